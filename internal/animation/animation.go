@@ -0,0 +1,145 @@
+// Package animation decodes the frames of animated GIF and PNG (APNG)
+// images.
+package animation
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"time"
+)
+
+// ErrNotAnimated is returned for images with a single frame.
+var ErrNotAnimated = errors.New("image is not animated")
+
+// minDelay is the shortest delay of a frame, shorter delays are taken to
+// be the default delay as in web browsers.
+const (
+	minDelay     = 20 * time.Millisecond
+	defaultDelay = 100 * time.Millisecond
+)
+
+// Frame is a frame of an animation. Its image is the whole animation at the
+// time of the frame, not only the part which changed.
+type Frame struct {
+	Image image.Image
+	Delay time.Duration
+}
+
+// Animation is the frames of an animated image.
+type Animation struct {
+	Frames []Frame
+	// Loops is the number of times the animation is played, zero plays it
+	// forever.
+	Loops int
+}
+
+// Decode decodes an animated GIF or PNG.
+func Decode(data []byte) (Animation, error) {
+	var (
+		a   Animation
+		err error
+	)
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		a, err = decodeGIF(data)
+	case bytes.HasPrefix(data, []byte(pngHeader)):
+		a, err = decodeAPNG(data)
+	default:
+		return Animation{}, ErrNotAnimated
+	}
+	if err != nil {
+		return Animation{}, err
+	}
+	if len(a.Frames) < 2 {
+		return Animation{}, ErrNotAnimated
+	}
+	return a, nil
+}
+
+// Duration returns the time a single loop of the animation takes.
+func (a Animation) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range a.Frames {
+		d += f.Delay
+	}
+	return d
+}
+
+// FrameAt returns the index of the frame shown at the time t after the
+// animation started. The last frame stays once all loops are played.
+func (a Animation) FrameAt(t time.Duration) int {
+	d := a.Duration()
+	if d <= 0 {
+		return 0
+	}
+	if a.Loops > 0 && t >= d*time.Duration(a.Loops) {
+		return len(a.Frames) - 1
+	}
+	t %= d
+	for i, f := range a.Frames {
+		if t < f.Delay {
+			return i
+		}
+		t -= f.Delay
+	}
+	return len(a.Frames) - 1
+}
+
+func delay(d time.Duration) time.Duration {
+	if d < minDelay {
+		return defaultDelay
+	}
+	return d
+}
+
+// snapshot returns a copy of the canvas.
+func snapshot(canvas *image.RGBA) *image.RGBA {
+	img := image.NewRGBA(canvas.Bounds())
+	copy(img.Pix, canvas.Pix)
+	return img
+}
+
+func decodeGIF(data []byte) (Animation, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return Animation{}, err
+	}
+
+	a := Animation{}
+	switch {
+	case g.LoopCount < 0:
+		a.Loops = 1
+	case g.LoopCount > 0:
+		a.Loops = g.LoopCount + 1
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = snapshot(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		d := time.Duration(0)
+		if i < len(g.Delay) {
+			d = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		a.Frames = append(a.Frames, Frame{Image: snapshot(canvas), Delay: delay(d)})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,182 @@
+package animation_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/maaslalani/slides/internal/animation"
+)
+
+func solid(c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeGIF(t *testing.T, frames int, loops int) []byte {
+	t.Helper()
+	g := &gif.GIF{LoopCount: loops}
+	colors := []color.Color{color.White, color.Black}
+	for i := 0; i < frames; i++ {
+		img := image.NewPaletted(image.Rect(0, 0, 4, 4), palette.Plan9)
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 4; y++ {
+				img.Set(x, y, colors[i%2])
+			}
+		}
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 5*(i+1))
+	}
+	var b bytes.Buffer
+	if err := gif.EncodeAll(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestDecodeGIF(t *testing.T) {
+	a, err := animation.Decode(encodeGIF(t, 2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(a.Frames))
+	}
+	if a.Loops != 0 {
+		t.Errorf("expected the animation to loop forever, got %d loops", a.Loops)
+	}
+	if d := a.Frames[1].Delay; d != 100*time.Millisecond {
+		t.Errorf("expected a delay of 100ms, got %s", d)
+	}
+	r, g, b, _ := a.Frames[1].Image.At(1, 1).RGBA()
+	if r != 0 || g != 0 || b != 0 {
+		t.Errorf("expected the second frame to be black")
+	}
+}
+
+func TestDecodeNotAnimated(t *testing.T) {
+	_, err := animation.Decode(encodeGIF(t, 1, 0))
+	if !errors.Is(err, animation.ErrNotAnimated) {
+		t.Errorf("expected ErrNotAnimated, got %v", err)
+	}
+
+	var b bytes.Buffer
+	png.Encode(&b, solid(color.White))
+	_, err = animation.Decode(b.Bytes())
+	if !errors.Is(err, animation.ErrNotAnimated) {
+		t.Errorf("expected ErrNotAnimated, got %v", err)
+	}
+}
+
+func chunk(typ string, data []byte) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	b = append(b, typ...)
+	b = append(b, data...)
+	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+}
+
+// idat returns the IHDR and the image data of an image encoded as PNG.
+func idat(t *testing.T, img image.Image) ([]byte, []byte) {
+	t.Helper()
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()[8:]
+	var ihdr, idat []byte
+	for len(data) >= 12 {
+		n := binary.BigEndian.Uint32(data)
+		switch string(data[4:8]) {
+		case "IHDR":
+			ihdr = data[8 : 8+n]
+		case "IDAT":
+			idat = append(idat, data[8:8+n]...)
+		}
+		data = data[12+n:]
+	}
+	return ihdr, idat
+}
+
+func fcTL(seq uint32, delayNum, delayDen uint16) []byte {
+	b := binary.BigEndian.AppendUint32(nil, seq)
+	b = binary.BigEndian.AppendUint32(b, 4)
+	b = binary.BigEndian.AppendUint32(b, 4)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint16(b, delayNum)
+	b = binary.BigEndian.AppendUint16(b, delayDen)
+	return append(b, 0, 0)
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	ihdr, first := idat(t, solid(color.White))
+	_, second := idat(t, solid(color.Black))
+
+	apng := []byte("\x89PNG\r\n\x1a\n")
+	apng = append(apng, chunk("IHDR", ihdr)...)
+	apng = append(apng, chunk("acTL", []byte{0, 0, 0, 2, 0, 0, 0, 3})...)
+	apng = append(apng, chunk("fcTL", fcTL(0, 1, 2))...)
+	apng = append(apng, chunk("IDAT", first)...)
+	apng = append(apng, chunk("fcTL", fcTL(1, 30, 0))...)
+	apng = append(apng, chunk("fdAT", append([]byte{0, 0, 0, 2}, second...))...)
+	apng = append(apng, chunk("IEND", nil)...)
+
+	a, err := animation.Decode(apng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(a.Frames))
+	}
+	if a.Loops != 3 {
+		t.Errorf("expected 3 loops, got %d", a.Loops)
+	}
+	if d := a.Frames[0].Delay; d != 500*time.Millisecond {
+		t.Errorf("expected a delay of 500ms, got %s", d)
+	}
+	if d := a.Frames[1].Delay; d != 300*time.Millisecond {
+		t.Errorf("expected a delay of 300ms, got %s", d)
+	}
+	r, _, _, _ := a.Frames[0].Image.At(1, 1).RGBA()
+	if r != 0xffff {
+		t.Errorf("expected the first frame to be white")
+	}
+	r, _, _, _ = a.Frames[1].Image.At(1, 1).RGBA()
+	if r != 0 {
+		t.Errorf("expected the second frame to be black")
+	}
+}
+
+func TestFrameAt(t *testing.T) {
+	a := animation.Animation{
+		Frames: []animation.Frame{{Delay: 100 * time.Millisecond}, {Delay: 200 * time.Millisecond}},
+		Loops:  2,
+	}
+	tests := []struct {
+		t    time.Duration
+		want int
+	}{
+		{0, 0},
+		{150 * time.Millisecond, 1},
+		{350 * time.Millisecond, 0},
+		{time.Second, 1},
+	}
+	for _, tt := range tests {
+		if got := a.FrameAt(tt.t); got != tt.want {
+			t.Errorf("FrameAt(%s) = %d, want %d", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package animation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"time"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n"
+
+var errInvalidAPNG = errors.New("invalid APNG")
+
+type chunk struct {
+	typ  string
+	data []byte
+}
+
+// frameControl is the fcTL chunk of an APNG frame.
+type frameControl struct {
+	width, height uint32
+	x, y          uint32
+	delay         time.Duration
+	dispose       byte
+	blend         byte
+}
+
+const (
+	disposeNone       = 0
+	disposeBackground = 1
+	disposePrevious   = 2
+	blendOver         = 1
+)
+
+func readChunks(data []byte) ([]chunk, error) {
+	var chunks []chunk
+	data = data[len(pngHeader):]
+	for len(data) >= 12 {
+		n := binary.BigEndian.Uint32(data)
+		if uint64(n)+12 > uint64(len(data)) {
+			return nil, errInvalidAPNG
+		}
+		chunks = append(chunks, chunk{typ: string(data[4:8]), data: data[8 : 8+n]})
+		data = data[12+n:]
+	}
+	return chunks, nil
+}
+
+func writeChunk(b *bytes.Buffer, typ string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	b.Write(n[:])
+	b.WriteString(typ)
+	b.Write(data)
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	b.Write(n[:])
+}
+
+func parseFrameControl(data []byte) (frameControl, error) {
+	if len(data) < 26 {
+		return frameControl{}, errInvalidAPNG
+	}
+	num := binary.BigEndian.Uint16(data[20:])
+	den := binary.BigEndian.Uint16(data[22:])
+	if den == 0 {
+		den = 100
+	}
+	return frameControl{
+		width:   binary.BigEndian.Uint32(data[4:]),
+		height:  binary.BigEndian.Uint32(data[8:]),
+		x:       binary.BigEndian.Uint32(data[12:]),
+		y:       binary.BigEndian.Uint32(data[16:]),
+		delay:   time.Duration(num) * time.Second / time.Duration(den),
+		dispose: data[24],
+		blend:   data[25],
+	}, nil
+}
+
+// decodeAPNG decodes the frames of an animated PNG. Every frame is decoded
+// as a PNG of its own, made of the frame data and the chunks of the image
+// which come before its data, such as the palette.
+func decodeAPNG(data []byte) (Animation, error) {
+	chunks, err := readChunks(data)
+	if err != nil {
+		return Animation{}, err
+	}
+	if len(chunks) == 0 || chunks[0].typ != "IHDR" || len(chunks[0].data) != 13 {
+		return Animation{}, errInvalidAPNG
+	}
+	ihdr := chunks[0].data
+
+	var (
+		a        Animation
+		animated bool
+		shared   []chunk
+		controls []frameControl
+		frames   [][]byte
+		seenIDAT bool
+	)
+	for _, c := range chunks[1:] {
+		switch c.typ {
+		case "acTL":
+			if len(c.data) < 8 {
+				return Animation{}, errInvalidAPNG
+			}
+			animated = true
+			a.Loops = int(binary.BigEndian.Uint32(c.data[4:]))
+		case "fcTL":
+			fc, err := parseFrameControl(c.data)
+			if err != nil {
+				return Animation{}, err
+			}
+			controls = append(controls, fc)
+			frames = append(frames, nil)
+		case "IDAT":
+			seenIDAT = true
+			// The default image is only a frame if a fcTL comes before it.
+			if len(frames) == 1 {
+				frames[0] = append(frames[0], c.data...)
+			}
+		case "fdAT":
+			if len(c.data) < 4 || len(frames) == 0 {
+				return Animation{}, errInvalidAPNG
+			}
+			frames[len(frames)-1] = append(frames[len(frames)-1], c.data[4:]...)
+		case "IEND":
+		default:
+			if !seenIDAT {
+				shared = append(shared, c)
+			}
+		}
+	}
+	if !animated {
+		return Animation{}, ErrNotAnimated
+	}
+
+	width := binary.BigEndian.Uint32(ihdr[0:])
+	height := binary.BigEndian.Uint32(ihdr[4:])
+	canvas := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	for i, fc := range controls {
+		if frames[i] == nil {
+			continue
+		}
+		frame, err := decodeFrame(ihdr, fc, shared, frames[i])
+		if err != nil {
+			return Animation{}, err
+		}
+
+		var previous *image.RGBA
+		if fc.dispose == disposePrevious {
+			previous = snapshot(canvas)
+		}
+		r := frame.Bounds().Add(image.Pt(int(fc.x), int(fc.y)))
+		op := draw.Src
+		if fc.blend == blendOver {
+			op = draw.Over
+		}
+		draw.Draw(canvas, r, frame, frame.Bounds().Min, op)
+		a.Frames = append(a.Frames, Frame{Image: snapshot(canvas), Delay: delay(fc.delay)})
+
+		switch fc.dispose {
+		case disposeBackground:
+			draw.Draw(canvas, r, image.Transparent, image.Point{}, draw.Src)
+		case disposePrevious:
+			canvas = previous
+		}
+	}
+	return a, nil
+}
+
+func decodeFrame(ihdr []byte, fc frameControl, shared []chunk, data []byte) (image.Image, error) {
+	header := bytes.Clone(ihdr)
+	binary.BigEndian.PutUint32(header[0:], fc.width)
+	binary.BigEndian.PutUint32(header[4:], fc.height)
+
+	var b bytes.Buffer
+	b.WriteString(pngHeader)
+	writeChunk(&b, "IHDR", header)
+	for _, c := range shared {
+		writeChunk(&b, c.typ, c.data)
+	}
+	writeChunk(&b, "IDAT", data)
+	writeChunk(&b, "IEND", nil)
+	return png.Decode(&b)
+}
@@ -0,0 +1,118 @@
+// Package cast plays back terminal recordings in the asciicast v2 format, as
+// recorded by asciinema.
+//
+// See https://docs.asciinema.org/manual/asciicast/v2/ for the format.
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrVersion is returned for recordings in a format other than asciicast v2.
+var ErrVersion = errors.New("unsupported asciicast version")
+
+// Header is the first line of a recording.
+type Header struct {
+	Version       int     `json:"version"`
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+	IdleTimeLimit float64 `json:"idle_time_limit"`
+	Title         string  `json:"title"`
+}
+
+// Event is something that happened during the recording, usually output.
+type Event struct {
+	Time time.Duration
+	// Type is "o" for output, "i" for input, "r" for a resize and "m" for a
+	// marker.
+	Type string
+	Data string
+}
+
+// Cast is a terminal recording.
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Duration returns the length of the recording.
+func (c *Cast) Duration() time.Duration {
+	if len(c.Events) == 0 {
+		return 0
+	}
+	return c.Events[len(c.Events)-1].Time
+}
+
+// Open reads the recording at path.
+func Open(path string) (*Cast, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a recording. Pauses longer than the recording's idle time limit
+// are shortened to the limit.
+func Parse(r io.Reader) (*Cast, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	var c Cast
+	if err := json.Unmarshal(scanner.Bytes(), &c.Header); err != nil {
+		return nil, fmt.Errorf("could not parse header: %w", err)
+	}
+	if c.Header.Version != 2 {
+		return nil, ErrVersion
+	}
+
+	var previous, shift float64
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var event []interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("could not parse event: %w", err)
+		}
+		if len(event) < 3 {
+			continue
+		}
+		t, ok1 := event[0].(float64)
+		kind, ok2 := event[1].(string)
+		data, ok3 := event[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			continue
+		}
+
+		if limit := c.Header.IdleTimeLimit; limit > 0 && t-previous > limit {
+			shift += t - previous - limit
+		}
+		previous = t
+
+		c.Events = append(c.Events, Event{
+			Time: time.Duration((t - shift) * float64(time.Second)),
+			Type: kind,
+			Data: data,
+		})
+	}
+
+	return &c, scanner.Err()
+}
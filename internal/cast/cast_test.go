@@ -0,0 +1,77 @@
+package cast_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maaslalani/slides/internal/cast"
+)
+
+const recording = `{"version": 2, "width": 10, "height": 2, "idle_time_limit": 1}
+[0.5, "o", "$ ls\r\n"]
+[1.0, "i", "x"]
+[1.5, "o", "file"]
+[10.0, "o", "\r\n$ "]
+`
+
+func TestParse(t *testing.T) {
+	c, err := cast.Parse(strings.NewReader(recording))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Header.Width != 10 || c.Header.Height != 2 {
+		t.Errorf("unexpected size %dx%d", c.Header.Width, c.Header.Height)
+	}
+	if len(c.Events) != 4 {
+		t.Fatalf("expected 4 events, got %d", len(c.Events))
+	}
+	// The 8.5 second pause is shortened to the idle time limit.
+	if c.Duration() != 2500*time.Millisecond {
+		t.Errorf("expected duration 2.5s, got %s", c.Duration())
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	_, err := cast.Parse(strings.NewReader(`{"version": 1}`))
+	if err != cast.ErrVersion {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	c, err := cast.Parse(strings.NewReader(recording))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cast.NewPlayer(c)
+
+	p.Seek(time.Second)
+	if got := p.String(); got != "$ ls\n" {
+		t.Errorf("expected %q, got %q", "$ ls\n", got)
+	}
+
+	p.Seek(time.Hour)
+	if got := p.String(); got != "file\n$" {
+		t.Errorf("expected %q, got %q", "file\n$", got)
+	}
+
+	// Seeking backwards replays the recording.
+	p.Seek(0)
+	if got := p.String(); got != "\n" {
+		t.Errorf("expected an empty screen, got %q", got)
+	}
+
+	start := time.Now()
+	p.Play(start)
+	p.Update(start.Add(2 * time.Second))
+	if p.Position() != 2*time.Second {
+		t.Errorf("expected position 2s, got %s", p.Position())
+	}
+	p.SetSpeed(3)
+	p.Update(start.Add(3 * time.Second))
+	if p.Position() != c.Duration() || p.Playing() {
+		t.Errorf("expected the player to stop at the end, at %s", p.Position())
+	}
+}
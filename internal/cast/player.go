@@ -0,0 +1,157 @@
+package cast
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maaslalani/slides/internal/vt"
+)
+
+// Speeds are the playback speeds a player steps through.
+var Speeds = []float64{0.25, 0.5, 1, 1.5, 2, 4}
+
+// Player plays a recording on a virtual terminal.
+type Player struct {
+	cast     *Cast
+	term     *vt.Terminal
+	position time.Duration
+	// next is the index of the first event after the position.
+	next    int
+	speed   int
+	playing bool
+	last    time.Time
+}
+
+// NewPlayer creates a paused player at the start of the recording.
+func NewPlayer(c *Cast) *Player {
+	p := &Player{cast: c, speed: 2}
+	p.rewind()
+	return p
+}
+
+func (p *Player) rewind() {
+	p.term = vt.New(max(p.cast.Header.Width, 1), max(p.cast.Header.Height, 1))
+	p.position = 0
+	p.next = 0
+}
+
+// Playing returns whether the player is playing.
+func (p *Player) Playing() bool {
+	return p.playing
+}
+
+// Play starts playing from the current position, or from the start if the
+// recording has ended.
+func (p *Player) Play(now time.Time) {
+	if p.position >= p.cast.Duration() {
+		p.rewind()
+	}
+	p.playing = true
+	p.last = now
+}
+
+// Pause stops playing.
+func (p *Player) Pause() {
+	p.playing = false
+}
+
+// Toggle plays or pauses the recording.
+func (p *Player) Toggle(now time.Time) {
+	if p.playing {
+		p.Pause()
+	} else {
+		p.Play(now)
+	}
+}
+
+// SetSpeed sets the playback speed to the closest of the available Speeds.
+func (p *Player) SetSpeed(speed float64) {
+	for i, s := range Speeds {
+		if math.Abs(s-speed) < math.Abs(Speeds[p.speed]-speed) {
+			p.speed = i
+		}
+	}
+}
+
+// Faster increases the playback speed.
+func (p *Player) Faster() {
+	p.speed = min(p.speed+1, len(Speeds)-1)
+}
+
+// Slower decreases the playback speed.
+func (p *Player) Slower() {
+	p.speed = max(p.speed-1, 0)
+}
+
+// Update advances the playback by the time passed since the last update,
+// scaled by the playback speed.
+func (p *Player) Update(now time.Time) {
+	if !p.playing {
+		return
+	}
+	elapsed := now.Sub(p.last)
+	p.last = now
+	p.Seek(p.position + time.Duration(float64(elapsed)*Speeds[p.speed]))
+	if p.position >= p.cast.Duration() {
+		p.playing = false
+	}
+}
+
+// Seek moves the playback to the given position in the recording. Seeking
+// backwards replays the recording from the start.
+func (p *Player) Seek(position time.Duration) {
+	position = min(max(position, 0), p.cast.Duration())
+	if position < p.position {
+		p.rewind()
+	}
+
+	for p.next < len(p.cast.Events) && p.cast.Events[p.next].Time <= position {
+		event := p.cast.Events[p.next]
+		switch event.Type {
+		case "o":
+			_, _ = p.term.Write([]byte(event.Data))
+		case "r":
+			cols, rows, ok := strings.Cut(event.Data, "x")
+			if ok {
+				c, _ := strconv.Atoi(cols)
+				r, _ := strconv.Atoi(rows)
+				p.term.Resize(c, r)
+			}
+		}
+		p.next++
+	}
+	p.position = position
+}
+
+// Position returns the current position in the recording.
+func (p *Player) Position() time.Duration {
+	return p.position
+}
+
+// Screen returns the terminal screen at the current position.
+func (p *Player) Screen() string {
+	return p.term.Render(false)
+}
+
+// String returns the text on the terminal screen without styling.
+func (p *Player) String() string {
+	return p.term.String()
+}
+
+// Status describes the state of the player, e.g. "▶ 0:05 / 1:32 1x".
+func (p *Player) Status() string {
+	icon := "⏸"
+	if p.playing {
+		icon = "▶"
+	}
+	speed := strconv.FormatFloat(Speeds[p.speed], 'f', -1, 64)
+	return fmt.Sprintf("%s %s / %s %sx", icon, clock(p.position), clock(p.cast.Duration()), speed)
+}
+
+func clock(d time.Duration) string {
+	s := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -0,0 +1,202 @@
+// Package chart draws bar charts, line plots and sparklines from CSV or YAML
+// data with Unicode block and braille characters.
+package chart
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ErrNoData is returned for charts without any values.
+var ErrNoData = errors.New("chart has no data")
+
+// Series is a named list of values, one for each label of the data.
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// Data is the data of a chart.
+type Data struct {
+	Labels []string
+	Series []Series
+}
+
+// Parse reads YAML or CSV data.
+//
+// YAML data maps labels to values, or the names of series to lists of
+// values:
+//
+//	go: [1, 4, 9]
+//	rust: [2, 3, 5]
+//
+// The first column of CSV data holds the labels and every other column is a
+// series. The series are named in the first row, if it contains anything
+// other than numbers or header is set:
+//
+//	language,stars
+//	go,12
+//	rust,9
+func Parse(src string, header bool) (Data, error) {
+	src = strings.TrimSpace(src)
+
+	var (
+		items yaml.MapSlice
+		data  Data
+		err   error
+	)
+	if yaml.Unmarshal([]byte(src), &items) == nil && len(items) > 0 {
+		data, err = fromYAML(items)
+	} else {
+		data, err = parseCSV(src, header)
+	}
+	if err != nil {
+		return Data{}, err
+	}
+	if len(data.Series) == 0 || len(data.Labels) == 0 {
+		return Data{}, ErrNoData
+	}
+	return data, nil
+}
+
+func parseCSV(src string, header bool) (Data, error) {
+	r := csv.NewReader(strings.NewReader(src))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return Data{}, err
+	}
+
+	var data Data
+	for i, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		values := make([]float64, len(record)-1)
+		numeric := true
+		for j, field := range record[1:] {
+			values[j], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			numeric = numeric && err == nil && finite(values[j])
+		}
+
+		if i == 0 && (header || !numeric) {
+			for _, name := range record[1:] {
+				data.Series = append(data.Series, Series{Name: strings.TrimSpace(name)})
+			}
+			continue
+		}
+		if !numeric {
+			return Data{}, fmt.Errorf("invalid values in row %d", i+1)
+		}
+
+		for len(data.Series) < len(values) {
+			data.Series = append(data.Series, Series{})
+		}
+		data.Labels = append(data.Labels, strings.TrimSpace(record[0]))
+		for j := range data.Series {
+			v := 0.0
+			if j < len(values) {
+				v = values[j]
+			}
+			data.Series[j].Values = append(data.Series[j].Values, v)
+		}
+	}
+	return data, nil
+}
+
+func fromYAML(items yaml.MapSlice) (Data, error) {
+	var (
+		data   Data
+		single Series
+	)
+	for _, item := range items {
+		key := fmt.Sprint(item.Key)
+		switch value := item.Value.(type) {
+		case []interface{}:
+			s := Series{Name: key}
+			for _, v := range value {
+				f, ok := number(v)
+				if !ok {
+					return Data{}, fmt.Errorf("invalid value %v of %s", v, key)
+				}
+				s.Values = append(s.Values, f)
+			}
+			data.Series = append(data.Series, s)
+			for len(data.Labels) < len(s.Values) {
+				data.Labels = append(data.Labels, strconv.Itoa(len(data.Labels)+1))
+			}
+		default:
+			f, ok := number(value)
+			if !ok {
+				return Data{}, fmt.Errorf("invalid value %v of %s", value, key)
+			}
+			data.Labels = append(data.Labels, key)
+			single.Values = append(single.Values, f)
+		}
+	}
+
+	if len(single.Values) > 0 {
+		if len(data.Series) > 0 {
+			return Data{}, errors.New("cannot mix values and lists of values")
+		}
+		data.Series = []Series{single}
+	}
+	for i := range data.Series {
+		for len(data.Series[i].Values) < len(data.Labels) {
+			data.Series[i].Values = append(data.Series[i].Values, 0)
+		}
+	}
+	return data, nil
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, finite(n)
+	}
+	return 0, false
+}
+
+// finite returns whether v is neither infinite nor NaN, which cannot be
+// drawn.
+func finite(v float64) bool {
+	return !math.IsInf(v, 0) && !math.IsNaN(v)
+}
+
+// bounds returns the smallest and largest value of the data.
+func (d Data) bounds() (float64, float64) {
+	lo, hi := d.Series[0].Values[0], d.Series[0].Values[0]
+	for _, s := range d.Series {
+		for _, v := range s.Values {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	return lo, hi
+}
+
+// fraction returns where v is between lo and hi, from 0 to 1. The values are
+// halved first, so that the distance between them cannot overflow.
+func fraction(v, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0
+	}
+	return (v/2 - lo/2) / (hi/2 - lo/2)
+}
+
+// format formats a value for the axis and labels of a chart.
+func format(v float64) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if len(s) > 7 {
+		s = strconv.FormatFloat(v, 'g', 4, 64)
+	}
+	return s
+}
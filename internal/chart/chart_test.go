@@ -0,0 +1,159 @@
+package chart_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/internal/chart"
+	"github.com/muesli/termenv"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		header   bool
+		expected chart.Data
+	}{
+		{
+			name: "csv with header",
+			src:  "language,stars\ngo,12\nrust,9",
+			expected: chart.Data{
+				Labels: []string{"go", "rust"},
+				Series: []chart.Series{{Name: "stars", Values: []float64{12, 9}}},
+			},
+		},
+		{
+			name: "csv without header",
+			src:  "go, 12\nrust, 9.5",
+			expected: chart.Data{
+				Labels: []string{"go", "rust"},
+				Series: []chart.Series{{Values: []float64{12, 9.5}}},
+			},
+		},
+		{
+			name:   "csv with numeric header",
+			src:    "language,2023,2024\ngo,1,2",
+			header: true,
+			expected: chart.Data{
+				Labels: []string{"go"},
+				Series: []chart.Series{
+					{Name: "2023", Values: []float64{1}},
+					{Name: "2024", Values: []float64{2}},
+				},
+			},
+		},
+		{
+			name: "yaml values",
+			src:  "go: 12\nrust: 9",
+			expected: chart.Data{
+				Labels: []string{"go", "rust"},
+				Series: []chart.Series{{Values: []float64{12, 9}}},
+			},
+		},
+		{
+			name: "yaml series",
+			src:  "go: [1, 2]\nrust: [3]",
+			expected: chart.Data{
+				Labels: []string{"1", "2"},
+				Series: []chart.Series{
+					{Name: "go", Values: []float64{1, 2}},
+					{Name: "rust", Values: []float64{3, 0}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := chart.Parse(tc.src, tc.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"", "go: fast", "go: 1\nrust: [1, 2]", "go,1\nrust,fast",
+		"go,1\nrust,NaN", "go,1\nrust,Inf", "go,1\nrust,-inf", "go,1\nrust,1e309",
+		"go: .nan", "go: .inf", "go: [1, -.inf]",
+	} {
+		if _, err := chart.Parse(src, false); err == nil {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tt := []struct {
+		opts     chart.Options
+		src      string
+		expected string
+	}{
+		{
+			opts:     chart.Options{Width: 20},
+			src:      "a: 2\nb: 4",
+			expected: "a │███████▌ 2\nb │███████████████ 4",
+		},
+		{
+			opts:     chart.Options{Type: chart.Spark, Width: 20},
+			src:      "a: 1\nb: 2\nc: 3",
+			expected: "▁▅█ 1–3",
+		},
+	}
+
+	for _, tc := range tt {
+		got, err := chart.Render(tc.src, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, got)
+		}
+	}
+
+	if _, err := chart.Render("a: 1", chart.Options{Type: "pie"}); err == nil {
+		t.Error("expected an error for an unknown chart type")
+	}
+}
+
+func TestRenderColors(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	src := "label,x,y,z\na,1,2,3"
+	got, err := chart.Render(src, chart.Options{Header: true, Width: 40, Colors: []string{"#010203", "#040506"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The third series wraps around to the first color.
+	if strings.Count(got, "38;2;1;2;3m") < 2 || !strings.Contains(got, "38;2;4;5;6m") {
+		t.Errorf("expected the series in the given colors, got %q", got)
+	}
+
+	got, err = chart.Render(src, chart.Options{Header: true, Width: 40, Colors: []string{"#010203"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "38;2;1;2;3m") {
+		t.Errorf("expected a single color to be ignored, got %q", got)
+	}
+}
+
+func TestRenderExtremeValues(t *testing.T) {
+	for _, src := range []string{"a,1e308\nb,-1e308", "a,1.7e308\nb,1.7e308", "a,-1e308\nb,-1e308\nc,0"} {
+		for _, kind := range []string{chart.Bar, chart.Column, chart.Line, chart.Spark} {
+			if _, err := chart.Render(src, chart.Options{Type: kind, Width: 40, Height: 8}); err != nil {
+				t.Errorf("%s %q: %v", kind, src, err)
+			}
+		}
+	}
+}
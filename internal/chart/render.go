@@ -0,0 +1,344 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/styles"
+)
+
+// Kinds of charts.
+const (
+	// Bar draws horizontal bars, one row for each value.
+	Bar = "bar"
+	// Column draws vertical bars.
+	Column = "column"
+	// Line plots the values with braille dots.
+	Line = "line"
+	// Spark draws a single line sparkline for each series.
+	Spark = "spark"
+)
+
+const (
+	minHeight = 4
+	maxHeight = 16
+)
+
+var (
+	horizontalEighths = []rune(" ▏▎▍▌▋▊▉█")
+	verticalEighths   = []rune(" ▁▂▃▄▅▆▇█")
+)
+
+// Options are the options of a chart.
+type Options struct {
+	// Type is the kind of chart, a bar chart by default.
+	Type string
+	// Header names the series in the first row of CSV data, even if it only
+	// contains numbers.
+	Header bool
+	// Width and Height are the space available for the chart, it fits in
+	// them where possible.
+	Width  int
+	Height int
+	// Colors are the colors of the series in order, such as those of the
+	// theme. The default colors are used if there are fewer than two.
+	Colors []string
+}
+
+// Render draws the data as a chart.
+func Render(src string, opts Options) (string, error) {
+	data, err := Parse(src, opts.Header)
+	if err != nil {
+		return "", err
+	}
+	kind := opts.Type
+	width := max(opts.Width, 20)
+	height := min(max(opts.Height, minHeight), maxHeight)
+
+	p := newPalette(opts.Colors)
+
+	var out string
+	switch kind {
+	case "", Bar:
+		out = renderBar(data, p, width)
+	case Column:
+		out = renderColumn(data, p, width, height)
+	case Line:
+		out = renderLine(data, p, width, height)
+	case Spark:
+		out = renderSpark(data, p, width)
+	default:
+		return "", fmt.Errorf("unknown chart type %q, expected bar, column, line or spark", kind)
+	}
+
+	if kind != Spark {
+		if legend := legend(data, p); legend != "" {
+			out += "\n\n" + legend
+		}
+	}
+	return out, nil
+}
+
+// palette holds the styles of the series of a chart in order.
+type palette []lipgloss.Style
+
+func newPalette(colors []string) palette {
+	if len(colors) < 2 {
+		return styles.Series
+	}
+	p := make(palette, len(colors))
+	for i, c := range colors {
+		p[i] = lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+	}
+	return p
+}
+
+func (p palette) color(series int, s string) string {
+	return p[series%len(p)].Render(s)
+}
+
+// legend names the series by their color, it is empty for a single series.
+func legend(d Data, p palette) string {
+	if len(d.Series) < 2 {
+		return ""
+	}
+	var items []string
+	for i, s := range d.Series {
+		if s.Name != "" {
+			items = append(items, p.color(i, "■")+" "+s.Name)
+		}
+	}
+	return strings.Join(items, "  ")
+}
+
+func pad(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", width-ansi.StringWidth(s))
+}
+
+func renderBar(d Data, p palette, width int) string {
+	labelWidth, valueWidth := 0, 0
+	for i, label := range d.Labels {
+		labelWidth = max(labelWidth, ansi.StringWidth(label))
+		for _, s := range d.Series {
+			valueWidth = max(valueWidth, len(format(s.Values[i])))
+		}
+	}
+	labelWidth = min(labelWidth, width/3)
+	barWidth := max(width-labelWidth-valueWidth-3, 1)
+
+	_, hi := d.bounds()
+	if hi <= 0 {
+		hi = 1
+	}
+
+	var lines []string
+	for i, label := range d.Labels {
+		for j, s := range d.Series {
+			if j > 0 {
+				label = ""
+			}
+			v := s.Values[i]
+			cells := max(v, 0) / hi * float64(barWidth)
+			full := int(cells)
+			bar := strings.Repeat("█", full)
+			if eighth := int((cells - float64(full)) * 8); eighth > 0 {
+				bar += string(horizontalEighths[eighth])
+			}
+			lines = append(lines, pad(label, labelWidth)+" │"+p.color(j, bar)+" "+format(v))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// axis returns the labels of the vertical axis of a chart with the given
+// number of rows, the bounds are labelled at the top and bottom.
+func axis(lo, hi float64, rows int) ([]string, int) {
+	labels := make([]string, rows)
+	labels[0] = format(hi)
+	labels[rows-1] = format(lo)
+	w := max(len(labels[0]), len(labels[rows-1]))
+	for i := range labels {
+		tick := "│"
+		if labels[i] != "" {
+			tick = "┤"
+		}
+		labels[i] = fmt.Sprintf("%*s %s", w, labels[i], tick)
+	}
+	return labels, w + 2
+}
+
+// xLabels places the labels below the horizontal axis at the given cells,
+// skipping labels which would overlap the previous one.
+func xLabels(labels []string, cells []int, offset int) string {
+	var b strings.Builder
+	col := 0
+	for i, label := range labels {
+		start := max(offset+cells[i]-ansi.StringWidth(label)/2, 0)
+		if start < col {
+			continue
+		}
+		b.WriteString(strings.Repeat(" ", start-col))
+		b.WriteString(label)
+		col = start + ansi.StringWidth(label) + 1
+		b.WriteString(" ")
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+func renderColumn(d Data, p palette, width, height int) string {
+	_, hi := d.bounds()
+	if hi <= 0 {
+		hi = 1
+	}
+	rows := height - 2
+	labels, axisWidth := axis(0, hi, rows)
+
+	plotWidth := width - axisWidth
+	n := len(d.Series)
+	barWidth := min(max((plotWidth/len(d.Labels)-1)/n, 1), 8)
+	groupWidth := barWidth*n + 1
+
+	lines := make([]string, rows)
+	for r := range lines {
+		var b strings.Builder
+		b.WriteString(labels[r])
+		for i := range d.Labels {
+			b.WriteString(" ")
+			for j, s := range d.Series {
+				eighths := int(math.Round(max(s.Values[i], 0) / hi * float64(rows*8)))
+				level := min(max(eighths-(rows-1-r)*8, 0), 8)
+				b.WriteString(p.color(j, strings.Repeat(string(verticalEighths[level]), barWidth)))
+			}
+		}
+		lines[r] = strings.TrimRight(b.String(), " ")
+	}
+
+	base := strings.Repeat(" ", axisWidth-1) + "└" + strings.Repeat("─", groupWidth*len(d.Labels))
+	cells := make([]int, len(d.Labels))
+	for i := range cells {
+		cells[i] = i*groupWidth + 1 + barWidth*n/2
+	}
+	truncated := make([]string, len(d.Labels))
+	for i, label := range d.Labels {
+		truncated[i] = ansi.Truncate(label, groupWidth-1, "…")
+	}
+	return strings.Join(append(lines, base, xLabels(truncated, cells, axisWidth)), "\n")
+}
+
+// braille dots by their position in a cell, two columns of four rows.
+var braille = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+func renderLine(d Data, p palette, width, height int) string {
+	lo, hi := d.bounds()
+	if hi == lo {
+		hi = lo + 1
+	}
+	rows := height - 2
+	labels, axisWidth := axis(lo, hi, rows)
+	cols := max(width-axisWidth, 2)
+
+	dots := make([][]rune, rows)
+	series := make([][]int, rows)
+	for r := range dots {
+		dots[r] = make([]rune, cols)
+		series[r] = make([]int, cols)
+	}
+	plot := func(x, y, s int) {
+		dots[y/4][x/2] |= braille[x%2][y%4]
+		series[y/4][x/2] = s
+	}
+
+	dotWidth, dotHeight := cols*2, rows*4
+	point := func(i int, v float64) (int, int) {
+		x := 0
+		if n := len(d.Labels); n > 1 {
+			x = i * (dotWidth - 1) / (n - 1)
+		}
+		y := dotHeight - 1 - int(math.Round(fraction(v, lo, hi)*float64(dotHeight-1)))
+		return x, y
+	}
+
+	for j, s := range d.Series {
+		for i := range s.Values {
+			x1, y1 := point(i, s.Values[i])
+			if i == 0 {
+				plot(x1, y1, j)
+				continue
+			}
+			x0, y0 := point(i-1, s.Values[i-1])
+			steps := max(abs(x1-x0), abs(y1-y0), 1)
+			for k := 0; k <= steps; k++ {
+				plot(x0+(x1-x0)*k/steps, y0+(y1-y0)*k/steps, j)
+			}
+		}
+	}
+
+	lines := make([]string, rows)
+	for r := range lines {
+		var b strings.Builder
+		b.WriteString(labels[r])
+		for c, dot := range dots[r] {
+			if dot == 0 {
+				b.WriteString(" ")
+				continue
+			}
+			b.WriteString(p.color(series[r][c], string(0x2800+dot)))
+		}
+		lines[r] = strings.TrimRight(b.String(), " ")
+	}
+
+	base := strings.Repeat(" ", axisWidth-1) + "└" + strings.Repeat("─", cols)
+	cells := make([]int, len(d.Labels))
+	for i := range cells {
+		x, _ := point(i, lo)
+		cells[i] = x / 2
+	}
+	return strings.Join(append(lines, base, xLabels(d.Labels, cells, axisWidth)), "\n")
+}
+
+func renderSpark(d Data, p palette, width int) string {
+	nameWidth := 0
+	for _, s := range d.Series {
+		nameWidth = max(nameWidth, ansi.StringWidth(s.Name))
+	}
+
+	var lines []string
+	for j, s := range d.Series {
+		lo, hi := s.Values[0], s.Values[0]
+		for _, v := range s.Values {
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		bounds := " " + format(lo) + "–" + format(hi)
+
+		values := s.Values
+		if n := width - nameWidth - len(bounds) - 1; len(values) > n {
+			values = values[len(values)-max(n, 1):]
+		}
+		var spark strings.Builder
+		for _, v := range values {
+			level := 4
+			if hi > lo {
+				level = 1 + int(math.Round(fraction(v, lo, hi)*7))
+			}
+			spark.WriteRune(verticalEighths[level])
+		}
+
+		line := p.color(j, spark.String()) + styles.Dim.Render(bounds)
+		if nameWidth > 0 {
+			line = pad(s.Name, nameWidth) + " " + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
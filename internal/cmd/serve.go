@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/maaslalani/slides/internal/keymap"
 	"github.com/maaslalani/slides/internal/model"
 	"github.com/maaslalani/slides/internal/navigation"
 	"github.com/maaslalani/slides/internal/server"
@@ -22,6 +23,10 @@ var (
 	keyPath  string
 	err      error
 	fileName string
+
+	transitions   bool
+	mouse         bool
+	allowTerminal bool
 )
 
 // ServeCmd is the command for serving the presentation. It starts the slides
@@ -56,12 +61,23 @@ var ServeCmd = &coral.Command{
 			protocol = term.Iterm
 		}
 
+		keys, err := keymap.Load(keymap.Path())
+		if err != nil {
+			return err
+		}
+
 		presentation := model.Model{
 			Page:             0,
 			Date:             time.Now().Format("2006-01-02"),
 			FileName:         fileName,
 			Search:           navigation.NewSearch(),
+			Picker:           navigation.NewPicker(),
+			Finder:           navigation.NewFinder(),
+			KeyMap:           &keys,
 			TerminalProtocol: protocol,
+			NoTransitions:    !transitions,
+			Mouse:            mouse,
+			AllowTerminal:    allowTerminal,
 		}
 		err = presentation.Load()
 		if err != nil {
@@ -97,4 +113,7 @@ func init() {
 	ServeCmd.Flags().StringVar(&keyPath, "keyPath", "slides", "Server private key path")
 	ServeCmd.Flags().StringVar(&host, "host", "localhost", "Server host to bind to")
 	ServeCmd.Flags().IntVar(&port, "port", 53531, "Server port to bind to")
+	ServeCmd.Flags().BoolVar(&transitions, "transitions", false, "Play transitions between slides, every frame is sent to the viewers")
+	ServeCmd.Flags().BoolVar(&mouse, "mouse", false, "Click to change slides, scroll long slides and open links")
+	ServeCmd.Flags().BoolVar(&allowTerminal, "allow-terminal", false, "Run terminal blocks on this machine for every viewer (gives them a shell)")
 }
@@ -9,13 +9,18 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	_ "image/jpeg"
 	_ "image/png"
 
+	_ "golang.org/x/image/webp"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/internal/diagram"
+	"github.com/maaslalani/slides/internal/svg"
 	"github.com/maaslalani/slides/internal/term"
 	"github.com/mdp/qrterminal/v3"
 )
@@ -24,6 +29,13 @@ import (
 type Block struct {
 	Code     string
 	Language string
+	// Info is the remainder of the fence's info string after the language,
+	// such as `{typewriter}` in ```go {typewriter}.
+	Info string
+	// Start and End are the byte offsets of the block (including its
+	// fences) in the markdown it was parsed from.
+	Start int
+	End   int
 }
 
 // Result represents the output for an executed code block.
@@ -34,7 +46,7 @@ type Result struct {
 }
 
 // ?: means non-capture group
-var re = regexp.MustCompile("(?s)(?:```|~~~)(\\w+)\n(.*?)\n(?:```|~~~)\\s?")
+var re = regexp.MustCompile("(?s)(?:```|~~~)([\\w-]+)([^\n]*)\n(.*?)\n(?:```|~~~)\\s?")
 
 // ErrParse is the returned error when we cannot parse the code block (i.e.
 // there is no code block on the current slide) or the code block is
@@ -44,18 +56,21 @@ var ErrParse = errors.New("Error: could not parse code block")
 // Parse takes a block of markdown and returns an array of Block's with code
 // and associated languages
 func Parse(markdown string) ([]Block, error) {
-	matches := re.FindAllStringSubmatch(markdown, -1)
+	matches := re.FindAllStringSubmatchIndex(markdown, -1)
 
 	var rv []Block
 	for _, match := range matches {
 		// There was either no language specified or no code block
 		// Either way, we cannot execute the expression
-		if len(match) < 3 {
+		if len(match) < 8 {
 			continue
 		}
 		rv = append(rv, Block{
-			Language: match[1],
-			Code:     RemoveComments(match[2]),
+			Language: markdown[match[2]:match[3]],
+			Info:     strings.TrimSpace(markdown[match[4]:match[5]]),
+			Code:     RemoveComments(markdown[match[6]:match[7]]),
+			Start:    match[0],
+			End:      match[1],
 		})
 
 	}
@@ -67,15 +82,97 @@ func Parse(markdown string) ([]Block, error) {
 	return rv, nil
 }
 
+// Options returns the space separated options in braces in the block's info
+// string, e.g. ["typewriter=line"] for ```go {typewriter=line}.
+func (b Block) Options() []string {
+	info := strings.TrimSpace(b.Info)
+	if !strings.HasPrefix(info, "{") || !strings.HasSuffix(info, "}") {
+		return nil
+	}
+	return strings.Fields(info[1 : len(info)-1])
+}
+
+// Option returns the value of the named option and whether the option is set
+// at all. Options without a value, such as {typewriter}, have an empty value.
+func (b Block) Option(name string) (string, bool) {
+	for _, opt := range b.Options() {
+		key, value, _ := strings.Cut(opt, "=")
+		if key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// LineRange is an inclusive range of line numbers in a code block, the first
+// line of a block is line 1.
+type LineRange struct {
+	From int
+	To   int
+}
+
+// Contains returns whether the line is part of the range.
+func (r LineRange) Contains(line int) bool {
+	return r.From <= line && line <= r.To
+}
+
+var highlightRegexp = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*(\|\d+(-\d+)?(,\d+(-\d+)?)*)*$`)
+
+// Highlights returns the groups of lines to highlight one after the other,
+// given as an option such as {3-5|9-10} or {1,3-4|7}. Groups are separated by
+// | and the ranges within a group by commas.
+func (b Block) Highlights() [][]LineRange {
+	for _, opt := range b.Options() {
+		if !highlightRegexp.MatchString(opt) {
+			continue
+		}
+
+		var groups [][]LineRange
+		for _, group := range strings.Split(opt, "|") {
+			var ranges []LineRange
+			for _, r := range strings.Split(group, ",") {
+				from, to, found := strings.Cut(r, "-")
+				if !found {
+					to = from
+				}
+				f, _ := strconv.Atoi(from)
+				t, _ := strconv.Atoi(to)
+				ranges = append(ranges, LineRange{From: min(f, t), To: max(f, t)})
+			}
+			groups = append(groups, ranges)
+		}
+		return groups
+	}
+	return nil
+}
+
 const (
 	// ExitCodeInternalError represents the exit code in which the code
 	// executing the code didn't work.
 	ExitCodeInternalError = -1
 )
 
-func RenderImage(img image.Image, terminal term.TerminalProtocol, availableCells int, width int) string {
-	var buff bytes.Buffer
+// Vector images are rasterized for cells of cellWidth by cellHeight pixels,
+// which is about the shape of the cells of most terminal fonts.
+const (
+	cellWidth  = 10
+	cellHeight = 22
+)
+
+// FitImage rasterizes SVG images at the size of cols by rows terminal cells,
+// so that they stay sharp however large they are shown. Other images are
+// returned as they are.
+func FitImage(img image.Image, cols, rows int) image.Image {
+	if s, ok := img.(*svg.Image); ok {
+		return s.Rasterize(cols*cellWidth, rows*cellHeight)
+	}
+	return img
+}
 
+// ImageCells returns the columns and rows an image takes up when it is fit
+// into availableCells rows of the given width, and the rows above it which
+// center it vertically.
+func ImageCells(img image.Image, availableCells int, width int) (int, int, int) {
 	aspectRatio := 2.2 * float64(img.Bounds().Dx()) / float64(img.Bounds().Dy())
 
 	// rows := float64(availableCells)
@@ -91,6 +188,15 @@ func RenderImage(img image.Image, terminal term.TerminalProtocol, availableCells
 	// calculate the vertical cells to pad on top to center image
 	yPadding := max(int(float64(availableCells)/2-(rows/2)), 0)
 
+	return int(cols), int(rows), yPadding
+}
+
+func RenderImage(img image.Image, terminal term.TerminalProtocol, availableCells int, width int) string {
+	var buff bytes.Buffer
+
+	cols, rows, yPadding := ImageCells(img, availableCells, width)
+	img = FitImage(img, cols, rows)
+
 	switch terminal {
 	case term.Kitty:
 		// Kitty options
@@ -115,15 +221,21 @@ func RenderImage(img image.Image, terminal term.TerminalProtocol, availableCells
 // Execute takes a code.Block and returns the output of the executed code
 func Execute(code Block, terminal term.TerminalProtocol, availableCells int, width int) Result {
 	if code.Language == "img" {
-		f, err := os.Open(code.Code)
+		f, err := os.Open(strings.TrimSpace(code.Code))
 		if err != nil {
-			panic(err)
+			return Result{
+				Out:      "Error: " + err.Error(),
+				ExitCode: ExitCodeInternalError,
+			}
 		}
 		defer f.Close()
 
 		img, _, err := image.Decode(f)
 		if err != nil {
-			panic(err)
+			return Result{
+				Out:      "Error: could not decode " + f.Name() + ": " + err.Error(),
+				ExitCode: ExitCodeInternalError,
+			}
 		}
 
 		return Result{
@@ -176,6 +288,20 @@ func Execute(code Block, terminal term.TerminalProtocol, availableCells int, wid
 		}
 	}
 
+	if code.Language == "graph" {
+		out, err := diagram.Render(code.Code)
+		if err != nil {
+			return Result{
+				Out:      "Error: " + err.Error(),
+				ExitCode: ExitCodeInternalError,
+			}
+		}
+		return Result{
+			Out:      out,
+			ExitCode: 0,
+		}
+	}
+
 	// Check supported language
 	language, ok := Languages[code.Language]
 	if !ok {
@@ -28,6 +28,19 @@ puts "Hello, world!"
 		},
 		{
 			markdown: `
+~~~include-table
+data.csv
+~~~
+`,
+			expected: []code.Block{
+				{
+					Code:     `data.csv`,
+					Language: "include-table",
+				},
+			},
+		},
+		{
+			markdown: `
 ~~~go
 fmt.Println("Hello, world!")
 ~~~
@@ -132,3 +145,76 @@ fmt.Println("Hello, world!")
 		}
 	}
 }
+
+func TestParseOffsets(t *testing.T) {
+	markdown := "# Title\n~~~bash\necho one\n~~~\ntext\n~~~go\nfmt.Println()\n~~~\n"
+
+	blocks, err := code.Parse(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+
+	expected := []string{
+		"~~~bash\necho one\n~~~\n",
+		"~~~go\nfmt.Println()\n~~~\n",
+	}
+	for i, block := range blocks {
+		if got := markdown[block.Start:block.End]; got != expected[i] {
+			t.Errorf("block %d: expected %q, got %q", i, expected[i], got)
+		}
+	}
+}
+
+func TestOption(t *testing.T) {
+	blocks, err := code.Parse("~~~go {typewriter=line 3-5}\nfmt.Println()\n~~~\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := blocks[0]
+	if block.Language != "go" {
+		t.Fatalf("incorrect language, got %s, want go", block.Language)
+	}
+	if value, ok := block.Option("typewriter"); !ok || value != "line" {
+		t.Errorf("expected typewriter=line, got %q (%t)", value, ok)
+	}
+	if _, ok := block.Option("3-5"); !ok {
+		t.Errorf("expected option 3-5 to be set")
+	}
+	if _, ok := block.Option("missing"); ok {
+		t.Errorf("expected option missing to be unset")
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	tt := []struct {
+		info     string
+		expected [][]code.LineRange
+	}{
+		{info: "", expected: nil},
+		{info: "{typewriter}", expected: nil},
+		{info: "{3-5|9-10}", expected: [][]code.LineRange{{{From: 3, To: 5}}, {{From: 9, To: 10}}}},
+		{info: "{1,4-3|7}", expected: [][]code.LineRange{{{From: 1, To: 1}, {From: 3, To: 4}}, {{From: 7, To: 7}}}},
+		{info: "{typewriter 2}", expected: [][]code.LineRange{{{From: 2, To: 2}}}},
+	}
+
+	for _, tc := range tt {
+		got := code.Block{Info: tc.info}.Highlights()
+		if len(got) != len(tc.expected) {
+			t.Fatalf("%s: expected %d groups, got %d", tc.info, len(tc.expected), len(got))
+		}
+		for i := range got {
+			if len(got[i]) != len(tc.expected[i]) {
+				t.Fatalf("%s: expected %v, got %v", tc.info, tc.expected, got)
+			}
+			for j := range got[i] {
+				if got[i][j] != tc.expected[i][j] {
+					t.Fatalf("%s: expected %v, got %v", tc.info, tc.expected, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package code
+
+import "strings"
+
+// DiffOp is the kind of change a line in a diff represents.
+type DiffOp int
+
+const (
+	// DiffEqual is a line present in both versions.
+	DiffEqual DiffOp = iota
+	// DiffInsert is a line only present in the new version.
+	DiffInsert
+	// DiffDelete is a line only present in the old version.
+	DiffDelete
+)
+
+// DiffLine is a single line of a diff.
+type DiffLine struct {
+	Text string
+	Op   DiffOp
+}
+
+// Diff compares two versions of some code line by line and returns the lines
+// of both merged together, with deleted lines placed before the lines that
+// were inserted in their place.
+func Diff(old, new string) []DiffLine {
+	a := strings.Split(old, "\n")
+	b := strings.Split(new, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, DiffLine{Text: a[i], Op: DiffEqual})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, DiffLine{Text: a[i], Op: DiffDelete})
+			i++
+		default:
+			lines = append(lines, DiffLine{Text: b[j], Op: DiffInsert})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, DiffLine{Text: a[i], Op: DiffDelete})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, DiffLine{Text: b[j], Op: DiffInsert})
+	}
+
+	return lines
+}
@@ -0,0 +1,70 @@
+package code_test
+
+import (
+	"testing"
+
+	"github.com/maaslalani/slides/internal/code"
+)
+
+func TestDiff(t *testing.T) {
+	tt := []struct {
+		desc     string
+		old      string
+		new      string
+		expected []code.DiffLine
+	}{
+		{
+			desc: "unchanged",
+			old:  "a\nb",
+			new:  "a\nb",
+			expected: []code.DiffLine{
+				{Text: "a", Op: code.DiffEqual},
+				{Text: "b", Op: code.DiffEqual},
+			},
+		},
+		{
+			desc: "insert",
+			old:  "a\nc",
+			new:  "a\nb\nc",
+			expected: []code.DiffLine{
+				{Text: "a", Op: code.DiffEqual},
+				{Text: "b", Op: code.DiffInsert},
+				{Text: "c", Op: code.DiffEqual},
+			},
+		},
+		{
+			desc: "delete",
+			old:  "a\nb\nc",
+			new:  "a\nc",
+			expected: []code.DiffLine{
+				{Text: "a", Op: code.DiffEqual},
+				{Text: "b", Op: code.DiffDelete},
+				{Text: "c", Op: code.DiffEqual},
+			},
+		},
+		{
+			desc: "change",
+			old:  "a\nb\nc",
+			new:  "a\nB\nc\nd",
+			expected: []code.DiffLine{
+				{Text: "a", Op: code.DiffEqual},
+				{Text: "b", Op: code.DiffDelete},
+				{Text: "B", Op: code.DiffInsert},
+				{Text: "c", Op: code.DiffEqual},
+				{Text: "d", Op: code.DiffInsert},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		got := code.Diff(tc.old, tc.new)
+		if len(got) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v", tc.desc, tc.expected, got)
+		}
+		for i := range got {
+			if got[i] != tc.expected[i] {
+				t.Fatalf("%s: expected %v, got %v", tc.desc, tc.expected, got)
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package code_test
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/maaslalani/slides/internal/code"
@@ -60,6 +63,16 @@ func main() {
 				ExitCode: code.ExitCodeInternalError,
 			},
 		},
+		{
+			block: code.Block{
+				Code:     "graph LR\n  A --> B",
+				Language: "graph",
+			},
+			expected: code.Result{
+				Out:      "┌───┐     ┌───┐\n│ A ├────▶│ B │\n└───┘     └───┘",
+				ExitCode: 0,
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -74,3 +87,17 @@ func main() {
 		}
 	}
 }
+
+func TestExecuteImageErrors(t *testing.T) {
+	malformed := filepath.Join(t.TempDir(), "malformed.svg")
+	if err := os.WriteFile(malformed, []byte("<svg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"missing.png", malformed} {
+		r := code.Execute(code.Block{Code: path, Language: "img"}, term.Other, 10, 80)
+		if !strings.HasPrefix(r.Out, "Error: ") || r.ExitCode != code.ExitCodeInternalError {
+			t.Errorf("expected an error for %s, got %+v", path, r)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package diagram
+
+import (
+	"strings"
+)
+
+// Directions in which a line leaves a cell of the canvas.
+const (
+	up uint8 = 1 << iota
+	down
+	left
+	right
+)
+
+var lineRunes = map[uint8]rune{
+	left: '─', right: '─', left | right: '─',
+	up: '│', down: '│', up | down: '│',
+	down | right: '┌', down | left: '┐', up | right: '└', up | left: '┘',
+	up | down | right: '├', up | down | left: '┤',
+	down | left | right: '┬', up | left | right: '┴',
+	up | down | left | right: '┼',
+}
+
+// canvas is a grid of characters which grows as it is drawn on. Lines are
+// recorded as the directions they leave each cell in, so that crossing and
+// joining lines are drawn with the matching box drawing characters.
+type canvas struct {
+	text   [][]rune
+	lines  [][]uint8
+	dashed [][]bool
+}
+
+func (c *canvas) grow(x, y int) {
+	for len(c.text) <= y {
+		c.text = append(c.text, nil)
+		c.lines = append(c.lines, nil)
+		c.dashed = append(c.dashed, nil)
+	}
+	for len(c.text[y]) <= x {
+		c.text[y] = append(c.text[y], 0)
+		c.lines[y] = append(c.lines[y], 0)
+		c.dashed[y] = append(c.dashed[y], false)
+	}
+}
+
+// set places a character on the canvas, it takes precedence over lines.
+func (c *canvas) set(x, y int, r rune) {
+	if x < 0 || y < 0 {
+		return
+	}
+	c.grow(x, y)
+	c.text[y][x] = r
+}
+
+func (c *canvas) get(x, y int) rune {
+	if y < 0 || y >= len(c.text) || x < 0 || x >= len(c.text[y]) {
+		return 0
+	}
+	return c.text[y][x]
+}
+
+// write places text on the canvas starting at x.
+func (c *canvas) write(x, y int, s string) {
+	for _, r := range s {
+		c.set(x, y, r)
+		x++
+	}
+}
+
+func (c *canvas) line(x, y int, dir uint8, dashed bool) {
+	if x < 0 || y < 0 {
+		return
+	}
+	c.grow(x, y)
+	c.dashed[y][x] = dashed && (c.lines[y][x] == 0 || c.dashed[y][x])
+	c.lines[y][x] |= dir
+}
+
+// hline draws a horizontal line between x1 and x2, inclusive.
+func (c *canvas) hline(x1, x2, y int, dashed bool) {
+	from, to := min(x1, x2), max(x1, x2)
+	for x := from; x <= to; x++ {
+		var dir uint8
+		if x > from {
+			dir |= left
+		}
+		if x < to {
+			dir |= right
+		}
+		c.line(x, y, dir, dashed)
+	}
+}
+
+// vline draws a vertical line between y1 and y2, inclusive.
+func (c *canvas) vline(x, y1, y2 int, dashed bool) {
+	from, to := min(y1, y2), max(y1, y2)
+	for y := from; y <= to; y++ {
+		var dir uint8
+		if y > from {
+			dir |= up
+		}
+		if y < to {
+			dir |= down
+		}
+		c.line(x, y, dir, dashed)
+	}
+}
+
+// box draws a box with its top left corner at x, y and the lines of the
+// label centered inside it.
+func (c *canvas) box(x, y, w, h int, s shape, label []string) {
+	border := borders[s]
+	c.set(x, y, border[0])
+	c.set(x+w-1, y, border[1])
+	c.set(x, y+h-1, border[2])
+	c.set(x+w-1, y+h-1, border[3])
+	for i := x + 1; i < x+w-1; i++ {
+		c.set(i, y, border[4])
+		c.set(i, y+h-1, border[4])
+	}
+	for j := y + 1; j < y+h-1; j++ {
+		c.set(x, j, border[5])
+		c.set(x+w-1, j, border[5])
+		for i := x + 1; i < x+w-1; i++ {
+			c.set(i, j, ' ')
+		}
+	}
+	for i, l := range label {
+		c.write(x+(w-width(l))/2, y+1+i, l)
+	}
+}
+
+// joinBorder replaces the border character at x, y with the junction of a
+// line leaving the box in the given direction.
+func (c *canvas) joinBorder(x, y int, dir uint8) {
+	junctions := map[uint8]map[rune]rune{
+		down:  {'─': '┬', '═': '╤'},
+		right: {'│': '├', '║': '╟'},
+		up:    {'─': '┴', '═': '╧'},
+		left:  {'│': '┤', '║': '╢'},
+	}
+	if r, ok := junctions[dir][c.get(x, y)]; ok {
+		c.set(x, y, r)
+	}
+}
+
+// String returns the drawing with trailing spaces and empty lines removed.
+func (c *canvas) String() string {
+	rows := make([]string, len(c.text))
+	for y := range c.text {
+		var b strings.Builder
+		for x, r := range c.text[y] {
+			switch {
+			case r != 0:
+				b.WriteRune(r)
+			case c.lines[y][x] != 0:
+				b.WriteRune(c.lineRune(x, y))
+			default:
+				b.WriteRune(' ')
+			}
+		}
+		rows[y] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.Trim(strings.Join(rows, "\n"), "\n")
+}
+
+func (c *canvas) lineRune(x, y int) rune {
+	dir := c.lines[y][x]
+	if c.dashed[y][x] {
+		switch dir {
+		case left, right, left | right:
+			return '╌'
+		case up, down, up | down:
+			return '┆'
+		}
+	}
+	return lineRunes[dir]
+}
+
+// width returns the number of cells a label takes up on the canvas.
+func width(s string) int {
+	return len([]rune(s))
+}
@@ -0,0 +1,100 @@
+// Package diagram renders diagrams written in a small subset of the mermaid
+// syntax as Unicode box art, without any external tools.
+//
+// Flowcharts start with `graph` or `flowchart` and a direction, TD (top down)
+// or LR (left to right):
+//
+//	graph LR
+//	  A[Start] --> B{Decide}
+//	  B -->|yes| C(Done)
+//	  B -- no --> A
+//
+// Sequence diagrams start with `sequenceDiagram`:
+//
+//	sequenceDiagram
+//	  participant A as Alice
+//	  A->>B: Hello
+//	  B-->>A: Hi
+package diagram
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnsupported is returned for diagrams other than flowcharts and sequence
+// diagrams.
+var ErrUnsupported = errors.New("unsupported diagram, expected graph, flowchart or sequenceDiagram")
+
+type shape int
+
+const (
+	rectangle shape = iota
+	rounded
+	decision
+)
+
+// borders holds the top left, top right, bottom left and bottom right corners
+// and the horizontal and vertical edges of each shape.
+var borders = map[shape][]rune{
+	rectangle: []rune("┌┐└┘─│"),
+	rounded:   []rune("╭╮╰╯─│"),
+	decision:  []rune("╔╗╚╝═║"),
+}
+
+// Render draws the diagram.
+func Render(src string) (string, error) {
+	lines := statements(src)
+	if len(lines) == 0 {
+		return "", ErrUnsupported
+	}
+
+	fields := strings.Fields(lines[0])
+	switch fields[0] {
+	case "graph", "flowchart":
+		direction := "TD"
+		if len(fields) > 1 {
+			direction = strings.ToUpper(fields[1])
+		}
+		g, err := parseFlowchart(lines[1:])
+		if err != nil {
+			return "", err
+		}
+		return g.render(direction == "LR" || direction == "RL"), nil
+	case "sequenceDiagram":
+		s, err := parseSequence(lines[1:])
+		if err != nil {
+			return "", err
+		}
+		return s.render(), nil
+	}
+	return "", ErrUnsupported
+}
+
+// statements splits the source into statements, which are separated by new
+// lines or semicolons. Comments starting with %% are removed.
+func statements(src string) []string {
+	var rv []string
+	for _, line := range strings.Split(src, "\n") {
+		if i := strings.Index(line, "%%"); i >= 0 {
+			line = line[:i]
+		}
+		for _, s := range strings.Split(line, ";") {
+			if s = strings.TrimSpace(s); s != "" {
+				rv = append(rv, s)
+			}
+		}
+	}
+	return rv
+}
+
+// labelLines splits a label on <br> tags.
+func labelLines(label string) []string {
+	label = strings.Trim(strings.TrimSpace(label), `"`)
+	r := strings.NewReplacer("<br/>", "\n", "<br />", "\n", "<br>", "\n")
+	lines := strings.Split(r.Replace(label), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package diagram_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/diagram"
+)
+
+func TestRender(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "left to right",
+			src:  "graph LR\n  A --> B",
+			expected: `
+┌───┐     ┌───┐
+│ A ├────▶│ B │
+└───┘     └───┘`,
+		},
+		{
+			name: "top down with label",
+			src:  "flowchart TD\n  A[One] -- go --> B(Two)",
+			expected: `
+┌─────┐
+│ One │
+└──┬──┘
+   │
+   │
+   │ go
+   ▼
+╭─────╮
+│ Two │
+╰─────╯`,
+		},
+		{
+			name: "sequence",
+			src:  "sequenceDiagram\n  A->>B: hi\n  B-->>A: yo",
+			expected: `
+┌───┐  ┌───┐
+│ A │  │ B │
+└─┬─┘  └─┬─┘
+  │      │
+  │  hi  │
+  ├─────▶┤
+  │      │
+  │  yo  │
+  ├◀╌╌╌╌╌┤
+  │      │
+┌─┴─┐  ┌─┴─┐
+│ A │  │ B │
+└───┘  └───┘`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := diagram.Render(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := strings.TrimPrefix(tc.expected, "\n")
+			if got != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := diagram.Render("pie\n  \"a\": 1"); err != diagram.ErrUnsupported {
+		t.Errorf("expected unsupported diagram, got %v", err)
+	}
+	if _, err := diagram.Render("graph TD\n  A --> "); err == nil {
+		t.Error("expected an error for an incomplete edge")
+	}
+}
@@ -0,0 +1,498 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	nodeRegexp = regexp.MustCompile(`^(\w+)\s*(\(\((.*?)\)\)|\(\[(.*?)\]\)|\[(.*?)\]|\((.*?)\)|\{(.*?)\})?`)
+	edgeRegexp = regexp.MustCompile(`^(-->|---|-\.->|-\.-|==>|===|--\s+.+?\s+-->|--\s+.+?\s+---|==\s+.+?\s+==>|-\.\s+.+?\s+\.->)\s*(?:\|([^|]*)\|)?\s*`)
+)
+
+// ignoredStatements are flowchart statements which only affect styling or
+// interaction, they are skipped.
+var ignoredStatements = map[string]bool{
+	"classDef": true, "class": true, "style": true, "linkStyle": true,
+	"click": true, "subgraph": true, "end": true, "direction": true,
+}
+
+type node struct {
+	label []string
+	shape shape
+	// dummy nodes stand in for edges passing through a rank, they are drawn
+	// as a straight line.
+	dummy bool
+	// dashed is set for dummy nodes of dashed edges.
+	dashed bool
+	rank   int
+	w, h   int
+	// main and cross are the position of the node's box along and across
+	// the direction of the flowchart.
+	main, cross int
+}
+
+type edge struct {
+	from, to int
+	label    string
+	arrow    bool
+	dashed   bool
+}
+
+type flowchart struct {
+	nodes []*node
+	ids   map[string]int
+	edges []edge
+}
+
+func parseFlowchart(lines []string) (*flowchart, error) {
+	g := &flowchart{ids: make(map[string]int)}
+	for _, line := range lines {
+		if ignoredStatements[strings.Fields(line)[0]] {
+			continue
+		}
+		if err := g.parseStatement(line); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// parseStatement parses a chain of nodes and edges such as
+// A[Start] --> B -->|label| C & D.
+func (g *flowchart) parseStatement(s string) error {
+	var (
+		pending *edge
+		from    []int
+	)
+	for {
+		var to []int
+		for {
+			m := nodeRegexp.FindStringSubmatch(s)
+			if m == nil {
+				return fmt.Errorf("could not parse node in %q", s)
+			}
+			to = append(to, g.node(m))
+			s = strings.TrimSpace(s[len(m[0]):])
+			if !strings.HasPrefix(s, "&") {
+				break
+			}
+			s = strings.TrimSpace(s[1:])
+		}
+
+		if pending != nil {
+			for _, f := range from {
+				for _, t := range to {
+					e := *pending
+					e.from, e.to = f, t
+					g.edges = append(g.edges, e)
+				}
+			}
+		}
+		if s == "" {
+			return nil
+		}
+
+		m := edgeRegexp.FindStringSubmatch(s)
+		if m == nil {
+			return fmt.Errorf("could not parse edge in %q", s)
+		}
+		op := m[1]
+		pending = &edge{
+			label:  strings.TrimSpace(m[2]),
+			arrow:  strings.HasSuffix(op, ">"),
+			dashed: strings.HasPrefix(op, "-."),
+		}
+		if len(op) > 4 && strings.ContainsAny(op, " \t") {
+			// The label is inside the edge, e.g. -- label -->.
+			inner := strings.TrimSpace(op[2:])
+			inner = strings.TrimSpace(inner[:strings.LastIndexAny(inner, " \t")])
+			pending.label = strings.Trim(inner, `"`)
+		}
+		from = to
+		s = s[len(m[0]):]
+	}
+}
+
+// node returns the index of the node matched by nodeRegexp, adding it if it
+// is new. A label replaces the label of an existing node.
+func (g *flowchart) node(m []string) int {
+	id := m[1]
+	i, ok := g.ids[id]
+	if !ok {
+		i = len(g.nodes)
+		g.ids[id] = i
+		g.nodes = append(g.nodes, &node{label: []string{id}})
+	}
+
+	n := g.nodes[i]
+	switch {
+	case m[3] != "" || m[4] != "" || m[6] != "":
+		n.shape = rounded
+		n.label = labelLines(m[3] + m[4] + m[6])
+	case m[5] != "":
+		n.shape = rectangle
+		n.label = labelLines(m[5])
+	case m[7] != "":
+		n.shape = decision
+		n.label = labelLines(m[7])
+	}
+	return i
+}
+
+// render lays out the flowchart in ranks, so that edges point down (or to the
+// right) whenever possible, and draws it. Edges which point back are routed
+// around the side of the flowchart.
+func (g *flowchart) render(lr bool) string {
+	if len(g.nodes) == 0 {
+		return ""
+	}
+	a := axes{lr: lr}
+
+	back := g.backEdges()
+	g.rank(back)
+	forward, backward := g.segments(back)
+	layers := g.layers(forward)
+
+	for _, n := range g.nodes {
+		lw := 0
+		for _, l := range n.label {
+			lw = max(lw, width(l))
+		}
+		n.w, n.h = lw+4, len(n.label)+2
+		if n.dummy {
+			n.w, n.h = 1, 1
+		}
+	}
+
+	// Place the ranks along the main axis.
+	lead := 0
+	if len(backward) > 0 {
+		lead = 2
+	}
+	layerMain := make([]int, len(layers))
+	layerSize := make([]int, len(layers))
+	pos := lead
+	for r, layer := range layers {
+		layerMain[r] = pos
+		layerSize[r] = 1
+		for _, i := range layer {
+			if !g.nodes[i].dummy {
+				layerSize[r] = max(layerSize[r], a.mainSize(g.nodes[i]))
+			}
+		}
+		gap := 4
+		if lr {
+			for _, e := range forward {
+				if g.nodes[e.from].rank == r {
+					gap = max(gap, width(e.label)+5)
+				}
+			}
+		}
+		pos += layerSize[r] + gap
+	}
+
+	// Place the nodes of each rank across it, centered.
+	crossGap := 4
+	if lr {
+		crossGap = 1
+	}
+	totalCross := 0
+	for _, layer := range layers {
+		size := -crossGap
+		for _, i := range layer {
+			size += a.crossSize(g.nodes[i]) + crossGap
+		}
+		totalCross = max(totalCross, size)
+	}
+	for r, layer := range layers {
+		size := -crossGap
+		for _, i := range layer {
+			size += a.crossSize(g.nodes[i]) + crossGap
+		}
+		pos := (totalCross - size) / 2
+		for _, i := range layer {
+			n := g.nodes[i]
+			n.main = layerMain[r]
+			n.cross = pos
+			pos += a.crossSize(n) + crossGap
+		}
+	}
+
+	var c canvas
+	center := func(n *node) int { return n.cross + a.crossSize(n)/2 }
+	end := func(n *node) int {
+		if n.dummy {
+			return layerMain[n.rank] + layerSize[n.rank]
+		}
+		return n.main + a.mainSize(n)
+	}
+	// exit is where edges leaving a node start, on the border of a box so
+	// that the line joins it.
+	exit := func(n *node) int {
+		if n.dummy {
+			return end(n)
+		}
+		return end(n) - 1
+	}
+
+	for _, n := range g.nodes {
+		if n.dummy {
+			a.mainLine(&c, center(n), n.main, end(n), n.dashed)
+			continue
+		}
+		x, y := a.xy(n.main, n.cross)
+		c.box(x, y, n.w, n.h, n.shape, n.label)
+	}
+
+	var texts []func()
+	arrow := func(e edge, n *node) {
+		if !e.arrow {
+			return
+		}
+		texts = append(texts, func() {
+			x, y := a.xy(n.main-1, center(n))
+			c.set(x, y, a.arrow())
+		})
+	}
+	leave := func(n *node) {
+		if n.dummy {
+			return
+		}
+		texts = append(texts, func() {
+			x, y := a.xy(end(n)-1, center(n))
+			c.joinBorder(x, y, a.forward())
+		})
+	}
+
+	for _, e := range forward {
+		s, t := g.nodes[e.from], g.nodes[e.to]
+		gap := layerMain[s.rank] + layerSize[s.rank]
+		turn := gap + 1
+
+		a.mainLine(&c, center(s), exit(s), turn, e.dashed)
+		a.crossLine(&c, turn, center(s), center(t), e.dashed)
+		if t.dummy {
+			a.mainLine(&c, center(t), turn, t.main, e.dashed)
+			continue
+		}
+		a.mainLine(&c, center(t), turn, t.main-1, e.dashed)
+		leave(s)
+		arrow(e, t)
+
+		if e.label != "" {
+			label, main, cross := e.label, gap+2, center(t)+2
+			if lr {
+				cross = center(t) - 1
+			}
+			texts = append(texts, func() {
+				x, y := a.xy(main, cross)
+				c.write(x, y, label)
+			})
+		}
+	}
+
+	spacing := 2
+	if !lr {
+		for _, e := range backward {
+			spacing = max(spacing, width(e.label)+3)
+		}
+	}
+	for k, e := range backward {
+		s, t := g.nodes[e.from], g.nodes[e.to]
+		gap := layerMain[s.rank] + layerSize[s.rank]
+		channel := totalCross + 1 + k*spacing
+		entry := t.main - 2
+
+		a.mainLine(&c, center(s), exit(s), gap, e.dashed)
+		a.crossLine(&c, gap, center(s), channel, e.dashed)
+		a.mainLine(&c, channel, gap, entry, e.dashed)
+		a.crossLine(&c, entry, channel, center(t), e.dashed)
+		a.mainLine(&c, center(t), entry, t.main-1, e.dashed)
+		leave(s)
+		arrow(e, t)
+
+		if e.label != "" {
+			label := e.label
+			x, y := channel+2, (gap+entry)/2
+			if lr {
+				x, y = (gap+entry-width(label))/2, channel+1
+			}
+			texts = append(texts, func() { c.write(x, y, label) })
+		}
+	}
+
+	for _, f := range texts {
+		f()
+	}
+	return c.String()
+}
+
+// backEdges returns which edges point back to a node that leads to them, a
+// depth first search from the nodes in the order they were declared.
+func (g *flowchart) backEdges() []bool {
+	back := make([]bool, len(g.edges))
+	state := make([]int, len(g.nodes))
+
+	var visit func(n int)
+	visit = func(n int) {
+		state[n] = 1
+		for i, e := range g.edges {
+			if e.from != n {
+				continue
+			}
+			switch state[e.to] {
+			case 0:
+				visit(e.to)
+			case 1:
+				back[i] = true
+			}
+		}
+		state[n] = 2
+	}
+	for n := range g.nodes {
+		if state[n] == 0 {
+			visit(n)
+		}
+	}
+	return back
+}
+
+// rank places every node one rank after the furthest node pointing to it.
+func (g *flowchart) rank(back []bool) {
+	for range g.nodes {
+		changed := false
+		for i, e := range g.edges {
+			if !back[i] && g.nodes[e.to].rank < g.nodes[e.from].rank+1 {
+				g.nodes[e.to].rank = g.nodes[e.from].rank + 1
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+}
+
+// segments splits the edges into forward segments between adjacent ranks,
+// adding dummy nodes for edges which span several ranks, and back edges.
+func (g *flowchart) segments(back []bool) ([]edge, []edge) {
+	var forward, backward []edge
+	for i, e := range g.edges {
+		if back[i] {
+			backward = append(backward, e)
+			continue
+		}
+		from := e.from
+		for r := g.nodes[e.from].rank + 1; r < g.nodes[e.to].rank; r++ {
+			g.nodes = append(g.nodes, &node{dummy: true, dashed: e.dashed, rank: r})
+			dummy := len(g.nodes) - 1
+			forward = append(forward, edge{from: from, to: dummy, dashed: e.dashed})
+			from = dummy
+		}
+		e.from = from
+		forward = append(forward, e)
+	}
+	return forward, backward
+}
+
+// layers groups the nodes by rank and orders each rank by the average
+// position of the nodes pointing to them, to reduce crossing edges.
+func (g *flowchart) layers(forward []edge) [][]int {
+	var layers [][]int
+	for i, n := range g.nodes {
+		for len(layers) <= n.rank {
+			layers = append(layers, nil)
+		}
+		layers[n.rank] = append(layers[n.rank], i)
+	}
+
+	position := make([]float64, len(g.nodes))
+	for _, layer := range layers {
+		for p, i := range layer {
+			position[i] = float64(p)
+		}
+	}
+	for r := 1; r < len(layers); r++ {
+		key := make(map[int]float64)
+		for _, i := range layers[r] {
+			sum, count := 0.0, 0
+			for _, e := range forward {
+				if e.to == i {
+					sum += position[e.from]
+					count++
+				}
+			}
+			key[i] = position[i]
+			if count > 0 {
+				key[i] = sum / float64(count)
+			}
+		}
+		sort.SliceStable(layers[r], func(a, b int) bool {
+			return key[layers[r][a]] < key[layers[r][b]]
+		})
+		for p, i := range layers[r] {
+			position[i] = float64(p)
+		}
+	}
+	return layers
+}
+
+// axes maps positions along (main) and across (cross) the direction of a
+// flowchart to the canvas.
+type axes struct {
+	lr bool
+}
+
+func (a axes) xy(main, cross int) (int, int) {
+	if a.lr {
+		return main, cross
+	}
+	return cross, main
+}
+
+func (a axes) mainSize(n *node) int {
+	if a.lr {
+		return n.w
+	}
+	return n.h
+}
+
+func (a axes) crossSize(n *node) int {
+	if a.lr {
+		return n.h
+	}
+	return n.w
+}
+
+func (a axes) mainLine(c *canvas, cross, from, to int, dashed bool) {
+	if a.lr {
+		c.hline(from, to, cross, dashed)
+	} else {
+		c.vline(cross, from, to, dashed)
+	}
+}
+
+func (a axes) crossLine(c *canvas, main, from, to int, dashed bool) {
+	if a.lr {
+		c.vline(main, from, to, dashed)
+	} else {
+		c.hline(from, to, main, dashed)
+	}
+}
+
+func (a axes) arrow() rune {
+	if a.lr {
+		return '▶'
+	}
+	return '▼'
+}
+
+func (a axes) forward() uint8 {
+	if a.lr {
+		return right
+	}
+	return down
+}
@@ -0,0 +1,234 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	participantRegexp = regexp.MustCompile(`^(?:participant|actor)\s+(\w+)(?:\s+as\s+(.+))?$`)
+	messageRegexp     = regexp.MustCompile(`^(\w+)\s*(-->>|->>|--x|-x|--\)|-\)|-->|->)\s*[+-]?(\w+)\s*(?::\s*(.*))?$`)
+	noteRegexp        = regexp.MustCompile(`^(?i:note)\s+(over|left of|right of)\s+(\w+)(?:\s*,\s*(\w+))?\s*:\s*(.*)$`)
+)
+
+// ignoredSequenceStatements are statements of sequence diagrams which are not
+// drawn.
+var ignoredSequenceStatements = map[string]bool{
+	"autonumber": true, "activate": true, "deactivate": true, "title": true,
+	"loop": true, "alt": true, "else": true, "opt": true, "par": true,
+	"and": true, "critical": true, "break": true, "rect": true, "end": true,
+}
+
+type participant struct {
+	label  string
+	center int
+}
+
+// step is a message between participants or a note, drawn one below the
+// other.
+type step struct {
+	from, to int
+	text     string
+	dashed   bool
+	// head is drawn at the end of a message, zero for a plain line.
+	head rune
+	// note is "over", "left of" or "right of" for notes.
+	note string
+}
+
+type sequence struct {
+	participants []participant
+	ids          map[string]int
+	steps        []step
+}
+
+func parseSequence(lines []string) (*sequence, error) {
+	s := &sequence{ids: make(map[string]int)}
+	for _, line := range lines {
+		if m := participantRegexp.FindStringSubmatch(line); m != nil {
+			i := s.participant(m[1])
+			if m[2] != "" {
+				s.participants[i].label = strings.TrimSpace(m[2])
+			}
+			continue
+		}
+		if m := messageRegexp.FindStringSubmatch(line); m != nil {
+			st := step{from: s.participant(m[1]), to: s.participant(m[3]), text: strings.TrimSpace(m[4])}
+			st.dashed = strings.HasPrefix(m[2], "--")
+			switch {
+			case strings.HasSuffix(m[2], ">>"), strings.HasSuffix(m[2], ")"):
+				st.head = '▶'
+			case strings.HasSuffix(m[2], "x"):
+				st.head = '×'
+			}
+			s.steps = append(s.steps, st)
+			continue
+		}
+		if m := noteRegexp.FindStringSubmatch(line); m != nil {
+			st := step{from: s.participant(m[2]), note: strings.ToLower(m[1]), text: strings.TrimSpace(m[4])}
+			st.to = st.from
+			if m[3] != "" {
+				st.to = s.participant(m[3])
+			}
+			s.steps = append(s.steps, st)
+			continue
+		}
+		if ignoredSequenceStatements[strings.Fields(line)[0]] {
+			continue
+		}
+		return nil, fmt.Errorf("could not parse %q", line)
+	}
+	return s, nil
+}
+
+func (s *sequence) participant(id string) int {
+	if i, ok := s.ids[id]; ok {
+		return i
+	}
+	s.ids[id] = len(s.participants)
+	s.participants = append(s.participants, participant{label: id})
+	return len(s.participants) - 1
+}
+
+// layout spaces the participants so that their boxes, the messages between
+// them and the notes next to them fit.
+func (s *sequence) layout() {
+	p := s.participants
+	p[0].center = width(p[0].label)/2 + 2
+	for i := 1; i < len(p); i++ {
+		prev, w := width(p[i-1].label)+4, width(p[i].label)+4
+		p[i].center = p[i-1].center - prev/2 + prev + 2 + w/2
+	}
+
+	// require moves the participants from hi onwards so that they are at
+	// least distance away from participant lo.
+	require := func(lo, hi, distance int) {
+		if hi >= len(p) || lo < 0 {
+			return
+		}
+		if d := distance - (p[hi].center - p[lo].center); d > 0 {
+			for k := hi; k < len(p); k++ {
+				p[k].center += d
+			}
+		}
+	}
+
+	// margin moves all participants so that the first one is at least
+	// center cells from the left.
+	margin := func(center int) {
+		if d := center - p[0].center; d > 0 {
+			for k := range p {
+				p[k].center += d
+			}
+		}
+	}
+
+	for _, st := range s.steps {
+		lo, hi := min(st.from, st.to), max(st.from, st.to)
+		w := s.noteWidth(st)
+		switch {
+		case st.note == "right of":
+			require(st.from, st.from+1, w+4)
+		case st.note == "left of":
+			require(st.from-1, st.from, w+4)
+			if st.from == 0 {
+				margin(w + 2)
+			}
+		case st.note != "":
+			require(lo, hi, w-4)
+			if lo == 0 {
+				margin(w / 2)
+			}
+		case lo == hi:
+			require(lo, lo+1, width(st.text)+8)
+		default:
+			require(lo, hi, width(st.text)+4)
+		}
+	}
+}
+
+func (s *sequence) noteWidth(st step) int {
+	w := 0
+	for _, l := range labelLines(st.text) {
+		w = max(w, width(l))
+	}
+	return w + 4
+}
+
+func (s *sequence) render() string {
+	if len(s.participants) == 0 {
+		return ""
+	}
+	s.layout()
+
+	var c canvas
+	header := func(y int) {
+		for _, p := range s.participants {
+			w := width(p.label) + 4
+			c.box(p.center-w/2, y, w, 3, rectangle, []string{p.label})
+		}
+	}
+	header(0)
+
+	y := 4
+	for _, st := range s.steps {
+		from, to := s.participants[st.from].center, s.participants[st.to].center
+		switch {
+		case st.note != "":
+			lines := labelLines(st.text)
+			w := s.noteWidth(st)
+			x := from - w/2
+			switch st.note {
+			case "right of":
+				x = from + 2
+			case "left of":
+				x = from - 2 - w
+			default:
+				if st.to != st.from {
+					x = min(from, to) - 2
+					w = max(w, max(from, to)-min(from, to)+5)
+				}
+			}
+			c.box(x, y, w, len(lines)+2, rounded, lines)
+			y += len(lines) + 3
+			continue
+		case st.from == st.to:
+			c.hline(from, from+3, y, st.dashed)
+			c.vline(from+3, y, y+1, st.dashed)
+			c.hline(from+3, from+1, y+1, st.dashed)
+			c.write(from+5, y, st.text)
+			if st.head != 0 {
+				c.set(from+1, y+1, mirror(st.head))
+			}
+		default:
+			c.write((from+to-width(st.text))/2+1, y, st.text)
+			c.hline(from, to, y+1, st.dashed)
+			switch {
+			case st.head == 0:
+			case from < to:
+				c.set(to-1, y+1, st.head)
+			default:
+				c.set(to+1, y+1, mirror(st.head))
+			}
+		}
+		y += 3
+	}
+
+	for _, p := range s.participants {
+		c.vline(p.center, 3, y-1, false)
+	}
+	header(y)
+	for _, p := range s.participants {
+		c.joinBorder(p.center, 2, down)
+		c.joinBorder(p.center, y, up)
+	}
+	return c.String()
+}
+
+func mirror(head rune) rune {
+	if head == '▶' {
+		return '◀'
+	}
+	return head
+}
@@ -0,0 +1,310 @@
+// Package figlet draws big text with FIGlet fonts, for terminals which do
+// not show images.
+//
+// Fonts are read from .flf files as described in
+// http://www.jave.de/figlet/figfont.html, with the horizontal fitting and
+// smushing rules. Vertical layout is not supported.
+package figlet
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//go:embed fonts/*.flf
+var fonts embed.FS
+
+// Fonts are the names of the bundled fonts.
+var Fonts = []string{"block", "shadow", "small"}
+
+// Layout bits of the full_layout field of a font, the lower six bits are the
+// horizontal smushing rules.
+const (
+	smushEqual     = 1
+	smushLowline   = 2
+	smushHierarchy = 4
+	smushPair      = 8
+	smushBigX      = 16
+	smushHardblank = 32
+	fitting        = 64
+	smushing       = 128
+)
+
+// Font is a FIGlet font.
+type Font struct {
+	height    int
+	hardblank rune
+	layout    int
+	chars     map[rune][][]rune
+}
+
+// ErrNotFont is returned for files which are not FIGlet fonts.
+var ErrNotFont = errors.New("figlet: not a FIGlet font")
+
+// Load loads the bundled font with the given name, or else the font file at
+// the path.
+func Load(name string) (*Font, error) {
+	f, err := fonts.Open("fonts/" + name + ".flf")
+	if err != nil {
+		f, err = os.Open(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a font in the .flf format.
+func Parse(r io.Reader) (*Font, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, ErrNotFont
+	}
+	header := strings.Fields(scanner.Text())
+	if len(header) < 6 || !strings.HasPrefix(header[0], "flf2a") || len(header[0]) < 6 {
+		return nil, ErrNotFont
+	}
+	var fields [8]int
+	for i, field := range header[1:min(len(header), 8)] {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("figlet: invalid header: %w", err)
+		}
+		fields[i+1] = n
+	}
+	f := &Font{
+		height:    fields[1],
+		hardblank: []rune(header[0])[5],
+		chars:     map[rune][][]rune{},
+	}
+	if f.height < 1 {
+		return nil, fmt.Errorf("figlet: invalid height %d", f.height)
+	}
+
+	// The full layout supersedes the old one.
+	if len(header) > 7 {
+		f.layout = fields[7]
+	} else {
+		switch old := fields[4]; {
+		case old == 0:
+			f.layout = fitting
+		case old > 0:
+			f.layout = smushing | old&63
+		}
+	}
+
+	for i := 0; i < fields[5] && scanner.Scan(); i++ {
+	}
+
+	readChar := func() ([][]rune, bool) {
+		lines := make([][]rune, f.height)
+		for i := range lines {
+			if !scanner.Scan() {
+				return nil, false
+			}
+			line := []rune(strings.TrimRight(scanner.Text(), " \t\r"))
+			if len(line) > 0 {
+				// Lines end with one or more end marks.
+				end := line[len(line)-1]
+				for len(line) > 0 && line[len(line)-1] == end {
+					line = line[:len(line)-1]
+				}
+			}
+			lines[i] = line
+		}
+		width := 0
+		for _, line := range lines {
+			width = max(width, len(line))
+		}
+		for i, line := range lines {
+			for len(line) < width {
+				line = append(line, ' ')
+			}
+			lines[i] = line
+		}
+		return lines, true
+	}
+
+	// The required characters are the printable ASCII characters followed
+	// by seven German ones, the others are tagged with their code.
+	for c := rune(32); c < 127; c++ {
+		lines, ok := readChar()
+		if !ok {
+			return nil, fmt.Errorf("figlet: missing character %q", c)
+		}
+		f.chars[c] = lines
+	}
+	for _, c := range []rune{'Ä', 'Ö', 'Ü', 'ä', 'ö', 'ü', 'ß'} {
+		lines, ok := readChar()
+		if !ok {
+			return f, nil
+		}
+		f.chars[c] = lines
+	}
+	for scanner.Scan() {
+		tag := strings.Fields(scanner.Text())
+		if len(tag) == 0 {
+			continue
+		}
+		code, err := strconv.ParseInt(tag[0], 0, 32)
+		if err != nil {
+			break
+		}
+		lines, ok := readChar()
+		if !ok {
+			break
+		}
+		if code >= 0 {
+			f.chars[rune(code)] = lines
+		}
+	}
+	return f, scanner.Err()
+}
+
+// Height returns the number of lines of text drawn with the font.
+func (f *Font) Height() int {
+	return f.height
+}
+
+// Render draws text with the font, characters which are not in the font
+// are left out.
+func (f *Font) Render(text string) []string {
+	out := make([][]rune, f.height)
+	prevWidth := 0
+	for _, c := range text {
+		char, ok := f.chars[c]
+		if !ok {
+			continue
+		}
+		width := len(char[0])
+		amount := f.smushAmount(out, char, prevWidth, width)
+		for row := range out {
+			line := out[row]
+			for k := 0; k < amount; k++ {
+				if col := len(line) - amount + k; col >= 0 {
+					line[col] = f.smush(line[col], char[row][k], prevWidth, width)
+				}
+			}
+			out[row] = append(line, char[row][min(amount, width):]...)
+		}
+		prevWidth = width
+	}
+
+	lines := make([]string, f.height)
+	for i, line := range out {
+		lines[i] = strings.ReplaceAll(string(line), string(f.hardblank), " ")
+	}
+	return lines
+}
+
+// smushAmount returns the number of columns a character moves into the
+// text drawn so far, which is the smallest of the rows.
+func (f *Font) smushAmount(out, char [][]rune, prevWidth, width int) int {
+	if f.layout&(fitting|smushing) == 0 {
+		return 0
+	}
+	amount := width
+	for row := range out {
+		line := out[row]
+		lineEnd := len(line) - 1
+		for lineEnd > 0 && line[lineEnd] == ' ' {
+			lineEnd--
+		}
+		charStart := 0
+		for charStart < width && char[row][charStart] == ' ' {
+			charStart++
+		}
+
+		n := charStart + len(line) - 1 - max(lineEnd, 0)
+		switch {
+		case lineEnd < 0 || line[lineEnd] == ' ':
+			n++
+		case charStart < width && f.smush(line[lineEnd], char[row][charStart], prevWidth, width) != 0:
+			n++
+		}
+		amount = min(amount, n)
+	}
+	return max(amount, 0)
+}
+
+// smush returns the character two overlapping characters are smushed into,
+// or zero if they can not be.
+func (f *Font) smush(left, right rune, prevWidth, width int) rune {
+	if left == ' ' {
+		return right
+	}
+	if right == ' ' {
+		return left
+	}
+	if prevWidth < 2 || width < 2 || f.layout&smushing == 0 {
+		return 0
+	}
+
+	// Universal smushing, the right character wins.
+	if f.layout&63 == 0 {
+		if right == f.hardblank {
+			return left
+		}
+		return right
+	}
+
+	if left == f.hardblank || right == f.hardblank {
+		if left == right && f.layout&smushHardblank != 0 {
+			return left
+		}
+		return 0
+	}
+	if f.layout&smushEqual != 0 && left == right {
+		return left
+	}
+	if f.layout&smushLowline != 0 {
+		if left == '_' && strings.ContainsRune(`|/\[]{}()<>`, right) {
+			return right
+		}
+		if right == '_' && strings.ContainsRune(`|/\[]{}()<>`, left) {
+			return left
+		}
+	}
+	if f.layout&smushHierarchy != 0 {
+		classes := []string{"|", `/\`, "[]", "{}", "()", "<>"}
+		class := func(c rune) int {
+			for i, cls := range classes {
+				if strings.ContainsRune(cls, c) {
+					return i
+				}
+			}
+			return -1
+		}
+		l, r := class(left), class(right)
+		if l >= 0 && r >= 0 && l != r {
+			if l > r {
+				return left
+			}
+			return right
+		}
+	}
+	if f.layout&smushPair != 0 {
+		switch string([]rune{left, right}) {
+		case "[]", "][", "{}", "}{", "()", ")(":
+			return '|'
+		}
+	}
+	if f.layout&smushBigX != 0 {
+		switch string([]rune{left, right}) {
+		case `/\`:
+			return '|'
+		case `\/`:
+			return 'Y'
+		case "><":
+			return 'X'
+		}
+	}
+	return 0
+}
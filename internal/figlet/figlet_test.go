@@ -0,0 +1,85 @@
+package figlet_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/figlet"
+)
+
+// font returns a font of two lines whose characters are drawn by the given
+// glyphs, in the order of the printable ASCII characters from "A".
+func font(t *testing.T, layout string, glyphs map[rune][2]string) *figlet.Font {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("flf2a$ 2 2 8 " + layout + " 0\n")
+	for c := rune(32); c < 127; c++ {
+		g, ok := glyphs[c]
+		if !ok {
+			g = [2]string{"", ""}
+		}
+		b.WriteString(g[0] + "@\n" + g[1] + "@@\n")
+	}
+	f, err := figlet.Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestLayout(t *testing.T) {
+	glyphs := map[rune][2]string{
+		'/':  {" /", "/ "},
+		'\\': {"\\ ", " \\"},
+		'|':  {"| ", "| "},
+		'_':  {"  ", "__"},
+	}
+	tests := []struct {
+		layout string
+		text   string
+		want   []string
+	}{
+		{"-1", "||", []string{"| | ", "| | "}},
+		{"0", "||", []string{"|| ", "|| "}},
+		{"0", "/\\", []string{" /\\ ", "/  \\"}},
+		{"1", "||", []string{"| ", "| "}},
+		{"16", "/\\", []string{" | ", "/ \\"}},
+		{"2", "_|", []string{" | ", "_| "}},
+	}
+	for _, tt := range tests {
+		got := font(t, tt.layout, glyphs).Render(tt.text)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("layout %s, %q:\nexpected\n%s\ngot\n%s", tt.layout, tt.text, strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+		}
+	}
+}
+
+func TestHardblank(t *testing.T) {
+	f := font(t, "-1", map[rune][2]string{'a': {"a$", "a$"}})
+	got := f.Render("aa")
+	if got[0] != "a a " {
+		t.Errorf("expected hardblanks to be spaces, got %q", got[0])
+	}
+}
+
+func TestBundled(t *testing.T) {
+	for _, name := range figlet.Fonts {
+		f, err := figlet.Load(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := f.Render("Slides 42!")
+		if len(lines) != f.Height() {
+			t.Errorf("%s: expected %d lines, got %d", name, f.Height(), len(lines))
+		}
+		if strings.TrimSpace(strings.Join(lines, "")) == "" {
+			t.Errorf("%s: expected text to be drawn", name)
+		}
+	}
+}
+
+func TestNotFont(t *testing.T) {
+	if _, err := figlet.Parse(strings.NewReader("# Slides\n")); err != figlet.ErrNotFont {
+		t.Errorf("expected ErrNotFont, got %v", err)
+	}
+}
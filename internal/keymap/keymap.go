@@ -0,0 +1,301 @@
+// Package keymap maps the keys of the presentation to its actions, the keys
+// can be changed by a configuration file and by the front matter of a
+// presentation.
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"gopkg.in/yaml.v2"
+)
+
+// KeyMap holds the key bindings of the actions of the presentation.
+type KeyMap struct {
+	Next            key.Binding
+	Previous        key.Binding
+	First           key.Binding
+	Last            key.Binding
+	NextSection     key.Binding
+	PreviousSection key.Binding
+
+	Overview       key.Binding
+	Titles         key.Binding
+	Find           key.Binding
+	Search         key.Binding
+	SearchBackward key.Binding
+	NextMatch      key.Binding
+	PreviousMatch  key.Binding
+	Clear          key.Binding
+
+	Execute       key.Binding
+	Copy          key.Binding
+	FocusNext     key.Binding
+	FocusPrevious key.Binding
+	FocusPane     key.Binding
+	FinishTyping  key.Binding
+	TypeLine      key.Binding
+	LeavePane     key.Binding
+
+	Play         key.Binding
+	SeekBackward key.Binding
+	SeekForward  key.Binding
+	Slower       key.Binding
+	Faster       key.Binding
+
+	Help key.Binding
+	Quit key.Binding
+}
+
+// Default returns the default key bindings.
+func Default() KeyMap {
+	return KeyMap{
+		Next:            binding("next slide", " ", "down", "j", "right", "l", "enter", "n", "pgdown"),
+		Previous:        binding("previous slide", "up", "k", "left", "h", "p", "pgup", "N"),
+		First:           first("g"),
+		Last:            binding("last slide", "G"),
+		NextSection:     binding("next section", "}"),
+		PreviousSection: binding("previous section", "{"),
+
+		Overview:       binding("overview", "o"),
+		Titles:         binding("go to title", "t"),
+		Find:           binding("find slides", "f"),
+		Search:         binding("search", "/"),
+		SearchBackward: binding("search backward", "#"),
+		NextMatch:      binding("next match", "ctrl+n"),
+		PreviousMatch:  binding("previous match", "ctrl+r"),
+		Clear:          binding("clear", "ctrl+x"),
+
+		Execute:       binding("execute code", "ctrl+e"),
+		Copy:          binding("copy code", "y"),
+		FocusNext:     binding("focus next block", "tab"),
+		FocusPrevious: binding("focus previous block", "shift+tab"),
+		FocusPane:     binding("focus terminal", "i"),
+		FinishTyping:  binding("finish typing", "s"),
+		TypeLine:      binding("type a line", ">"),
+		LeavePane:     binding("leave terminal", "ctrl+]"),
+
+		Play:         binding("play or pause", "ctrl+p"),
+		SeekBackward: binding("seek backward", "["),
+		SeekForward:  binding("seek forward", "]"),
+		Slower:       binding("slower", "-"),
+		Faster:       binding("faster", "+", "="),
+
+		Help: binding("help", "?"),
+		Quit: binding("quit", "q", "ctrl+c"),
+	}
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	b := key.NewBinding(key.WithKeys(keys...))
+	b.SetHelp(helpKeys(keys), desc)
+	return b
+}
+
+// first returns the binding of the first slide, which is gone to by
+// pressing g twice.
+func first(keys ...string) key.Binding {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		if k == "g" {
+			k = "gg"
+		}
+		names[i] = k
+	}
+	b := key.NewBinding(key.WithKeys(keys...))
+	b.SetHelp(helpKeys(names), "first slide")
+	return b
+}
+
+// actions returns the bindings of the key map by the names of their actions
+// in configuration files.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"next":             &k.Next,
+		"previous":         &k.Previous,
+		"first":            &k.First,
+		"last":             &k.Last,
+		"next-section":     &k.NextSection,
+		"previous-section": &k.PreviousSection,
+		"overview":         &k.Overview,
+		"titles":           &k.Titles,
+		"find":             &k.Find,
+		"search":           &k.Search,
+		"search-backward":  &k.SearchBackward,
+		"next-match":       &k.NextMatch,
+		"previous-match":   &k.PreviousMatch,
+		"clear":            &k.Clear,
+		"execute":          &k.Execute,
+		"copy":             &k.Copy,
+		"focus-next":       &k.FocusNext,
+		"focus-previous":   &k.FocusPrevious,
+		"focus-pane":       &k.FocusPane,
+		"finish-typing":    &k.FinishTyping,
+		"type-line":        &k.TypeLine,
+		"leave-pane":       &k.LeavePane,
+		"play":             &k.Play,
+		"seek-backward":    &k.SeekBackward,
+		"seek-forward":     &k.SeekForward,
+		"slower":           &k.Slower,
+		"faster":           &k.Faster,
+		"help":             &k.Help,
+		"quit":             &k.Quit,
+	}
+}
+
+// Keys are the keys of an action in a configuration file, a single key or a
+// list of them.
+type Keys []string
+
+// UnmarshalYAML reads a single key or a list of keys.
+func (k *Keys) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*k = Keys{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*k = list
+	return nil
+}
+
+// With returns the key map with the keys of the given actions replaced. An
+// action without keys is turned off.
+func (k KeyMap) With(overrides map[string]Keys) (KeyMap, error) {
+	actions := k.actions()
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			return k, fmt.Errorf("unknown action %q", name)
+		}
+		keys = normalize(keys)
+		if len(keys) == 0 {
+			b.Unbind()
+			continue
+		}
+		if b == &k.First {
+			*b = first(keys...)
+			continue
+		}
+		*b = binding(b.Help().Desc, keys...)
+	}
+	return k, nil
+}
+
+// normalize returns the keys with the names which are easier to write in
+// configuration files replaced by the names of the keys in key messages.
+func normalize(keys Keys) Keys {
+	normalized := make(Keys, 0, len(keys))
+	for _, k := range keys {
+		switch k = strings.TrimSpace(k); k {
+		case "":
+			continue
+		case "space":
+			k = " "
+		case "pageup":
+			k = "pgup"
+		case "pagedown":
+			k = "pgdown"
+		case "return":
+			k = "enter"
+		}
+		normalized = append(normalized, k)
+	}
+	return normalized
+}
+
+// helpKeys returns the keys of a binding as they are shown in the help, up to
+// four of them.
+func helpKeys(keys []string) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		switch k {
+		case " ":
+			k = "space"
+		case "up":
+			k = "↑"
+		case "down":
+			k = "↓"
+		case "left":
+			k = "←"
+		case "right":
+			k = "→"
+		}
+		names = append(names, k)
+	}
+	if len(names) > 4 {
+		names = append(names[:4], "…")
+	}
+	return strings.Join(names, "/")
+}
+
+// Path returns the path of the configuration file of the key map.
+func Path() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "slides", "keymap.yaml")
+}
+
+// Load returns the default key map with the keys of the configuration file
+// at path, the default key map if there is no such file.
+func Load(path string) (KeyMap, error) {
+	k := Default()
+	if path == "" {
+		return k, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return k, nil
+	}
+	if err != nil {
+		return k, err
+	}
+	var overrides map[string]Keys
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return k, fmt.Errorf("%s: %w", path, err)
+	}
+	k, err = k.With(overrides)
+	if err != nil {
+		return k, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// Has returns whether keyPress is one of the keys of an enabled binding.
+func Has(b key.Binding, keyPress string) bool {
+	if !b.Enabled() {
+		return false
+	}
+	for _, k := range b.Keys() {
+		if k == keyPress {
+			return true
+		}
+	}
+	return false
+}
+
+// ShortHelp returns the bindings shown in short help, it implements
+// help.KeyMap.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Previous, k.Overview, k.Find, k.Help, k.Quit}
+}
+
+// FullHelp returns the columns of bindings shown in the help overlay, it
+// implements help.KeyMap.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Next, k.Previous, k.First, k.Last, k.NextSection, k.PreviousSection},
+		{k.Overview, k.Titles, k.Find, k.Search, k.SearchBackward, k.NextMatch, k.PreviousMatch, k.Clear},
+		{k.Execute, k.Copy, k.FocusNext, k.FocusPrevious, k.FocusPane, k.LeavePane, k.FinishTyping, k.TypeLine},
+		{k.Play, k.SeekBackward, k.SeekForward, k.Slower, k.Faster, k.Help, k.Quit},
+	}
+}
@@ -0,0 +1,63 @@
+package keymap_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/keymap"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDefault(t *testing.T) {
+	k := keymap.Default()
+	assert.True(t, keymap.Has(k.Next, " "))
+	assert.True(t, keymap.Has(k.Previous, "pgup"))
+	assert.True(t, keymap.Has(k.Help, "?"))
+	assert.True(t, keymap.Has(k.LeavePane, "ctrl+]"))
+	assert.True(t, keymap.Has(k.Faster, "="))
+	assert.False(t, keymap.Has(k.Next, "b"))
+	assert.Equal(t, "gg", k.First.Help().Key)
+	assert.Equal(t, "space/↓/j/→/…", k.Next.Help().Key)
+}
+
+func TestWith(t *testing.T) {
+	var overrides map[string]keymap.Keys
+	err := yaml.Unmarshal([]byte("next: [space, \".\", pagedown]\nprevious: b\ncopy: []\nplay: p\n"), &overrides)
+	assert.NoError(t, err)
+
+	k, err := keymap.Default().With(overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{" ", ".", "pgdown"}, k.Next.Keys())
+	assert.Equal(t, "space/./pgdown", k.Next.Help().Key)
+	assert.Equal(t, "next slide", k.Next.Help().Desc)
+	assert.Equal(t, []string{"b"}, k.Previous.Keys())
+	assert.False(t, keymap.Has(k.Copy, "y"))
+	assert.True(t, keymap.Has(k.Quit, "q"))
+	assert.Equal(t, []string{"p"}, k.Play.Keys())
+	assert.Equal(t, "play or pause", k.Play.Help().Desc)
+
+	_, err = keymap.Default().With(map[string]keymap.Keys{"jump": {"x"}})
+	assert.EqualError(t, err, `unknown action "jump"`)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	k, err := keymap.Load(filepath.Join(dir, "missing.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, keymap.Default().Next.Keys(), k.Next.Keys())
+
+	path := filepath.Join(dir, "keymap.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("first: home\nlast: [end, G]\n"), 0o600))
+	k, err = keymap.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"home"}, k.First.Keys())
+	assert.Equal(t, "home", k.First.Help().Key)
+	assert.Equal(t, []string{"end", "G"}, k.Last.Keys())
+
+	assert.NoError(t, os.WriteFile(path, []byte("next: [\n"), 0o600))
+	_, err = keymap.Load(path)
+	assert.Error(t, err)
+}
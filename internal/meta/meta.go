@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/maaslalani/slides/internal/keymap"
 	"gopkg.in/yaml.v2"
 )
 
@@ -19,6 +20,11 @@ type parsedMeta struct {
 	Author *string `yaml:"author"`
 	Date   *string `yaml:"date"`
 	Paging *string `yaml:"paging"`
+	Header *Header `yaml:"header"`
+
+	Transition *string `yaml:"transition"`
+
+	Keys map[string]keymap.Keys `yaml:"keys"`
 }
 
 // Meta contains all of the data to be parsed
@@ -28,6 +34,37 @@ type Meta struct {
 	Author string
 	Date   string
 	Paging string
+	Header Header
+	// Transition is the transition between the slides, e.g. fade.
+	Transition string
+	// Keys override the keys of actions of the presentation by their names.
+	Keys map[string]keymap.Keys
+}
+
+// Header configures the headers which are drawn as big text, as images on
+// terminals which show them.
+type Header struct {
+	// Font is the path to a TrueType font, the embedded Fira Mono is used
+	// if it is empty.
+	Font string `yaml:"font"`
+	// Size is the height of the header in rows.
+	Size int `yaml:"size"`
+	// Color is a hex color, or an ANSI color number.
+	Color string `yaml:"color"`
+	// Weight is normal or bold.
+	Weight string `yaml:"weight"`
+	// Levels are the levels of the headings which are drawn, the first of
+	// them on a slide is.
+	Levels []int `yaml:"levels"`
+	// Style is image, or figlet for headers drawn with a FIGlet font even on
+	// terminals which show images. Terminals which do not always use FIGlet.
+	Style string `yaml:"style"`
+	// Figlet is the name of a bundled FIGlet font, or the path to a .flf
+	// file.
+	Figlet string `yaml:"figlet"`
+	// Gradient are the colors FIGlet headers fade through from left to
+	// right, instead of Color.
+	Gradient []string `yaml:"gradient"`
 }
 
 // New creates a new instance of the
@@ -47,6 +84,7 @@ func (m *Meta) Parse(header string) (*Meta, bool) {
 		Author: defaultAuthor(),
 		Date:   defaultDate(),
 		Paging: defaultPaging(),
+		Header: defaultHeader(),
 	}
 
 	var tmp parsedMeta
@@ -84,6 +122,38 @@ func (m *Meta) Parse(header string) (*Meta, bool) {
 		m.Paging = fallback.Paging
 	}
 
+	if tmp.Transition != nil {
+		m.Transition = *tmp.Transition
+	}
+
+	m.Keys = tmp.Keys
+
+	m.Header = fallback.Header
+	if h := tmp.Header; h != nil {
+		if h.Font != "" {
+			m.Header.Font = h.Font
+		}
+		if h.Size > 0 {
+			m.Header.Size = h.Size
+		}
+		if h.Color != "" {
+			m.Header.Color = h.Color
+		}
+		if h.Weight != "" {
+			m.Header.Weight = h.Weight
+		}
+		if len(h.Levels) > 0 {
+			m.Header.Levels = h.Levels
+		}
+		if h.Style != "" {
+			m.Header.Style = h.Style
+		}
+		if h.Figlet != "" {
+			m.Header.Figlet = h.Figlet
+		}
+		m.Header.Gradient = h.Gradient
+	}
+
 	return m, true
 }
 
@@ -112,6 +182,17 @@ func defaultPaging() string {
 	return "Slide %d / %d"
 }
 
+func defaultHeader() Header {
+	return Header{
+		Size:   3,
+		Color:  "#FFAA00",
+		Weight: "normal",
+		Levels: []int{1},
+		Style:  "image",
+		Figlet: "small",
+	}
+}
+
 func parseDate(value string) string {
 	pairs := [][]string{
 		{"YYYY", "2006"},
@@ -13,6 +13,7 @@ import (
 func TestMeta_ParseHeader(t *testing.T) {
 	user, _ := user.Current()
 	date := time.Now().Format("2006-01-02")
+	header := meta.Header{Size: 3, Color: "#FFAA00", Weight: "normal", Levels: []int{1}, Style: "image", Figlet: "small"}
 
 	tests := []struct {
 		name      string
@@ -27,6 +28,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -37,6 +39,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -47,6 +50,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: "gopher",
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -57,6 +61,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -67,6 +72,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   "31/01/1970",
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -77,6 +83,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   time.Now().Format("Jan 2, 2006"),
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -87,6 +94,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   time.Now().Format("2006-01-02"),
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -97,6 +105,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   time.Now().Format("2/1/06"),
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -107,6 +116,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   time.Now().Format("Jan 2, 2006"),
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -117,6 +127,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   time.Now().Format("January 02, 2006"),
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -127,6 +138,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 		{
@@ -137,6 +149,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "%d of %d",
+				Header: header,
 			},
 		},
 		{
@@ -147,6 +160,30 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
+			},
+		},
+		{
+			name:      "Parse transition from header",
+			slideshow: fmt.Sprintf("---\ntransition: %q\n", "fade"),
+			want: &meta.Meta{
+				Theme:      "default",
+				Author:     user.Name,
+				Date:       date,
+				Paging:     "Slide %d / %d",
+				Header:     header,
+				Transition: "fade",
+			},
+		},
+		{
+			name:      "Parse header from header",
+			slideshow: "---\nheader:\n  font: Inter.ttf\n  weight: bold\n  levels: [1, 2]\n  style: figlet\n  gradient: [\"#f00\", \"#00f\"]\n",
+			want: &meta.Meta{
+				Theme:  "default",
+				Author: user.Name,
+				Date:   date,
+				Paging: "Slide %d / %d",
+				Header: meta.Header{Font: "Inter.ttf", Size: 3, Color: "#FFAA00", Weight: "bold", Levels: []int{1, 2}, Style: "figlet", Figlet: "small", Gradient: []string{"#f00", "#00f"}},
 			},
 		},
 		{
@@ -157,6 +194,7 @@ func TestMeta_ParseHeader(t *testing.T) {
 				Author: user.Name,
 				Date:   date,
 				Paging: "Slide %d / %d",
+				Header: header,
 			},
 		},
 	}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/term"
+)
+
+const (
+	// animationFrame is the shortest time between two frames of animated
+	// images on terminals which are sent every frame. Over SSH every frame
+	// is sent through the network, so fewer of them are drawn.
+	animationFrame       = 66 * time.Millisecond
+	remoteAnimationFrame = 250 * time.Millisecond
+)
+
+type animationMsg struct {
+	id int
+}
+
+// animated returns whether the current slide has animated images.
+func (m Model) animated() bool {
+	for _, img := range m.slideImages() {
+		if len(img.Animation.Frames) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// startAnimations begins playing the animated images of the current slide on
+// terminals which are sent every frame, Kitty plays them by itself.
+func (m *Model) startAnimations() tea.Cmd {
+	if m.TerminalProtocol != term.Iterm || !m.animated() {
+		return nil
+	}
+	m.animationID++
+	m.animationStart = time.Now()
+	m.animationTime = 0
+	return m.animationTick()
+}
+
+// animationTick schedules the next frame until every animated image on the
+// slide has played all of its loops.
+func (m Model) animationTick() tea.Cmd {
+	playing := false
+	for _, img := range m.slideImages() {
+		a := img.Animation
+		if len(a.Frames) > 1 && (a.Loops == 0 || m.animationTime < a.Duration()*time.Duration(a.Loops)) {
+			playing = true
+		}
+	}
+	if !playing {
+		return nil
+	}
+
+	frame := animationFrame
+	if m.Remote {
+		frame = remoteAnimationFrame
+	}
+	id := m.animationID
+	return tea.Tick(frame, func(time.Time) tea.Msg {
+		return animationMsg{id: id}
+	})
+}
+
+// stopAnimations stops the animated images of the current slide on
+// terminals which are sent every frame, hideImages stops them in Kitty.
+func (m *Model) stopAnimations() {
+	m.animationID++
+	m.animationTime = 0
+}
@@ -0,0 +1,333 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/styles"
+)
+
+// Code blocks that need decorating after glamour has rendered the slide (the
+// focused block, blocks with highlighted lines, diffs and blocks with output)
+// are surrounded by marker paragraphs
+// in the markdown. The rendered marker lines tell us where each block starts
+// and ends and are removed again in decorateBlocks.
+var markerRegexp = regexp.MustCompile(`^slidesblock(begin|end)(\d+)$`)
+
+func beginMarker(i int) string {
+	return fmt.Sprintf("slidesblockbegin%d", i)
+}
+
+func endMarker(i int) string {
+	return fmt.Sprintf("slidesblockend%d", i)
+}
+
+// focusedBlock returns the index of the focused code block on the current
+// slide and whether any block has focus at all.
+func (m Model) focusedBlock() (int, bool) {
+	return m.focus - 1, m.focus > 0
+}
+
+// cycleFocus moves the focus delta blocks forward (or backward) through the
+// code blocks on the current slide, wrapping around at either end.
+func (m *Model) cycleFocus(delta int) {
+	blocks, err := code.Parse(m.Slides[m.Page].Content)
+	if err != nil {
+		return
+	}
+
+	n := len(blocks)
+	i, ok := m.focusedBlock()
+	if !ok {
+		if delta > 0 {
+			i = -1
+		} else {
+			i = n
+		}
+	}
+	m.focus = ((i+delta)%n+n)%n + 1
+}
+
+// isElementLanguage returns whether code blocks of the language are replaced
+// by an element of the slide, such as a terminal pane or a diagram, rather
+// than shown.
+func isElementLanguage(language string) bool {
+	switch language {
+	case terminalLanguage, castLanguage, graphLanguage, chartLanguage, mathLanguage:
+		return true
+	}
+	return isTableLanguage(language)
+}
+
+// setOutput stores the output of the code block at index i, it is rendered
+// directly below that block.
+func (m *Model) setOutput(i int, out string) {
+	if m.outputs == nil {
+		m.outputs = make(map[int]string)
+	}
+	// Leading spaces are kept, they can be part of a drawing.
+	m.outputs[i] = strings.TrimRight(strings.TrimLeft(out, "\r\n"), " \t\r\n")
+}
+
+// annotateBlocks replaces the code of blocks which are being typed out with
+// the part typed so far, and the code of diff blocks with the lines of the
+// diff. It surrounds the blocks which need decorating with markers.
+func (m Model) annotateBlocks(content string, blocks []code.Block) string {
+	focused, hasFocus := m.focusedBlock()
+
+	// Work backwards so that the offsets of earlier blocks remain valid.
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		start := strings.LastIndex(content[:b.Start], "\n") + 1
+		if isElementLanguage(b.Language) {
+			// The block itself is replaced by the terminal pane or recording.
+			content = content[:start] + "\n" + beginMarker(i) + "\n\n" + endMarker(i) + "\n\n" + content[b.End:]
+			continue
+		}
+
+		if t, ok := m.typewriters[i]; ok && !t.done() {
+			revealed := t.reveal(content[b.Start:b.End])
+			content = content[:b.Start] + revealed + content[b.End:]
+			b.End = b.Start + len(revealed)
+		}
+
+		lines, isDiff := m.diffView(blocks, i)
+		if isDiff {
+			body := diffBody(content[b.Start:b.End], lines)
+			content = content[:b.Start] + body + content[b.End:]
+			b.End = b.Start + len(body)
+		}
+
+		_, hasOutput := m.outputs[i]
+		if !m.tagBlocks && !hasOutput && (!hasFocus || focused != i) && b.Highlights() == nil && !isDiff {
+			continue
+		}
+
+		content = content[:b.End] + "\n\n" + endMarker(i) + "\n\n" + content[b.End:]
+		content = content[:start] + "\n" + beginMarker(i) + "\n\n" + content[start:]
+	}
+
+	return content
+}
+
+// decorateBlocks removes the markers inserted by annotateBlocks from the
+// rendered slide, dims the lines which are not highlighted, draws a gutter
+// next to the focused block and places the output of executed blocks below
+// them.
+func (m Model) decorateBlocks(rendered string, blocks []code.Block) string {
+	lines := strings.Split(rendered, "\n")
+	focused, hasFocus := m.focusedBlock()
+
+	var (
+		out       []string
+		region    []string
+		inBlock   bool
+		skipBlank bool
+	)
+
+	for _, line := range lines {
+		plain := strings.TrimSpace(ansi.Strip(line))
+		if skipBlank {
+			skipBlank = false
+			if plain == "" {
+				continue
+			}
+		}
+
+		match := markerRegexp.FindStringSubmatch(plain)
+		if match == nil {
+			if inBlock {
+				region = append(region, line)
+			} else {
+				out = append(out, line)
+			}
+			continue
+		}
+
+		i, _ := strconv.Atoi(match[2])
+		if match[1] == "begin" {
+			inBlock = true
+			region = nil
+			skipBlank = true
+			continue
+		}
+
+		if highlight := m.highlight(blocks, i); highlight != nil {
+			region = dim(region, highlight)
+		}
+		if lines, ok := m.diffView(blocks, i); ok {
+			region = diffGutter(region, lines)
+		}
+		if hasFocus && i == focused {
+			region = gutter(region)
+		}
+		out = append(out, m.tagLines(region, i)...)
+		inBlock = false
+		region = nil
+
+		if output := m.blockOutput(i); output != "" {
+			out = append(out, m.tagLines(strings.Split(indent(output, "  "), "\n"), i)...)
+		} else {
+			skipBlank = true
+		}
+	}
+
+	// An unterminated block can only happen if glamour dropped the end
+	// marker, keep its lines rather than losing them.
+	out = append(out, region...)
+
+	return strings.Join(out, "\n")
+}
+
+// tagLines tags the lines of the code block at index i, or of its output,
+// while the slide is rendered for the mouse.
+func (m Model) tagLines(lines []string, i int) []string {
+	if !m.tagBlocks {
+		return lines
+	}
+	tagged := make([]string, len(lines))
+	for n, line := range lines {
+		tagged[n] = blockTag(i) + line
+	}
+	return tagged
+}
+
+// blockSteps returns the number of states the block at index i steps
+// through: its groups of highlighted lines or the pages of its table.
+func (m Model) blockSteps(i int, b code.Block) int {
+	if pages, ok := m.tables[i]; ok {
+		return len(pages)
+	}
+	return len(b.Highlights())
+}
+
+// steps returns the number of steps on the current slide. The first step
+// shows every block in its first state, each further step moves one block on
+// to its next group of highlighted lines or page.
+func (m Model) steps() int {
+	blocks, _ := code.Parse(m.Slides[m.Page].Content)
+	steps := 1
+	for i, b := range blocks {
+		steps += max(m.blockSteps(i, b)-1, 0)
+	}
+	return steps
+}
+
+// blockStep returns the state of the block at index i at the current step.
+// Blocks stay in their first state until the step reaches them and in their
+// last state once the step has moved past them.
+func (m Model) blockStep(blocks []code.Block, i int) int {
+	offset := 0
+	for j, b := range blocks[:i] {
+		offset += max(m.blockSteps(j, b)-1, 0)
+	}
+	return min(max(m.step-offset, 0), max(m.blockSteps(i, blocks[i])-1, 0))
+}
+
+// highlight returns the lines of the block at index i which are highlighted
+// at the current step.
+func (m Model) highlight(blocks []code.Block, i int) []code.LineRange {
+	if i >= len(blocks) {
+		return nil
+	}
+	groups := blocks[i].Highlights()
+	if groups == nil {
+		return nil
+	}
+	return groups[m.blockStep(blocks, i)]
+}
+
+// dim fades the lines of a code block which are not highlighted. The lines of
+// the rendered block line up with the lines of the code.
+func dim(lines []string, highlight []code.LineRange) []string {
+	for i, line := range lines {
+		highlighted := false
+		for _, r := range highlight {
+			if r.Contains(i + 1) {
+				highlighted = true
+				break
+			}
+		}
+		if !highlighted {
+			lines[i] = styles.Dim.Render(ansi.Strip(line))
+		}
+	}
+	return lines
+}
+
+// gutter marks the non-blank span of lines with a bar in the left margin.
+func gutter(lines []string) []string {
+	first, last := -1, -1
+	for i, line := range lines {
+		if strings.TrimSpace(ansi.Strip(line)) != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	bar := styles.Focus.Render("▌")
+	for i := first; i >= 0 && i <= last; i++ {
+		lines[i] = replaceSpace(lines[i], 0, bar)
+	}
+	return lines
+}
+
+// diffGutter marks the inserted and deleted lines of a diff next to the code
+// and dims the deleted lines. The lines of the rendered block line up with
+// the lines of the diff.
+func diffGutter(region []string, lines []code.DiffLine) []string {
+	for i := 0; i < len(region) && i < len(lines); i++ {
+		switch lines[i].Op {
+		case code.DiffInsert:
+			region[i] = replaceSpace(region[i], 2, styles.DiffInsert.Render("+"))
+		case code.DiffDelete:
+			region[i] = styles.Dim.Render(ansi.Strip(region[i]))
+			region[i] = replaceSpace(region[i], 2, styles.DiffDelete.Render("-"))
+		}
+	}
+	return region
+}
+
+// replaceSpace replaces the character in column col of line with repl if
+// the line is indented by spaces up to that column, skipping over escape
+// sequences. Otherwise the line is returned unchanged.
+func replaceSpace(line string, col int, repl string) string {
+	for i := 0; i < len(line); {
+		if line[i] != '\x1b' {
+			if line[i] != ' ' {
+				break
+			}
+			if col == 0 {
+				return line[:i] + repl + line[i+1:]
+			}
+			col--
+			i++
+			continue
+		}
+
+		// Skip a CSI sequence: ESC [ parameters final-byte
+		i++
+		if i < len(line) && line[i] == '[' {
+			i++
+			for i < len(line) && (line[i] < 0x40 || line[i] > 0x7e) {
+				i++
+			}
+		}
+		i++
+	}
+	return line
+}
+
+func indent(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,238 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/internal/navigation"
+	"github.com/maaslalani/slides/internal/slides"
+)
+
+func TestSteps(t *testing.T) {
+	tt := []struct {
+		desc    string
+		content string
+		tables  map[int][]string
+		steps   int
+		// blockSteps holds the state of every block at each step.
+		blockSteps [][]int
+	}{
+		{
+			desc:       "no blocks",
+			content:    "# Title",
+			steps:      1,
+			blockSteps: [][]int{{}},
+		},
+		{
+			desc:       "block without highlights",
+			content:    "~~~go\na\n~~~",
+			steps:      1,
+			blockSteps: [][]int{{0}},
+		},
+		{
+			desc:       "one block",
+			content:    "~~~go {1|2|3}\na\nb\nc\n~~~",
+			steps:      3,
+			blockSteps: [][]int{{0}, {1}, {2}},
+		},
+		{
+			desc:       "blocks step one after the other",
+			content:    "~~~go {1|2}\na\nb\n~~~\n\n~~~go\nc\n~~~\n\n~~~go {1|2|1-2}\nd\ne\n~~~",
+			steps:      4,
+			blockSteps: [][]int{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {1, 0, 2}},
+		},
+		{
+			desc:       "table pages",
+			content:    "~~~table\na,b\n~~~\n\n~~~go {1|2}\na\nb\n~~~",
+			tables:     map[int][]string{0: {"page 1", "page 2", "page 3"}},
+			steps:      4,
+			blockSteps: [][]int{{0, 0}, {1, 0}, {2, 0}, {2, 1}},
+		},
+	}
+
+	for _, tc := range tt {
+		m := Model{Slides: []slides.Slide{{Content: tc.content}}, tables: tc.tables}
+		if got := m.steps(); got != tc.steps {
+			t.Errorf("%s: expected %d steps, got %d", tc.desc, tc.steps, got)
+		}
+		// Slides without code blocks fail to parse, they have no blocks.
+		blocks, _ := code.Parse(tc.content)
+		for step, want := range tc.blockSteps {
+			m.step = step
+			for i := range blocks {
+				if got := m.blockStep(blocks, i); got != want[i] {
+					t.Errorf("%s: expected block %d to be in state %d at step %d, got %d", tc.desc, i, want[i], step, got)
+				}
+			}
+		}
+	}
+}
+
+func TestPreviousLastStep(t *testing.T) {
+	m := newTestModel(t, "---\nauthor: me\n---\n~~~csv {rows=1}\na\nb\nc\nd\n~~~\n\n~~~go {1|2}\na\nb\n~~~\n\n---\n\n# Next")
+	m.navigate(navigation.State{Page: 1, TotalSlides: 2})
+
+	// The first slide has three pages of its table and two groups of
+	// highlighted lines.
+	m.navigate(navigation.Previous(m.navigationState()))
+	updated, _ := m.Update(autoExecuteCodeMsg{})
+	m = updated.(Model)
+	if m.Page != 0 || m.step != 3 {
+		t.Fatalf("expected the last step of the first slide, got slide %d step %d", m.Page, m.step)
+	}
+
+	m.navigate(navigation.Previous(m.navigationState()))
+	if m.Page != 0 || m.step != 2 {
+		t.Errorf("expected step 2 of the first slide, got slide %d step %d", m.Page, m.step)
+	}
+
+	// Going back with a count goes to the first step.
+	m.navigate(navigation.State{Page: 1, TotalSlides: 2})
+	m.buffer = "1"
+	m.navigate(navigation.Previous(m.navigationState()))
+	updated, _ = m.Update(autoExecuteCodeMsg{})
+	m = updated.(Model)
+	if m.Page != 0 || m.step != 0 {
+		t.Errorf("expected the first step of the first slide, got slide %d step %d", m.Page, m.step)
+	}
+}
+
+func TestAnnotateBlocks(t *testing.T) {
+	content := "Intro\n\n~~~go\nfmt.Println(1)\n~~~\n\nOutro"
+	marked := "Intro\n\n\nslidesblockbegin0\n\n~~~go\nfmt.Println(1)\n~~~\n\n\nslidesblockend0\n\n\nOutro"
+
+	tt := []struct {
+		desc     string
+		content  string
+		model    Model
+		expected string
+	}{
+		{
+			desc:     "undecorated block",
+			content:  content,
+			expected: content,
+		},
+		{
+			desc:     "focused block",
+			content:  content,
+			model:    Model{focus: 1},
+			expected: marked,
+		},
+		{
+			desc:     "block with output",
+			content:  content,
+			model:    Model{outputs: map[int]string{0: "1"}},
+			expected: marked,
+		},
+		{
+			desc:     "tagged block",
+			content:  content,
+			model:    Model{tagBlocks: true},
+			expected: marked,
+		},
+		{
+			desc:     "highlighted block",
+			content:  "~~~go {1}\na\n~~~",
+			expected: "\nslidesblockbegin0\n\n~~~go {1}\na\n~~~\n\nslidesblockend0\n\n",
+		},
+		{
+			desc:     "typed block",
+			content:  "~~~go\nabc\ndef\n~~~",
+			model:    Model{typewriters: map[int]typewriter{0: {shown: 2, total: 7}}},
+			expected: "~~~go\nab\n\n~~~",
+		},
+		{
+			desc:     "diff block",
+			content:  "~~~go\na\nb\n~~~\n\n~~~go {diff}\na\nc\n~~~",
+			expected: "~~~go\na\nb\n~~~\n\n\nslidesblockbegin1\n\n~~~go {diff}\na\nb\nc\n~~~\n\nslidesblockend1\n\n",
+		},
+		{
+			desc:     "element block is replaced",
+			content:  "Intro\n\n~~~terminal\nbash\n~~~\n\nOutro",
+			expected: "Intro\n\n\nslidesblockbegin0\n\nslidesblockend0\n\n\nOutro",
+		},
+	}
+
+	for _, tc := range tt {
+		blocks, err := code.Parse(tc.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tc.model.Slides = []slides.Slide{{Content: tc.content}}
+		if got := tc.model.annotateBlocks(tc.content, blocks); got != tc.expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", tc.desc, tc.expected, got)
+		}
+	}
+}
+
+func TestDecorateBlocks(t *testing.T) {
+	content := "Intro\n\n~~~go\nfmt.Println(1)\n~~~\n\nOutro\n"
+
+	tt := []struct {
+		desc   string
+		focus  int
+		output string
+		// gutter is set if the line of the code has the focus gutter.
+		gutter bool
+	}{
+		{desc: "undecorated"},
+		{desc: "focused", focus: 1, gutter: true},
+		{desc: "output", output: "printed"},
+		{desc: "focused with output", focus: 1, output: "printed", gutter: true},
+	}
+
+	for _, tc := range tt {
+		m := newTestModel(t, content)
+		m.focus = tc.focus
+		if tc.output != "" {
+			m.setOutput(0, tc.output)
+		}
+		slide, _ := m.GetSlide()
+		lines := plainLines(slide)
+
+		if strings.Contains(slide, "slidesblock") {
+			t.Errorf("%s: expected the markers to be removed:\n%s", tc.desc, slide)
+		}
+
+		codeLine := lineIndex(lines, "fmt.Println(1)")
+		intro, outro := lineIndex(lines, "Intro"), lineIndex(lines, "Outro")
+		if intro < 0 || codeLine <= intro || outro <= codeLine {
+			t.Fatalf("%s: expected intro, code and outro in order:\n%s", tc.desc, strings.Join(lines, "\n"))
+		}
+
+		if got := strings.Contains(lines[codeLine], "▌"); got != tc.gutter {
+			t.Errorf("%s: expected gutter %t on %q", tc.desc, tc.gutter, lines[codeLine])
+		}
+
+		if tc.output != "" {
+			out := lineIndex(lines, tc.output)
+			if out <= codeLine || out >= outro {
+				t.Errorf("%s: expected the output between the code and the outro:\n%s", tc.desc, strings.Join(lines, "\n"))
+			}
+		}
+	}
+}
+
+func TestReplaceSpace(t *testing.T) {
+	tt := []struct {
+		line     string
+		col      int
+		expected string
+	}{
+		{"   code", 0, "|  code"},
+		{"   code", 2, "  |code"},
+		{"  code", 2, "  code"},
+		{"code", 0, "code"},
+		{"", 0, ""},
+		{"\x1b[38;5;252m \x1b[0m code", 0, "\x1b[38;5;252m|\x1b[0m code"},
+		{"\x1b[38;5;252m \x1b[0m code", 1, "\x1b[38;5;252m \x1b[0m|code"},
+		{"\x1b[38;5;252m \x1b[0mcode", 1, "\x1b[38;5;252m \x1b[0mcode"},
+	}
+
+	for _, tc := range tt {
+		if got := replaceSpace(tc.line, tc.col, "|"); got != tc.expected {
+			t.Errorf("replaceSpace(%q, %d): expected %q, got %q", tc.line, tc.col, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/cast"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/styles"
+)
+
+// castLanguage is the language of code blocks which are replaced by the
+// playback of an asciinema recording. The code of the block is the path to the
+// recording, the autoplay option starts playing it when the slide is shown.
+//
+//	```cast {autoplay speed=2}
+//	demo.cast
+//	```
+const castLanguage = "cast"
+
+// castSeek is how far the recording is moved by a single seek.
+const castSeek = 5 * time.Second
+
+const castFrame = 50 * time.Millisecond
+
+type castMsg struct {
+	id int
+}
+
+// loadCasts loads the recordings of the cast blocks on the current slide and
+// starts playing those with the autoplay option.
+func (m *Model) loadCasts() tea.Cmd {
+	if m.casts != nil {
+		return nil
+	}
+	blocks, err := code.Parse(m.Slides[m.Page].Content)
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	for i, block := range blocks {
+		if block.Language != castLanguage {
+			continue
+		}
+		c, err := cast.Open(m.resolvePath(strings.TrimSpace(block.Code)))
+		if err != nil {
+			m.setOutput(i, "Error: could not load recording: "+err.Error())
+			continue
+		}
+
+		p := cast.NewPlayer(c)
+		if value, ok := block.Option("speed"); ok {
+			if speed, err := strconv.ParseFloat(value, 64); err == nil {
+				p.SetSpeed(speed)
+			}
+		}
+		if _, ok := block.Option("autoplay"); ok {
+			p.Play(now)
+		}
+		if m.casts == nil {
+			m.casts = make(map[int]*cast.Player)
+		}
+		m.casts[i] = p
+	}
+
+	m.castID++
+	return m.castTick()
+}
+
+// activeCast returns the recording which is controlled by the playback keys:
+// the focused cast block, or the first one on the slide.
+func (m Model) activeCast() (*cast.Player, bool) {
+	if i, ok := m.focusedBlock(); ok {
+		p, ok := m.casts[i]
+		return p, ok
+	}
+	first := -1
+	for i := range m.casts {
+		if first < 0 || i < first {
+			first = i
+		}
+	}
+	p, ok := m.casts[first]
+	return p, ok
+}
+
+// castKey handles the playback keys, it returns false for any other key or
+// when there is no recording on the slide.
+func (m *Model) castKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	p, ok := m.activeCast()
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Play):
+		p.Toggle(time.Now())
+	case key.Matches(msg, m.keys.SeekBackward):
+		p.Seek(p.Position() - castSeek)
+	case key.Matches(msg, m.keys.SeekForward):
+		p.Seek(p.Position() + castSeek)
+	case key.Matches(msg, m.keys.Slower):
+		p.Slower()
+	case key.Matches(msg, m.keys.Faster):
+		p.Faster()
+	default:
+		return nil, false
+	}
+
+	m.castID++
+	return m.castTick(), true
+}
+
+// castTick schedules the next frame while any recording on the slide is
+// playing.
+func (m Model) castTick() tea.Cmd {
+	playing := false
+	for _, p := range m.casts {
+		playing = playing || p.Playing()
+	}
+	if !playing {
+		return nil
+	}
+	id := m.castID
+	return tea.Tick(castFrame, func(time.Time) tea.Msg {
+		return castMsg{id: id}
+	})
+}
+
+// castView renders a recording in a frame with the state of the playback
+// below it. Recordings wider than the slide are cut off on the right.
+func (m Model) castView(p *cast.Player) string {
+	width, _ := m.paneSize(code.Block{})
+	lines := strings.Split(p.Screen(), "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "")
+	}
+	return styles.Pane.Render(strings.Join(lines, "\n")) + "\n" + styles.Dim.Render(" "+p.Status())
+}
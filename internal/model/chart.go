@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/maaslalani/slides/internal/chart"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/styles"
+)
+
+// renderChart renders the chart of the block at index i in the colors of the
+// theme of the presentation.
+func (m *Model) renderChart(i int, block code.Block, availableCells int) {
+	kind, _ := block.Option("type")
+	_, header := block.Option("header")
+	out, err := chart.Render(block.Code, chart.Options{
+		Type:   kind,
+		Header: header,
+		Width:  styles.SlideWidth(m.viewport.Width),
+		Height: availableCells,
+		Colors: m.themeColors,
+	})
+	if err != nil {
+		m.setOutput(i, "Error: "+err.Error())
+		return
+	}
+	m.setOutput(i, out)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/term"
+)
+
+const statusTimeout = 2 * time.Second
+
+// statusMsg shows a transient message in the status line.
+type statusMsg string
+
+// clearStatusMsg removes the status message if it is still being displayed.
+type clearStatusMsg string
+
+func clearStatusCmd(status string) tea.Cmd {
+	return tea.Tick(statusTimeout, func(time.Time) tea.Msg {
+		return clearStatusMsg(status)
+	})
+}
+
+// copyCmd places text on the clipboard of the person viewing the
+// presentation.
+//
+// The system clipboard is used when the presentation runs locally, with the
+// OSC 52 escape sequence as a fallback for headless machines and SSH
+// sessions. When the presentation is served with `slides serve` the system
+// clipboard belongs to the server, so OSC 52 is the only option.
+func (m Model) copyCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		if !m.Remote && !clipboard.Unsupported && os.Getenv("SSH_TTY") == "" {
+			if err := clipboard.WriteAll(text); err == nil {
+				return statusMsg("Copied to clipboard")
+			}
+		}
+
+		if err := m.writeOSC52(text); err != nil {
+			return statusMsg("Could not copy to clipboard")
+		}
+		return statusMsg("Copied to clipboard (OSC 52)")
+	}
+}
+
+// writeOSC52 asks the terminal displaying the presentation to set its
+// clipboard. Terminal multiplexers need the sequence wrapped in a
+// passthrough so that it reaches the outer terminal.
+func (m Model) writeOSC52(text string) error {
+	out := m.output()
+	seq := osc52.New(text)
+	if !m.Remote {
+		if os.Getenv("TMUX") != "" {
+			seq = seq.Tmux()
+		} else if term.IsTmuxScreen() {
+			seq = seq.Screen()
+		}
+	}
+
+	// The sequence is written at once, so that it is not interleaved with
+	// the frames of the renderer.
+	_, err := io.WriteString(out, seq.String())
+	return err
+}
+
+// output returns the terminal the presentation is displayed on.
+func (m Model) output() io.Writer {
+	if m.Output == nil {
+		return os.Stdout
+	}
+	return m.Output
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/code"
+)
+
+const morphDelay = 120 * time.Millisecond
+
+// morphMsg advances the magic move animation on the current slide. Ticks from
+// an earlier animation are told apart by their id and ignored.
+type morphMsg struct {
+	id int
+}
+
+// diffMode returns whether the block is compared with its previous version,
+// {diff}, and whether the changes are animated instead, {diff=move}.
+func diffMode(block code.Block) (bool, bool) {
+	mode, ok := block.Option("diff")
+	return ok, mode == "move"
+}
+
+// diffBase returns the displayed code of the previous version of the block at
+// index i: the closest earlier block with the same language on the same slide,
+// or else the last such block on the previous slide.
+func (m Model) diffBase(blocks []code.Block, i int) (string, bool) {
+	content := m.Slides[m.Page].Content
+	for j := i - 1; j >= 0; j-- {
+		if blocks[j].Language == blocks[i].Language {
+			_, body, _, ok := splitBlock(content[blocks[j].Start:blocks[j].End])
+			return body, ok
+		}
+	}
+
+	if m.Page == 0 {
+		return "", false
+	}
+	content = m.Slides[m.Page-1].Content
+	previous, _ := code.Parse(content)
+	for j := len(previous) - 1; j >= 0; j-- {
+		if previous[j].Language == blocks[i].Language {
+			_, body, _, ok := splitBlock(content[previous[j].Start:previous[j].End])
+			return body, ok
+		}
+	}
+	return "", false
+}
+
+// diff returns the diff of the block at index i against its previous version.
+func (m Model) diff(blocks []code.Block, i int) ([]code.DiffLine, bool) {
+	if i >= len(blocks) {
+		return nil, false
+	}
+	if ok, _ := diffMode(blocks[i]); !ok {
+		return nil, false
+	}
+	old, ok := m.diffBase(blocks, i)
+	if !ok {
+		return nil, false
+	}
+	content := m.Slides[m.Page].Content
+	_, body, _, ok := splitBlock(content[blocks[i].Start:blocks[i].End])
+	if !ok {
+		return nil, false
+	}
+	return code.Diff(old, body), true
+}
+
+// diffView returns the lines of the block at index i as they are currently
+// displayed. A {diff} block shows the deleted lines alongside the new code. A
+// {diff=move} block starts out as the previous version, then removes the
+// deleted lines and inserts the new ones a frame at a time, and is displayed
+// like any other block once the animation is over.
+func (m Model) diffView(blocks []code.Block, i int) ([]code.DiffLine, bool) {
+	lines, ok := m.diff(blocks, i)
+	if !ok {
+		return nil, false
+	}
+	if _, move := diffMode(blocks[i]); !move {
+		return lines, true
+	}
+	if m.morphFrame >= morphFrames(lines) {
+		return nil, false
+	}
+
+	var (
+		view              []code.DiffLine
+		deleted, inserted int
+	)
+	deletions := countOp(lines, code.DiffDelete)
+	for _, line := range lines {
+		switch line.Op {
+		case code.DiffDelete:
+			if deleted >= m.morphFrame {
+				view = append(view, line)
+			}
+			deleted++
+		case code.DiffInsert:
+			if inserted < m.morphFrame-deletions {
+				view = append(view, line)
+			}
+			inserted++
+		default:
+			view = append(view, line)
+		}
+	}
+	return view, true
+}
+
+// diffBody returns the markdown of the code block with its code replaced by
+// the lines of the diff.
+func diffBody(markdown string, lines []code.DiffLine) string {
+	open, _, close, ok := splitBlock(markdown)
+	if !ok {
+		return markdown
+	}
+	text := make([]string, len(lines))
+	for i, line := range lines {
+		text[i] = line.Text
+	}
+	return open + "\n" + strings.Join(text, "\n") + close
+}
+
+func countOp(lines []code.DiffLine, op code.DiffOp) int {
+	var n int
+	for _, line := range lines {
+		if line.Op == op {
+			n++
+		}
+	}
+	return n
+}
+
+func morphFrames(lines []code.DiffLine) int {
+	return countOp(lines, code.DiffDelete) + countOp(lines, code.DiffInsert)
+}
+
+// startMorph begins the magic move animation of the current slide, unless it
+// has already been started.
+func (m *Model) startMorph() tea.Cmd {
+	if m.morphStarted || m.morphing() == 0 {
+		return nil
+	}
+	m.morphStarted = true
+	m.morphID++
+	return m.morphTick()
+}
+
+// morphing returns the number of frames the magic move animation of the
+// current slide lasts.
+func (m Model) morphing() int {
+	blocks, _ := code.Parse(m.Slides[m.Page].Content)
+	var frames int
+	for i, b := range blocks {
+		if _, move := diffMode(b); !move {
+			continue
+		}
+		if lines, ok := m.diff(blocks, i); ok {
+			frames = max(frames, morphFrames(lines))
+		}
+	}
+	return frames
+}
+
+func (m Model) morphTick() tea.Cmd {
+	if m.morphFrame >= m.morphing() {
+		return nil
+	}
+	id := m.morphID
+	return tea.Tick(morphDelay, func(time.Time) tea.Msg {
+		return morphMsg{id: id}
+	})
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/maaslalani/slides/internal/figlet"
+	"github.com/maaslalani/slides/internal/slides"
+	"github.com/maaslalani/slides/internal/term"
+	"github.com/maaslalani/slides/styles"
+)
+
+const defaultFigletFont = "small"
+
+var (
+	figletFontsMu sync.Mutex
+	figletFonts   = map[string]*figlet.Font{}
+)
+
+// loadFigletFont loads a bundled FIGlet font, or the font file at a path,
+// once.
+func loadFigletFont(name string) (*figlet.Font, error) {
+	figletFontsMu.Lock()
+	defer figletFontsMu.Unlock()
+	if f, ok := figletFonts[name]; ok {
+		return f, nil
+	}
+	f, err := figlet.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	figletFonts[name] = f
+	return f, nil
+}
+
+// figletHeader returns whether a header is drawn with a FIGlet font, which
+// it is on terminals which do not show images, or if the front matter asks
+// for it. Headers which are only an image show the image where they can.
+func (m *Model) figletHeader(header slides.Image) bool {
+	if m.TerminalProtocol != term.Kitty && m.TerminalProtocol != term.Iterm {
+		return true
+	}
+	return header.Path == "" && m.header.Style == "figlet"
+}
+
+// renderFiglet draws the text of a header with the FIGlet font of the
+// presentation, wrapping its words to the width of the slide.
+func (m *Model) renderFiglet(header slides.Image) slides.Image {
+	name := m.header.Figlet
+	if name == "" {
+		name = defaultFigletFont
+	} else if !slices.Contains(figlet.Fonts, name) {
+		name = m.resolvePath(name)
+	}
+	f, err := loadFigletFont(name)
+	if err != nil {
+		f, _ = loadFigletFont(defaultFigletFont)
+	}
+
+	width := max(styles.SlideWidth(m.viewport.Width), 1)
+	var lines []string
+	var words []string
+	flush := func() {
+		if len(words) > 0 {
+			lines = append(lines, f.Render(strings.Join(words, " "))...)
+		}
+		words = nil
+	}
+	for _, word := range strings.Fields(imageName(header)) {
+		if len(words) > 0 && lipgloss.Width(strings.Join(f.Render(strings.Join(append(words, word), " ")), "\n")) > width {
+			flush()
+		}
+		words = append(words, word)
+	}
+	flush()
+
+	header.Str = "\n" + indent(m.colorFiglet(lines), "  ") + "\n"
+	return header
+}
+
+// figletGradient returns the colors FIGlet headers fade through, those of
+// the gradient of the front matter or else the first two colors of the
+// theme. It is empty for themes without colors.
+func (m *Model) figletGradient() []string {
+	if len(m.header.Gradient) >= 2 {
+		return m.header.Gradient
+	}
+	if len(m.themeColors) >= 2 {
+		return m.themeColors[:2]
+	}
+	return nil
+}
+
+// colorFiglet fades the lines of a FIGlet header through the colors of the
+// gradient from left to right, or colors them with the color of headers if
+// there is no gradient.
+func (m *Model) colorFiglet(lines []string) string {
+	gradient := m.figletGradient()
+	if len(gradient) < 2 {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(m.header.Color))
+		for i, line := range lines {
+			lines[i] = style.Render(line)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	stops := make([]colorful.Color, len(gradient))
+	for i, c := range gradient {
+		stops[i], _ = colorful.MakeColor(headerColor(c))
+	}
+	width := 0
+	for _, line := range lines {
+		width = max(width, ansi.StringWidth(line))
+	}
+	colors := make([]lipgloss.Style, width)
+	for x := range colors {
+		t := float64(x) / float64(max(width-1, 1)) * float64(len(stops)-1)
+		i := min(int(t), len(stops)-2)
+		c := stops[i].BlendLab(stops[i+1], t-float64(i)).Clamped()
+		colors[x] = lipgloss.NewStyle().Foreground(lipgloss.Color(c.Hex()))
+	}
+
+	for i, line := range lines {
+		var b strings.Builder
+		for x, r := range []rune(line) {
+			if r == ' ' || x >= width {
+				b.WriteRune(r)
+				continue
+			}
+			b.WriteString(colors[x].Render(string(r)))
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/internal/meta"
+	"github.com/muesli/termenv"
+)
+
+func TestColorFiglet(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	const (
+		flat  = "38;2;255;170;0m"
+		theme = "38;2;0;0;255m"
+		front = "38;2;0;255;0m"
+	)
+	tt := []struct {
+		desc        string
+		gradient    []string
+		themeColors []string
+		expected    string
+	}{
+		{"gradient", []string{"#0f0", "#f00"}, []string{"#00f", "#f00"}, front},
+		{"theme colors", nil, []string{"#00f", "#f00", "#0f0"}, theme},
+		{"single color gradient", []string{"#0f0"}, []string{"#00f", "#f00"}, theme},
+		{"theme without colors", nil, nil, flat},
+	}
+
+	for _, tc := range tt {
+		m := Model{
+			header:      meta.Header{Color: "#FFAA00", Gradient: tc.gradient},
+			themeColors: tc.themeColors,
+		}
+		got := m.colorFiglet([]string{"ab", "cd"})
+		if !strings.HasPrefix(got, "\x1b["+tc.expected) {
+			t.Errorf("%s: expected the header to start in %q, got %q", tc.desc, tc.expected, got)
+		}
+	}
+}
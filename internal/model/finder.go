@@ -0,0 +1,99 @@
+package model
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/internal/slides"
+)
+
+// openFinder shows the finder for the text of the slides.
+func (m *Model) openFinder() tea.Cmd {
+	pages := make([][]string, len(m.Slides))
+	for page, slide := range m.Slides {
+		pages[page] = slideLines(slide)
+	}
+	m.stopAnimations()
+	m.hideImages()
+	return tea.Batch(tea.ClearScreen, m.Finder.Begin(pages))
+}
+
+// slideLines returns the lines of text of a slide which are searched, its
+// header and the lines of its content which are not empty or directives.
+func slideLines(slide slides.Slide) []string {
+	var lines []string
+	if slide.Header != nil && slide.Header.Alt != "" {
+		lines = append(lines, slide.Header.Alt)
+	}
+	for _, line := range strings.Split(slide.Content, "\n") {
+		line = strings.TrimSpace(strings.ReplaceAll(line, "\t", tabSpaces))
+		if line == "" || strings.HasPrefix(line, "<!--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// updateFinder handles the key presses while the finder is shown, enter
+// goes to the selected slide and highlights what was found on it.
+func (m *Model) updateFinder(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.Finder.Done()
+		result, ok := m.Finder.Selection()
+		if !ok {
+			return m.uncover()
+		}
+		cmd := m.uncover()
+		if result.Page != m.Page {
+			cmd = m.SetPage(result.Page)
+		}
+		m.found = m.Finder.Query()
+		return cmd
+	case tea.KeyCtrlC, tea.KeyEscape:
+		m.Finder.Done()
+		return m.uncover()
+	}
+	return m.Finder.Update(msg)
+}
+
+// finderView shows a preview of the selected slide above the finder, with
+// what was found highlighted.
+func (m Model) finderView() string {
+	finder := m.Finder.View(m.viewport.Width)
+	height := max(m.viewport.Height-lipgloss.Height(finder), 0)
+
+	page := m.Page
+	if result, ok := m.Finder.Selection(); ok {
+		page = result.Page
+	}
+	preview := m.preview(page)
+	preview.found = m.Finder.Query()
+	slide, _ := preview.GetSlide()
+	lines := strings.Split(slide, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lipgloss.PlaceVertical(height, lipgloss.Top, strings.Join(lines, "\n")) + "\n" + finder
+}
+
+// preview returns the model showing a slide as it looks before anything
+// was done on it, without images.
+func (m Model) preview(page int) Model {
+	m.Page = page
+	m.previewing = true
+	m.VirtualText = ""
+	m.scroll = 0
+	m.focus = 0
+	m.step = 0
+	m.outputs = nil
+	m.tables = nil
+	m.typewriters = nil
+	m.morphFrame = 0
+	m.morphStarted = false
+	m.pane = nil
+	m.casts = nil
+	return m
+}
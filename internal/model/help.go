@@ -0,0 +1,62 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/styles"
+)
+
+// openHelp shows the keys of the presentation instead of the current
+// slide.
+func (m *Model) openHelp() tea.Cmd {
+	m.help = true
+	m.pauseTypewriter()
+	m.stopAnimations()
+	m.hideImages()
+	return tea.ClearScreen
+}
+
+// updateHelp handles the key presses while the help is shown, which close
+// it.
+func (m *Model) updateHelp(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return tea.Quit
+	case key.Matches(msg, m.keys.Help), msg.Type == tea.KeyEscape, msg.String() == "q":
+		m.help = false
+		return m.uncover()
+	}
+	return nil
+}
+
+// helpView renders the keys of the presentation in columns, as many side by
+// side as fit the width of the terminal.
+func (m Model) helpView() string {
+	h := help.New()
+	h.FullSeparator = "    "
+	h.Styles.FullKey = styles.HelpKey
+	h.Styles.FullDesc = styles.HelpDesc
+	h.Styles.FullSeparator = styles.HelpDesc
+
+	columns := m.keys.FullHelp()
+	var keys string
+	for perRow := len(columns); perRow > 0; perRow-- {
+		var rows []string
+		for i := 0; i < len(columns); i += perRow {
+			rows = append(rows, h.FullHelpView(columns[i:min(i+perRow, len(columns))]))
+		}
+		keys = strings.Join(rows, "\n\n")
+		if lipgloss.Width(keys) <= m.viewport.Width-4 {
+			break
+		}
+	}
+
+	title := styles.HelpTitle.Render("Keys")
+	footer := styles.Search.Render("esc close")
+	body := lipgloss.NewStyle().Margin(1, 2).Render(lipgloss.JoinVertical(lipgloss.Left, title, "", keys))
+	return lipgloss.PlaceVertical(m.viewport.Height-1, lipgloss.Top, body) + "\n" + footer
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/navigation"
+	"github.com/maaslalani/slides/internal/transition"
+	"github.com/maaslalani/slides/styles"
+)
+
+// Matches of searches are shown in reverse video, which keeps the colors of
+// the text and is turned off again without resetting them.
+const (
+	highlightOn  = "\x1b[7m"
+	highlightOff = "\x1b[27m"
+)
+
+// highlightMatches highlights what was found on the rendered slide: every
+// occurrence of the query, ignoring case, or else the best fuzzy match of
+// the query on a line.
+func highlightMatches(rendered, query string) string {
+	if query == "" {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	plain := make([]string, len(lines))
+	found := false
+	for i, line := range lines {
+		plain[i] = ansi.Strip(line)
+		if ranges := occurrences([]rune(plain[i]), []rune(query)); ranges != nil {
+			lines[i] = highlightRanges(line, ranges)
+			found = true
+		}
+	}
+	if found {
+		return strings.Join(lines, "\n")
+	}
+
+	best := -1
+	var match navigation.Match
+	for i := range lines {
+		if m, ok := navigation.FuzzyMatch(query, plain[i]); ok && (best < 0 || m.Score > match.Score) {
+			best, match = i, m
+		}
+	}
+	if best < 0 {
+		return rendered
+	}
+	var ranges [][2]int
+	for _, p := range match.Positions {
+		if n := len(ranges); n > 0 && ranges[n-1][1] == p {
+			ranges[n-1][1]++
+			continue
+		}
+		ranges = append(ranges, [2]int{p, p + 1})
+	}
+	lines[best] = highlightRanges(lines[best], ranges)
+	return strings.Join(lines, "\n")
+}
+
+// highlightSearch highlights the matches of the search on the rendered
+// slide while it is typed, and after it was executed until it is cleared.
+func (m Model) highlightSearch(rendered string) string {
+	if (!m.Search.Active && !m.Search.Found) || m.Search.Query() == "" {
+		return rendered
+	}
+	pattern, err := m.Search.Pattern()
+	if err != nil {
+		return rendered
+	}
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		plain := ansi.Strip(line)
+		var ranges [][2]int
+		for _, loc := range pattern.FindAllStringIndex(plain, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			start := utf8.RuneCountInString(plain[:loc[0]])
+			ranges = append(ranges, [2]int{start, start + utf8.RuneCountInString(plain[loc[0]:loc[1]])})
+		}
+		if ranges != nil {
+			lines[i] = highlightRanges(line, ranges)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// searchStatus returns the query of the search and which of the slides that
+// match is shown, such as 3/7, for the status line.
+func (m Model) searchStatus() string {
+	prompt := m.Search.SearchTextInput.Prompt
+	current, total := m.Search.Count(&m)
+	count := fmt.Sprintf("%d/%d", current, total)
+	if current == 0 {
+		count = fmt.Sprintf("-/%d", total)
+	}
+	return styles.Search.Render(prompt + m.Search.Query() + "  " + count)
+}
+
+// occurrences returns the ranges of runes of text where query occurs,
+// ignoring case.
+func occurrences(text, query []rune) [][2]int {
+	var ranges [][2]int
+	if len(query) == 0 {
+		return nil
+	}
+	for i := 0; i+len(query) <= len(text); i++ {
+		match := true
+		for j, r := range query {
+			if unicode.ToLower(text[i+j]) != unicode.ToLower(r) {
+				match = false
+				break
+			}
+		}
+		if match {
+			ranges = append(ranges, [2]int{i, i + len(query)})
+			i += len(query) - 1
+		}
+	}
+	return ranges
+}
+
+// highlightRanges highlights the ranges of runes of the text of a rendered
+// line, which are counted without its escape sequences. The highlight is
+// turned on again after every escape sequence in a range, as they may reset
+// it.
+func highlightRanges(line string, ranges [][2]int) string {
+	var b strings.Builder
+	n, r := 0, 0
+	on := false
+	for len(line) > 0 && r < len(ranges) {
+		if line[0] == '\x1b' {
+			seq, rest := transition.SplitEscape(line)
+			b.WriteString(seq)
+			if on {
+				b.WriteString(highlightOn)
+			}
+			line = rest
+			continue
+		}
+		if !on && n == ranges[r][0] {
+			b.WriteString(highlightOn)
+			on = true
+		}
+		_, size := utf8.DecodeRuneInString(line)
+		b.WriteString(line[:size])
+		line = line[size:]
+		n++
+		if on && n == ranges[r][1] {
+			b.WriteString(highlightOff)
+			on = false
+			r++
+		}
+	}
+	if on {
+		b.WriteString(highlightOff)
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSearchClearedOnReload(t *testing.T) {
+	m := newTestModel(t, "---\nauthor: me\n---\n# One\n\n---\n\n# Two\n")
+	m.Init()
+
+	m.Search.SetQuery("Two")
+	m.Search.Execute(&m)
+	if _, total := m.Search.Count(&m); !m.Search.Found || total != 1 {
+		t.Fatalf("expected the search to match one slide, got %d", total)
+	}
+
+	if err := os.WriteFile(m.FileName, []byte("---\nauthor: me\n---\n# Three\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(m.FileName, later, later); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(fileWatchMsg{})
+	m = updated.(Model)
+
+	if _, total := m.Search.Count(&m); m.Search.Found || total != 0 {
+		t.Errorf("expected the matches to be cleared on reload, got %d", total)
+	}
+}
@@ -0,0 +1,454 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/internal/slides"
+	"github.com/maaslalani/slides/internal/term"
+	"github.com/maaslalani/slides/styles"
+)
+
+// imageRegexp matches markdown images, optionally followed by options in
+// braces: ![alt](path "title"){width=40% float=left}
+var imageRegexp = regexp.MustCompile(`!\[([^\]\n]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"\n]*")?\s*\)(?:\{([^}\n]*)\})?`)
+
+// Images between the paragraphs of a slide are replaced by marker paragraphs
+// before rendering, like code blocks which need decorating.
+var imageMarkerRegexp = regexp.MustCompile(`^slidesimage(\d+)$`)
+
+func imageMarker(i int) string {
+	return fmt.Sprintf("slidesimage%d", i)
+}
+
+// imageMarginRows are the rows of the slide which are left to its text when
+// an image is fit into the slide.
+const imageMarginRows = 6
+
+// floatGap is the space between a floating image and the text of the slide.
+const floatGap = 2
+
+// outsideCode applies f to the parts of the markdown which are neither code
+// blocks nor inline code, in the order they appear in.
+func outsideCode(content string, f func(string) string) string {
+	blocks, _ := code.Parse(content)
+	var b strings.Builder
+	last := 0
+	replace := func(s string) {
+		prev := 0
+		for _, span := range inlineCodeRegexp.FindAllStringIndex(s, -1) {
+			b.WriteString(f(s[prev:span[0]]))
+			b.WriteString(s[span[0]:span[1]])
+			prev = span[1]
+		}
+		b.WriteString(f(s[prev:]))
+	}
+	for _, block := range blocks {
+		replace(content[last:block.Start])
+		b.WriteString(content[block.Start:block.End])
+		last = block.End
+	}
+	replace(content[last:])
+	return b.String()
+}
+
+// preprocessImages finds the images of a slide, they are loaded by
+// loadImages when they are about to be shown.
+func preprocessImages(content string) []slides.Image {
+	var images []slides.Image
+	outsideCode(content, func(s string) string {
+		for _, match := range imageRegexp.FindAllStringSubmatch(s, -1) {
+			images = append(images, parseImage(match))
+		}
+		return s
+	})
+	return images
+}
+
+// parseImage returns the image of an imageRegexp match with its options.
+func parseImage(match []string) slides.Image {
+	img := slides.Image{Alt: match[1], Path: match[2]}
+	for _, opt := range strings.Fields(match[3]) {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "width":
+			img.Width = value
+		case "height":
+			img.Height = value
+		case "float":
+			if value == "left" || value == "right" {
+				img.Float = value
+			}
+		}
+	}
+	return img
+}
+
+// loadImage reads and decodes an image, or draws the text of a header which
+// is not an image.
+func (m *Model) loadImage(img slides.Image) slides.Image {
+	var e *cachedImage
+	if img.Path == "" {
+		e = m.imageCache().header(img.Alt, m.headerStyle())
+	} else {
+		e = m.imageCache().file(m.resolvePath(img.Path))
+	}
+	img.ID, img.Image, img.Animation = e.id, e.image, e.animation
+	return img
+}
+
+// imageCache returns the cache of the images of the presentation.
+func (m *Model) imageCache() *imageCache {
+	if m.images == nil {
+		m.images = newImageCache()
+	}
+	return m.images
+}
+
+// resolvePath resolves a path in the presentation relative to the directory
+// of the presentation rather than the working directory.
+func (m *Model) resolvePath(path string) string {
+	if m.FileName == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(m.FileName), path)
+}
+
+// cellSize returns the number of cells a size of an image stands for, either
+// a number of cells or a percentage of total.
+func cellSize(size string, total int) (int, bool) {
+	if percent, ok := strings.CutSuffix(size, "%"); ok {
+		n, err := strconv.ParseFloat(percent, 64)
+		return int(n * float64(total) / 100), err == nil
+	}
+	n, err := strconv.Atoi(size)
+	return n, err == nil
+}
+
+// imageCells returns the columns and rows the image takes up. It keeps the
+// aspect ratio of the image and fits into its width and height, or else
+// into the slide.
+func (m *Model) imageCells(img slides.Image) (int, int) {
+	maxCols := max(styles.SlideWidth(m.viewport.Width), 1)
+	maxRows := max(m.viewport.Height-imageMarginRows, 1)
+
+	cols, rows := maxCols, maxRows
+	if img.Float != "" {
+		// Leave room for the text next to the image.
+		cols = maxCols / 2
+	}
+	if n, ok := cellSize(img.Width, maxCols); ok {
+		cols = min(n, maxCols)
+	}
+	if n, ok := cellSize(img.Height, maxRows); ok {
+		rows = min(n, maxRows)
+	}
+
+	// Terminal cells are about 2.2 times as tall as they are wide.
+	b := img.Image.Bounds()
+	ratio := 2.2 * float64(b.Dx()) / float64(max(b.Dy(), 1))
+	if float64(cols)/ratio > float64(rows) {
+		cols = int(float64(rows) * ratio)
+	} else {
+		rows = int(float64(cols) / ratio)
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// renderImage renders the nth image of a slide into a block of cells which
+// reserves the space the image takes up, so that it can be laid out like
+// text. Terminals which do not show images, and images which could not be
+// loaded, get the alt text instead. Kitty is sent the image once by
+// sendImages, the block only places it.
+func (m *Model) renderImage(img slides.Image, n int) slides.Image {
+	img.FrameStrs = nil
+	if img.Image == nil || (m.TerminalProtocol != term.Kitty && m.TerminalProtocol != term.Iterm) {
+		img.Str = styles.Dim.Render("[" + imageName(img) + "]")
+		img.Cols = ansi.StringWidth(img.Str)
+		return img
+	}
+
+	cols, rows := m.imageCells(img)
+	img.Cols = cols
+	switch m.TerminalProtocol {
+	case term.Kitty:
+		var buf bytes.Buffer
+		term.KittyPlace(&buf, term.KittyImgOpts{
+			ImageId:     img.ID,
+			PlacementId: imagePlacement(n),
+			DstCols:     uint32(cols),
+			DstRows:     uint32(rows),
+			CursorMove:  1,
+			Quiet:       2,
+		})
+		img.Str = imageBlock(buf.String(), cols, rows)
+	case term.Iterm:
+		enc := encoding{protocol: m.TerminalProtocol, cols: cols, rows: rows}
+		strs := m.imageCache().encode(img.ID, enc, func() []string {
+			frames := []image.Image{code.FitImage(img.Image, cols, rows)}
+			if len(img.Animation.Frames) > 1 {
+				frames = frames[:0]
+				for _, f := range img.Animation.Frames {
+					frames = append(frames, f.Image)
+				}
+			}
+			strs := make([]string, len(frames))
+			for i, frame := range frames {
+				var buf bytes.Buffer
+				writeItermImage(&buf, frame, cols, rows)
+				strs[i] = imageBlock(buf.String(), cols, rows)
+			}
+			return strs
+		})
+		img.Str = strs[0]
+		if len(strs) > 1 {
+			img.FrameStrs = strs
+		}
+	}
+	return img
+}
+
+// Placements of an image in Kitty are told apart by their ids, the header
+// of a slide has the first and the images of the slide the ones after it.
+const headerPlacement = 1
+
+func imagePlacement(n int) uint32 {
+	return uint32(n) + headerPlacement + 1
+}
+
+// renderHeader renders the header image of a slide. Kitty is sent the image
+// once by sendImages, the header only places it. Headers which could not be
+// drawn show their text instead.
+func (m *Model) renderHeader(img slides.Image) slides.Image {
+	rows := m.headerStyle().rows
+	switch {
+	case img.Image == nil:
+		img.Str = m.headerText(styles.Dim.Render("[" + imageName(img) + "]"))
+	case m.TerminalProtocol == term.Iterm && img.ID != 0:
+		enc := encoding{protocol: m.TerminalProtocol, header: true, cols: m.viewport.Width, rows: rows}
+		img.Str = m.imageCache().encode(img.ID, enc, func() []string {
+			return []string{code.RenderImage(img.Image, m.TerminalProtocol, rows, m.viewport.Width)}
+		})[0]
+	case m.TerminalProtocol == term.Kitty && img.ID != 0:
+		cols, rows, yPadding := code.ImageCells(img.Image, rows, m.viewport.Width)
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "\033[%dB", yPadding)
+		term.KittyPlace(&buf, term.KittyImgOpts{
+			ImageId:     img.ID,
+			PlacementId: headerPlacement,
+			DstCols:     uint32(cols),
+			DstRows:     uint32(rows),
+			Quiet:       2,
+		})
+		img.Str = buf.String()
+	default:
+		img.Str = code.RenderImage(img.Image, m.TerminalProtocol, rows, m.viewport.Width)
+	}
+	return img
+}
+
+// kittyTransmission returns the escape sequences which send an image and
+// the frames of its animation to Kitty. Images are fit to the cells they
+// take up, headers are sent as they are.
+func (m *Model) kittyTransmission(img slides.Image, header bool) string {
+	cols, rows := 0, 0
+	if !header {
+		cols, rows = m.imageCells(img)
+	}
+	enc := encoding{protocol: term.Kitty, header: header, cols: cols, rows: rows}
+	return m.imageCache().encode(img.ID, enc, func() []string {
+		var buf bytes.Buffer
+		fitted := img.Image
+		if !header {
+			fitted = code.FitImage(img.Image, cols, rows)
+		}
+		term.KittyTransmit(&buf, fitted, term.KittyImgOpts{ImageId: img.ID, Quiet: 2})
+		if len(img.Animation.Frames) > 1 {
+			for _, f := range img.Animation.Frames[1:] {
+				term.KittyWriteFrame(&buf, f.Image, img.ID, uint32(f.Delay.Milliseconds()))
+			}
+		}
+		return []string{buf.String()}
+	})[0]
+}
+
+// sendImages sends Kitty the loaded images of the current slide which it
+// does not have yet, and starts their animations, once the transition to
+// the slide is over and while it is not covered. Kitty keeps the images, so
+// that they are only placed again when the slide is drawn.
+func (m *Model) sendImages() {
+	if m.TerminalProtocol != term.Kitty || m.Page >= len(m.Slides) || m.transitioning() || m.covered() {
+		return
+	}
+	if m.kittySent == nil {
+		m.kittySent = map[uint32]bool{}
+	}
+
+	// The images are written at once to the output, which the renderer
+	// shares through term.Synchronized, so that they are not interleaved with
+	// its frames.
+	var buf bytes.Buffer
+	send := func(ref imageRef) {
+		img, ok := m.loaded[ref]
+		if !ok || img.ID == 0 || img.Image == nil {
+			return
+		}
+		if !m.kittySent[img.ID] {
+			buf.WriteString(m.kittyTransmission(img, ref.n == headerRef))
+			m.kittySent[img.ID] = true
+		}
+		if a := img.Animation; len(a.Frames) > 1 {
+			term.KittyAnimate(&buf, img.ID, uint32(a.Frames[0].Delay.Milliseconds()), a.Loops)
+		}
+	}
+
+	send(imageRef{m.Page, headerRef})
+	for n := range m.Slides[m.Page].Images {
+		send(imageRef{m.Page, n})
+	}
+	if buf.Len() > 0 {
+		m.output().Write(buf.Bytes())
+	}
+}
+
+// hideImages removes the images of the current slide from Kitty's screen,
+// which keeps showing them otherwise, and stops their animations.
+func (m *Model) hideImages() {
+	if m.TerminalProtocol != term.Kitty || m.Page >= len(m.Slides) {
+		return
+	}
+	var buf bytes.Buffer
+	if header, ok := m.loaded[imageRef{m.Page, headerRef}]; ok && header.ID != 0 {
+		term.KittyDeletePlacements(&buf, header.ID)
+	}
+	for n := range m.Slides[m.Page].Images {
+		img, ok := m.loaded[imageRef{m.Page, n}]
+		if !ok || img.ID == 0 {
+			continue
+		}
+		if len(img.Animation.Frames) > 1 {
+			term.KittyStopAnimation(&buf, img.ID)
+		}
+		term.KittyDeletePlacements(&buf, img.ID)
+	}
+	if buf.Len() > 0 {
+		m.output().Write(buf.Bytes())
+	}
+}
+
+func writeItermImage(buf *bytes.Buffer, img image.Image, cols, rows int) {
+	term.ItermWriteImageWithOptions(buf, img, term.ItermImgOpts{
+		Width:           strconv.Itoa(cols),
+		Height:          strconv.Itoa(rows),
+		DisplayInline:   true,
+		DoNotMoveCursor: true,
+	})
+}
+
+// imageBlock returns rows lines of cols spaces, the first of which is
+// prefixed by the escape sequence which draws the image over them.
+func imageBlock(seq string, cols, rows int) string {
+	blank := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = blank
+	}
+	lines[0] = seq + blank
+	return strings.Join(lines, "\n")
+}
+
+// imageStr returns the rendered image, or its current frame.
+func (m Model) imageStr(img slides.Image) string {
+	if len(img.FrameStrs) == 0 {
+		return img.Str
+	}
+	return img.FrameStrs[img.Animation.FrameAt(m.animationTime)]
+}
+
+// markImages replaces the images of the slide by marker paragraphs, images
+// which float are removed and placed next to the text in floatImages.
+func (m Model) markImages(content string) string {
+	images := m.slideImages()
+	i := 0
+	return outsideCode(content, func(s string) string {
+		return imageRegexp.ReplaceAllStringFunc(s, func(string) string {
+			defer func() { i++ }()
+			if i < len(images) && images[i].Float != "" {
+				return ""
+			}
+			return "\n\n" + imageMarker(i) + "\n\n"
+		})
+	})
+}
+
+// placeImages replaces the rendered image markers with the images.
+func (m Model) placeImages(rendered string) string {
+	images := m.slideImages()
+	lines := strings.Split(rendered, "\n")
+	for i, line := range lines {
+		match := imageMarkerRegexp.FindStringSubmatch(strings.TrimSpace(ansi.Strip(line)))
+		if match == nil {
+			continue
+		}
+		n, _ := strconv.Atoi(match[1])
+		if n < len(images) {
+			lines[i] = indent(m.imageStr(images[n]), "  ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// floatWidth returns the columns taken up by the images which float on the
+// current slide, including the space around them.
+func (m Model) floatWidth() int {
+	left, right := 0, 0
+	for _, img := range m.slideImages() {
+		switch img.Float {
+		case "left":
+			left = max(left, img.Cols+floatGap)
+		case "right":
+			right = max(right, img.Cols+floatGap)
+		}
+	}
+	return left + right
+}
+
+// floatImages places the images which float left or right of the rendered
+// slide next to it, one below the other.
+func (m Model) floatImages(rendered string) string {
+	var left, right []string
+	for _, img := range m.slideImages() {
+		switch img.Float {
+		case "left":
+			left = append(left, m.imageStr(img))
+		case "right":
+			right = append(right, m.imageStr(img))
+		}
+	}
+	if left == nil && right == nil {
+		return rendered
+	}
+
+	// The images line up with the first line of the text, below the top
+	// padding of the slide.
+	column := func(images []string) string {
+		return lipgloss.NewStyle().MarginLeft(floatGap).Render("\n" + strings.Join(images, "\n\n"))
+	}
+	var parts []string
+	if left != nil {
+		parts = append(parts, column(left))
+	}
+	parts = append(parts, rendered)
+	if right != nil {
+		parts = append(parts, column(right))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/maaslalani/slides/internal/slides"
+)
+
+func TestParseImage(t *testing.T) {
+	tt := []struct {
+		markdown string
+		expected slides.Image
+	}{
+		{`![alt](image.png)`, slides.Image{Alt: "alt", Path: "image.png"}},
+		{`![](image.png)`, slides.Image{Path: "image.png"}},
+		{`![alt](<image.png>)`, slides.Image{Alt: "alt", Path: "image.png"}},
+		{`![alt](image.png "title")`, slides.Image{Alt: "alt", Path: "image.png"}},
+		{`![alt](image.png){width=40%}`, slides.Image{Alt: "alt", Path: "image.png", Width: "40%"}},
+		{`![alt](image.png){width=20 height=10 float=left}`, slides.Image{Alt: "alt", Path: "image.png", Width: "20", Height: "10", Float: "left"}},
+		{`![alt](image.png){float=middle}`, slides.Image{Alt: "alt", Path: "image.png"}},
+		{`![alt](image.png){unknown=1}`, slides.Image{Alt: "alt", Path: "image.png"}},
+	}
+
+	for _, tc := range tt {
+		match := imageRegexp.FindStringSubmatch(tc.markdown)
+		if match == nil {
+			t.Errorf("expected %s to match", tc.markdown)
+			continue
+		}
+		if got := parseImage(match); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tc.markdown, tc.expected, got)
+		}
+	}
+}
+
+func TestCellSize(t *testing.T) {
+	tt := []struct {
+		size     string
+		total    int
+		expected int
+		ok       bool
+	}{
+		{"20", 80, 20, true},
+		{"50%", 80, 40, true},
+		{"12.5%", 80, 10, true},
+		{"", 80, 0, false},
+		{"wide", 80, 0, false},
+		{"x%", 80, 0, false},
+	}
+
+	for _, tc := range tt {
+		got, ok := cellSize(tc.size, tc.total)
+		if ok != tc.ok || (ok && got != tc.expected) {
+			t.Errorf("cellSize(%q, %d): expected %d, %t, got %d, %t", tc.size, tc.total, tc.expected, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	abs, err := filepath.Abs("image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tt := []struct {
+		fileName string
+		path     string
+		expected string
+	}{
+		{"", "image.png", "image.png"},
+		{"slides.md", "image.png", "image.png"},
+		{"talks/slides.md", "image.png", filepath.Join("talks", "image.png")},
+		{"talks/slides.md", "../image.png", "image.png"},
+		{"talks/slides.md", abs, abs},
+	}
+
+	for _, tc := range tt {
+		m := Model{FileName: tc.fileName}
+		if got := m.resolvePath(tc.path); got != tc.expected {
+			t.Errorf("resolvePath(%q) in %q: expected %q, got %q", tc.path, tc.fileName, tc.expected, got)
+		}
+	}
+}
+
+func TestImageCells(t *testing.T) {
+	// The slide is 72 columns wide and 24 rows high. Cells are 2.2 times as
+	// tall as they are wide, so a 10x22 image is as wide as it is high in
+	// cells.
+	square := image.NewRGBA(image.Rect(0, 0, 10, 22))
+	wide := image.NewRGBA(image.Rect(0, 0, 100, 22))
+
+	tt := []struct {
+		desc       string
+		img        slides.Image
+		cols, rows int
+	}{
+		{"fits the height of the slide", slides.Image{Image: square}, 24, 24},
+		{"fits the width of the slide", slides.Image{Image: wide}, 72, 7},
+		{"width in cells", slides.Image{Image: square, Width: "10"}, 10, 10},
+		{"width in percent", slides.Image{Image: square, Width: "25%"}, 18, 18},
+		{"height in cells", slides.Image{Image: square, Height: "5"}, 5, 5},
+		{"larger than the slide", slides.Image{Image: square, Width: "200"}, 24, 24},
+		{"floating", slides.Image{Image: wide, Float: "left"}, 36, 3},
+	}
+
+	for _, tc := range tt {
+		m := Model{viewport: viewport.New(80, 30)}
+		cols, rows := m.imageCells(tc.img)
+		if cols != tc.cols || rows != tc.rows {
+			t.Errorf("%s: expected %dx%d, got %dx%d", tc.desc, tc.cols, tc.rows, cols, rows)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/maaslalani/slides/internal/animation"
+	"github.com/maaslalani/slides/internal/term"
+)
+
+// imageCache holds the images of the presentation, so that they are read,
+// decoded and encoded once rather than every time the presentation is
+// reloaded or resized. It is shared by the viewers of a served presentation.
+type imageCache struct {
+	mu      sync.Mutex
+	entries map[imageKey]*cachedImage
+	byID    map[uint32]*cachedImage
+	nextID  uint32
+}
+
+// imageKey identifies an image file by its path, modification time and
+// size, so that a changed file is read again. Images drawn from the text of
+// headers are keyed by the text and its style.
+type imageKey struct {
+	path   string
+	mtime  time.Time
+	size   int64
+	header string
+	style  headerStyle
+}
+
+type cachedImage struct {
+	// id is the id the image is sent to Kitty with, it is unique in the
+	// presentation.
+	id        uint32
+	once      sync.Once
+	image     image.Image
+	animation animation.Animation
+	// encoded holds the escape sequences which draw the image, one per
+	// frame for terminals which are sent every frame.
+	encoded map[encoding][]string
+}
+
+// encoding is how an image is encoded for a terminal.
+type encoding struct {
+	protocol   term.TerminalProtocol
+	header     bool
+	cols, rows int
+}
+
+func newImageCache() *imageCache {
+	return &imageCache{
+		entries: map[imageKey]*cachedImage{},
+		byID:    map[uint32]*cachedImage{},
+	}
+}
+
+// get returns the image for key, loading it with load on first use. Images
+// are loaded at most once, but different images at the same time.
+func (c *imageCache) get(key imageKey, load func() (image.Image, animation.Animation)) *cachedImage {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.nextID++
+		e = &cachedImage{id: c.nextID, encoded: map[encoding][]string{}}
+		c.entries[key] = e
+		c.byID[e.id] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		e.image, e.animation = load()
+	})
+	return e
+}
+
+// file returns the image at path, which is nil if it could not be read.
+func (c *imageCache) file(path string) *cachedImage {
+	info, err := os.Stat(path)
+	if err != nil {
+		return &cachedImage{}
+	}
+	key := imageKey{path: path, mtime: info.ModTime(), size: info.Size()}
+	return c.get(key, func() (image.Image, animation.Animation) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, animation.Animation{}
+		}
+		if a, err := animation.Decode(data); err == nil {
+			return a.Frames[0].Image, a
+		}
+		img, _, _ := image.Decode(bytes.NewReader(data))
+		return img, animation.Animation{}
+	})
+}
+
+// header returns the image of the text of a header. Headers are drawn with
+// the embedded font if the font of the style could not be loaded.
+func (c *imageCache) header(text string, style headerStyle) *cachedImage {
+	return c.get(imageKey{header: text, style: style}, func() (image.Image, animation.Animation) {
+		f, err := loadFont(style.font)
+		if err != nil {
+			f, err = loadFont("")
+		}
+		if err != nil {
+			return nil, animation.Animation{}
+		}
+		// Headers are drawn at 24 pixels per row, the height of a cell on
+		// most terminals.
+		img, _ := CreateImageFromText(text, f, 24*style.rows, headerColor(style.color), style.bold)
+		return img, animation.Animation{}
+	})
+}
+
+// encode returns the image with the given id encoded as enc, encoding it
+// with f on first use.
+func (c *imageCache) encode(id uint32, enc encoding, f func() []string) []string {
+	c.mu.Lock()
+	e, ok := c.byID[id]
+	var strs []string
+	if ok {
+		strs, ok = e.encoded[enc]
+	}
+	c.mu.Unlock()
+	if ok {
+		return strs
+	}
+
+	strs = f()
+	c.mu.Lock()
+	if e != nil {
+		e.encoded[enc] = strs
+	}
+	c.mu.Unlock()
+	return strs
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maaslalani/slides/internal/animation"
+	"github.com/maaslalani/slides/internal/term"
+)
+
+func TestImageCacheGet(t *testing.T) {
+	c := newImageCache()
+	loads := 0
+	load := func() (image.Image, animation.Animation) {
+		loads++
+		return image.NewRGBA(image.Rect(0, 0, 1, 1)), animation.Animation{}
+	}
+
+	now := time.Now()
+	a := imageKey{path: "a.png", mtime: now, size: 1}
+	tt := []struct {
+		desc  string
+		key   imageKey
+		id    uint32
+		loads int
+	}{
+		{"first image", a, 1, 1},
+		{"same image", a, 1, 1},
+		{"other image", imageKey{path: "b.png", mtime: now, size: 1}, 2, 2},
+		{"modified image", imageKey{path: "a.png", mtime: now.Add(time.Second), size: 1}, 3, 3},
+		{"resized image", imageKey{path: "a.png", mtime: now, size: 2}, 4, 4},
+		{"header", imageKey{header: "Title"}, 5, 5},
+		{"header with another style", imageKey{header: "Title", style: headerStyle{rows: 4}}, 6, 6},
+	}
+
+	for _, tc := range tt {
+		e := c.get(tc.key, load)
+		if e.id != tc.id || loads != tc.loads || e.image == nil {
+			t.Errorf("%s: expected id %d after %d loads, got id %d after %d loads", tc.desc, tc.id, tc.loads, e.id, loads)
+		}
+	}
+}
+
+func TestImageCacheGetOnce(t *testing.T) {
+	c := newImageCache()
+	key := imageKey{path: "a.png"}
+	var mu sync.Mutex
+	loads := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.get(key, func() (image.Image, animation.Animation) {
+				mu.Lock()
+				loads++
+				mu.Unlock()
+				return nil, animation.Animation{}
+			})
+		}()
+	}
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("expected the image to be loaded once, got %d loads", loads)
+	}
+}
+
+func TestImageCacheFile(t *testing.T) {
+	c := newImageCache()
+	name := filepath.Join(t.TempDir(), "image.png")
+
+	if e := c.file(name); e.image != nil || e.id != 0 {
+		t.Errorf("expected a missing file to have no image, got %+v", e)
+	}
+
+	writePNG(t, name, 4, 2)
+	first := c.file(name)
+	if first.image == nil || first.image.Bounds().Dx() != 4 {
+		t.Fatalf("expected the image to be decoded, got %+v", first)
+	}
+	if again := c.file(name); again != first {
+		t.Error("expected the unchanged file to be cached")
+	}
+
+	writePNG(t, name, 8, 2)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(name, later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed := c.file(name)
+	if changed == first || changed.image.Bounds().Dx() != 8 {
+		t.Error("expected the changed file to be read again")
+	}
+}
+
+func TestImageCacheEncode(t *testing.T) {
+	c := newImageCache()
+	e := c.get(imageKey{path: "a.png"}, func() (image.Image, animation.Animation) {
+		return nil, animation.Animation{}
+	})
+
+	encodes := 0
+	encode := func(s string) func() []string {
+		return func() []string {
+			encodes++
+			return []string{s}
+		}
+	}
+	kitty := encoding{protocol: term.Kitty, cols: 10, rows: 5}
+	iterm := encoding{protocol: term.Iterm, cols: 10, rows: 5}
+
+	tt := []struct {
+		desc     string
+		id       uint32
+		enc      encoding
+		expected string
+		encodes  int
+	}{
+		{"first encoding", e.id, kitty, "kitty", 1},
+		{"same encoding", e.id, kitty, "kitty", 1},
+		{"other protocol", e.id, iterm, "iterm", 2},
+		{"other size", e.id, encoding{protocol: term.Kitty, cols: 20, rows: 10}, "larger", 3},
+		{"unknown image", e.id + 1, kitty, "unknown", 4},
+		{"unknown image is not cached", e.id + 1, kitty, "unknown", 5},
+	}
+
+	for _, tc := range tt {
+		got := c.encode(tc.id, tc.enc, encode(tc.expected))
+		if len(got) != 1 || got[0] != tc.expected || encodes != tc.encodes {
+			t.Errorf("%s: expected %q after %d encodes, got %q after %d", tc.desc, tc.expected, tc.encodes, got, encodes)
+		}
+	}
+}
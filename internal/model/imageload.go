@@ -0,0 +1,205 @@
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/slides"
+	"github.com/maaslalani/slides/internal/term"
+	"github.com/maaslalani/slides/styles"
+)
+
+// Images are decoded and rendered when their slide, or a slide next to it,
+// is shown rather than when the presentation is loaded. At most one image
+// per CPU is loaded at a time, across all the viewers of a served
+// presentation.
+var imageWorkers = make(chan struct{}, runtime.NumCPU())
+
+// imageRef identifies an image by its slide and its position on the slide,
+// the header of a slide is at headerRef.
+type imageRef struct {
+	page, n int
+}
+
+const headerRef = -1
+
+type imageLoadedMsg struct {
+	id    int
+	ref   imageRef
+	image slides.Image
+}
+
+// resetImages drops the rendered images and cancels those being loaded, so
+// that they are loaded again at the current size of the window.
+func (m *Model) resetImages() {
+	if m.cancelLoads != nil {
+		m.cancelLoads()
+	}
+	m.loadID++
+	m.loaded = nil
+	m.loading = nil
+	m.cancelLoads = nil
+}
+
+// loadImages loads the images of the current slide and of the slides before
+// and after it which are not loaded yet.
+func (m *Model) loadImages() tea.Cmd {
+	if m.Page >= len(m.Slides) {
+		return nil
+	}
+	if m.loaded == nil {
+		parent := m.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		m.loaded = map[imageRef]slides.Image{}
+		m.loading = map[imageRef]bool{}
+		m.loadContext, m.cancelLoads = context.WithCancel(parent)
+	}
+
+	var cmds []tea.Cmd
+	load := func(ref imageRef, img slides.Image) {
+		if _, ok := m.loaded[ref]; ok || m.loading[ref] {
+			return
+		}
+		m.loading[ref] = true
+		cmds = append(cmds, m.loadImageCmd(ref, img))
+	}
+	for _, page := range []int{m.Page, m.Page + 1, m.Page - 1} {
+		if page < 0 || page >= len(m.Slides) {
+			continue
+		}
+		slide := m.Slides[page]
+		if slide.Header != nil {
+			load(imageRef{page, headerRef}, *slide.Header)
+		}
+		for n, img := range slide.Images {
+			load(imageRef{page, n}, img)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadImageCmd decodes and renders an image on one of the image workers.
+func (m *Model) loadImageCmd(ref imageRef, img slides.Image) tea.Cmd {
+	ctx, id := m.loadContext, m.loadID
+	r := *m
+	r.imageCache()
+	return func() tea.Msg {
+		select {
+		case imageWorkers <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+		defer func() { <-imageWorkers }()
+		// The load may have been cancelled while a worker was free as well.
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if ref.n == headerRef && r.figletHeader(img) {
+			return imageLoadedMsg{id: id, ref: ref, image: r.renderFiglet(img)}
+		}
+		img = r.loadImage(img)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if ref.n == headerRef {
+			img = r.renderHeader(img)
+		} else {
+			img = r.renderImage(img, ref.n)
+		}
+		if r.TerminalProtocol == term.Kitty && img.Image != nil {
+			// Encode the image now, so that sending it does not hold up
+			// the presentation.
+			r.kittyTransmission(img, ref.n == headerRef)
+		}
+		return imageLoadedMsg{id: id, ref: ref, image: img}
+	}
+}
+
+// imageLoaded stores a loaded image and sends it to Kitty if it is on the
+// current slide.
+func (m *Model) imageLoaded(msg imageLoadedMsg) tea.Cmd {
+	if msg.id != m.loadID || m.loaded == nil {
+		return nil
+	}
+	m.loaded[msg.ref] = msg.image
+	delete(m.loading, msg.ref)
+	if msg.ref.page != m.Page {
+		return nil
+	}
+	m.sendImages()
+	if len(msg.image.FrameStrs) > 1 {
+		return m.startAnimations()
+	}
+	return nil
+}
+
+// slideImages returns the images of the current slide, those which are
+// still loading are placeholders.
+func (m Model) slideImages() []slides.Image {
+	images := make([]slides.Image, len(m.Slides[m.Page].Images))
+	for n, img := range m.Slides[m.Page].Images {
+		if m.previewing {
+			img.Str = styles.Dim.Render("[" + imageName(img) + "]")
+			img.Cols = ansi.StringWidth(img.Str)
+			images[n] = img
+			continue
+		}
+		if loaded, ok := m.loaded[imageRef{m.Page, n}]; ok {
+			images[n] = loaded
+			continue
+		}
+		img.Str = loadingPlaceholder(img)
+		img.Cols = ansi.StringWidth(img.Str)
+		images[n] = img
+	}
+	return images
+}
+
+// slideHeader returns the header image of the current slide, which is a
+// placeholder while it is loading.
+func (m Model) slideHeader() string {
+	header := m.Slides[m.Page].Header
+	if header == nil {
+		return ""
+	}
+	if loaded, ok := m.loaded[imageRef{m.Page, headerRef}]; ok {
+		if m.previewing && loaded.Image != nil {
+			return m.headerText(styles.Dim.Render(imageName(*header)))
+		}
+		if m.transitioning() && loaded.Image != nil {
+			// Images move the cursor, which the frames of transitions
+			// can not.
+			return m.headerText("")
+		}
+		return loaded.Str
+	}
+	if m.previewing {
+		return m.headerText(styles.Dim.Render(imageName(*header)))
+	}
+	return m.headerText(loadingPlaceholder(*header))
+}
+
+// headerText returns text in place of the image of a header, taking up
+// about as many rows as the image.
+func (m Model) headerText(text string) string {
+	return "\n  " + text + strings.Repeat("\n", max(m.headerStyle().rows-2, 1))
+}
+
+func loadingPlaceholder(img slides.Image) string {
+	return styles.Dim.Render("[loading " + imageName(img) + "…]")
+}
+
+// imageName returns the alt text of an image, or else the name of its file.
+func imageName(img slides.Image) string {
+	if img.Alt != "" {
+		return img.Alt
+	}
+	return filepath.Base(img.Path)
+}
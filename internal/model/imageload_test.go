@@ -0,0 +1,162 @@
+package model
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// imageDeck has an image on each of its slides.
+const imageDeck = "# One\n\n![first](one.png)\n\n---\n\n# Two\n\n![second](two.png)\n\n---\n\n# Three\n\n![third](three.png)\n\n---\n\n# Four\n\n![fourth](four.png)\n"
+
+// newImageTestModel loads imageDeck with the images next to it.
+func newImageTestModel(t *testing.T) Model {
+	t.Helper()
+	m := newTestModel(t, imageDeck)
+	dir := filepath.Dir(m.FileName)
+	for _, name := range []string{"one.png", "two.png", "three.png", "four.png"} {
+		writePNG(t, filepath.Join(dir, name), 4, 2)
+	}
+	return m
+}
+
+func writePNG(t *testing.T, name string, width, height int) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runCmd runs cmd and the commands of the batches it returns, and returns
+// their messages.
+func runCmd(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, cmd := range batch {
+			msgs = append(msgs, runCmd(cmd)...)
+		}
+		return msgs
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+// imageLoads returns the imageLoadedMsgs among msgs.
+func imageLoads(msgs []tea.Msg) []imageLoadedMsg {
+	var loads []imageLoadedMsg
+	for _, msg := range msgs {
+		if msg, ok := msg.(imageLoadedMsg); ok {
+			loads = append(loads, msg)
+		}
+	}
+	return loads
+}
+
+func TestImageLoaded(t *testing.T) {
+	m := newImageTestModel(t)
+	cmd := m.loadImages()
+
+	if view := strings.Join(plainLines(m.View()), "\n"); !strings.Contains(view, "[loading first…]") {
+		t.Fatalf("expected a placeholder while the image loads:\n%s", view)
+	}
+
+	loads := imageLoads(runCmd(cmd))
+	if len(loads) == 0 {
+		t.Fatal("expected the images to be loaded")
+	}
+	for _, msg := range loads {
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+
+	view := strings.Join(plainLines(m.View()), "\n")
+	if strings.Contains(view, "[loading") || !strings.Contains(view, "[first]") {
+		t.Errorf("expected the loaded image in place of the placeholder:\n%s", view)
+	}
+	if img, ok := m.loaded[imageRef{0, 0}]; !ok || img.Image == nil {
+		t.Errorf("expected the image to be decoded, got %+v", img)
+	}
+}
+
+func TestImageLoadedAfterResize(t *testing.T) {
+	m := newImageTestModel(t)
+	loads := imageLoads(runCmd(m.loadImages()))
+	if len(loads) == 0 {
+		t.Fatal("expected the images to be loaded")
+	}
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = updated.(Model)
+	for _, msg := range loads {
+		updated, _ = m.Update(msg)
+		m = updated.(Model)
+	}
+	if len(m.loaded) != 0 {
+		t.Errorf("expected images loaded at the previous size to be dropped, got %d", len(m.loaded))
+	}
+}
+
+func TestResetImagesCancelsLoads(t *testing.T) {
+	m := newImageTestModel(t)
+	cmd := m.loadImages()
+	ctx := m.loadContext
+
+	m.resetImages()
+	if ctx.Err() == nil {
+		t.Fatal("expected the loads to be cancelled")
+	}
+	if loads := imageLoads(runCmd(cmd)); len(loads) != 0 {
+		t.Errorf("expected cancelled loads to return nothing, got %d images", len(loads))
+	}
+	if m.loaded != nil || m.loading != nil {
+		t.Error("expected the loaded images to be dropped")
+	}
+}
+
+func TestLoadImagesAdjacentSlides(t *testing.T) {
+	tt := []struct {
+		page     int
+		expected []int
+	}{
+		{0, []int{0, 1}},
+		{1, []int{0, 1, 2}},
+		{3, []int{2, 3}},
+	}
+
+	for _, tc := range tt {
+		m := newImageTestModel(t)
+		m.Page = tc.page
+		m.loadImages()
+
+		// Every slide has a header and an image.
+		pages := map[int]bool{}
+		for ref := range m.loading {
+			pages[ref.page] = true
+		}
+		if len(pages) != len(tc.expected) || len(m.loading) != 2*len(tc.expected) {
+			t.Errorf("page %d: expected the images of slides %v to load, got %v", tc.page, tc.expected, m.loading)
+		}
+		for _, page := range tc.expected {
+			if !m.loading[imageRef{page, 0}] || !m.loading[imageRef{page, headerRef}] {
+				t.Errorf("page %d: expected the images of slide %d to load", tc.page, page)
+			}
+		}
+		m.resetImages()
+	}
+}
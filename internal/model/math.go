@@ -0,0 +1,167 @@
+package model
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/golang/freetype"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/internal/term"
+	"github.com/maaslalani/slides/internal/tex"
+)
+
+// mathLanguage is the language of code blocks which are replaced by their
+// LaTeX formula set in Unicode. Formulas between $$ in the markdown become
+// math blocks as well, formulas between single $ are set inline.
+//
+//	```math {image}
+//	\sum_{i=1}^n i = \frac{n(n+1)}{2}
+//	```
+const mathLanguage = "math"
+
+// mathFontSize is the size formulas are drawn at on terminals which show
+// images.
+const mathFontSize = 48
+
+var inlineCodeRegexp = regexp.MustCompile("`[^`\n]*`")
+
+// preprocessMath turns the $$ formulas of a slide into math blocks and sets
+// the $ formulas in Unicode. Code is left alone.
+func preprocessMath(content string) string {
+	if !strings.Contains(content, "$") {
+		return content
+	}
+	return outsideCode(content, replaceFormulas)
+}
+
+// replaceFormulas replaces $$ and $ formulas. Like in pandoc, an inline
+// formula must not start or end with a space and must not be followed by a
+// digit, so that amounts such as $5 and $10 are not taken for math.
+func replaceFormulas(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			b.WriteString(s[i : i+2])
+			i++
+			continue
+		}
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		if strings.HasPrefix(s[i:], "$$") {
+			if end := strings.Index(s[i+2:], "$$"); end >= 0 {
+				formula := strings.TrimSpace(s[i+2 : i+2+end])
+				b.WriteString("\n```" + mathLanguage + "\n" + formula + "\n```\n")
+				i += end + 3
+				continue
+			}
+		}
+
+		if end := closingDollar(s[i+1:]); end > 0 {
+			b.WriteString(escapeMarkdown(tex.Inline(s[i+1 : i+1+end])))
+			i += end + 1
+			continue
+		}
+		b.WriteByte('$')
+	}
+	return b.String()
+}
+
+// closingDollar returns the index of the $ which closes the inline formula
+// at the start of s, or -1 if s does not start a formula.
+func closingDollar(s string) int {
+	if s == "" || s[0] == ' ' || s[0] == '\t' || s[0] == '$' {
+		return -1
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			return -1
+		case '\\':
+			i++
+		case '$':
+			if s[i-1] == ' ' || s[i-1] == '\t' {
+				continue
+			}
+			if i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// escapeMarkdown escapes the characters of s which markdown would take for
+// emphasis, links, code or HTML.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\*_`[]<>", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// renderMath sets the formula of the math block at index i in Unicode. With
+// the image option, terminals which show images get the formula drawn as an
+// image instead.
+func (m *Model) renderMath(i int, block code.Block, availableCells int) {
+	formula := tex.Display(block.Code)
+	_, asImage := block.Option("image")
+	if asImage && (m.TerminalProtocol == term.Kitty || m.TerminalProtocol == term.Iterm) {
+		lines := strings.Split(formula, "\n")
+		if img, err := createImageFromLines(lines, mathFontSize); err == nil {
+			cols := 0
+			for _, line := range lines {
+				cols = max(cols, ansi.StringWidth(line))
+			}
+			// Images are drawn at twice the size of the text.
+			m.setOutput(i, code.RenderImage(img, m.TerminalProtocol, min(2*len(lines), availableCells), min(2*cols, m.viewport.Width)))
+			return
+		}
+	}
+	m.setOutput(i, formula)
+}
+
+// createImageFromLines draws lines of monospaced text on an image, keeping
+// the characters of the lines in their columns.
+func createImageFromLines(lines []string, fontSize int) (image.Image, error) {
+	f, err := loadFont("")
+	if err != nil {
+		return nil, err
+	}
+
+	cols := 0
+	for _, line := range lines {
+		cols = max(cols, ansi.StringWidth(line))
+	}
+	// The advance of a monospaced character is about 3/5 of its size.
+	advance, lineHeight := fontSize*3/5, fontSize*6/5
+	img := image.NewRGBA(image.Rect(0, 0, (cols+1)*advance, len(lines)*lineHeight+fontSize/2))
+	draw.Draw(img, img.Bounds(), &image.Uniform{image.Transparent}, image.Point{}, draw.Src)
+
+	c := freetype.NewContext()
+	c.SetDPI(72)
+	c.SetFont(f)
+	c.SetFontSize(float64(fontSize))
+	c.SetClip(img.Bounds())
+	c.SetDst(img)
+	c.SetSrc(image.NewUniform(color.RGBA{R: 221, G: 221, B: 221, A: 255}))
+
+	for i, line := range lines {
+		pt := freetype.Pt(advance/2, fontSize+i*lineHeight)
+		if _, err := c.DrawString(line, pt); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
@@ -2,26 +2,35 @@ package model
 
 import (
 	"bufio"
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"image"
 	"image/color"
-	"image/draw"
 	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/golang/freetype"
+	"github.com/golang/freetype/truetype"
+	"github.com/maaslalani/slides/internal/cast"
 	"github.com/maaslalani/slides/internal/file"
+	"github.com/maaslalani/slides/internal/keymap"
 	"github.com/maaslalani/slides/internal/navigation"
+	"github.com/maaslalani/slides/internal/pane"
 	"github.com/maaslalani/slides/internal/process"
 	"github.com/maaslalani/slides/internal/slides"
 	"github.com/maaslalani/slides/internal/term"
+	"github.com/muesli/termenv"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
@@ -34,14 +43,18 @@ import (
 var (
 	//go:embed tutorial.md
 	slidesTutorial []byte
-	tabSpaces      = strings.Repeat(" ", 4)
+	//go:embed FiraMono-Regular.ttf
+	defaultFont []byte
+	tabSpaces   = strings.Repeat(" ", 4)
 )
 
 const (
 	delimiter = "\n---\n"
 )
 
-const headerCells = 3
+// defaultHeaderRows is the height of headers which are drawn as images,
+// unless the front matter sets it.
+const defaultHeaderRows = 3
 
 // Model represents the model of this presentation, which contains all the
 // state related to the current slides.
@@ -53,13 +66,139 @@ type Model struct {
 	Theme    glamour.TermRendererOption
 	Paging   string
 	FileName string
+	// themeColors are the colors of the theme from the front matter, charts
+	// are drawn in them.
+	themeColors []string
+	// header configures the headers drawn as images, from the front matter.
+	header   meta.Header
 	viewport viewport.Model
 	buffer   string
 	// VirtualText is used for additional information that is not part of the
 	// original slides, it will be displayed on a slide and reset on page change
-	VirtualText      string
-	Search           navigation.Search
+	VirtualText string
+	Search      navigation.Search
+	// Picker goes to a slide by its title.
+	Picker navigation.Picker
+	// Finder finds slides by their text.
+	Finder navigation.Finder
+	// KeyMap holds the key bindings from the configuration file, the default
+	// ones if it is nil. Presentations can override them in their front
+	// matter.
+	KeyMap *keymap.KeyMap
+	// keys are the key bindings of the presentation.
+	keys keymap.KeyMap
+	// help is set while the help overlay is shown.
+	help             bool
 	TerminalProtocol term.TerminalProtocol
+	// Output is the terminal the presentation is displayed on. It is used for
+	// escape sequences that bypass the renderer, such as OSC 52 clipboard
+	// requests, and defaults to stdout. The program should render to the same
+	// writer, made with term.Synchronized, so that their writes do not
+	// interleave.
+	Output io.Writer
+	// Remote is set when the presentation is viewed over SSH through
+	// `slides serve`, where the local clipboard belongs to the server.
+	Remote bool
+	// AllowTerminal lets terminal panes run over `slides serve`, where their
+	// commands run on the server for whoever is viewing.
+	AllowTerminal bool
+	// Transition is the transition between slides from the front matter,
+	// slides can set their own with a transition directive.
+	Transition string
+	// NoTransitions turns off the transitions between slides, e.g. for slow
+	// connections.
+	NoTransitions bool
+	// Mouse is set when the program reports the mouse, slides which are
+	// taller than the terminal are then scrolled with the wheel.
+	Mouse bool
+	// scroll is how many lines of the current slide are scrolled out of view
+	// at the top.
+	scroll int
+	// Context is done when the viewer of the presentation disconnects, it
+	// stops the commands running in terminal panes.
+	Context context.Context
+	// status is a transient message displayed in the status line.
+	status string
+	// focus is one more than the index of the focused code block on the
+	// current slide, zero means that no block has focus.
+	focus int
+	// outputs holds the output of executed code blocks on the current slide
+	// keyed by the index of the block.
+	outputs map[int]string
+	// typewriters holds the progress of code blocks on the current slide
+	// which type themselves out, keyed by the index of the block.
+	typewriters  map[int]typewriter
+	typewriterID int
+	// step is the current group of highlighted code lines on the slide.
+	step int
+	// lastStep is set when the slide was entered going back, it is moved to
+	// its last step once the pages of its tables are known.
+	lastStep bool
+	// morphFrame is the current frame of the magic move animation between
+	// two versions of a code block.
+	morphFrame   int
+	morphID      int
+	morphStarted bool
+	// pane is the interactive terminal of the current slide, key presses are
+	// sent to it while it has focus.
+	pane        *pane.Pane
+	paneFocused bool
+	// casts holds the players of the recordings on the current slide, keyed
+	// by the index of the block.
+	casts  map[int]*cast.Player
+	castID int
+	// tables holds the pages of the tables on the current slide, keyed by
+	// the index of the block.
+	tables map[int][]string
+	// images caches the images of the presentation.
+	images *imageCache
+	// loaded holds the images which were loaded and rendered at the current
+	// size of the window, and loading those which are being loaded until
+	// loadContext is cancelled. They are made by the first Update.
+	loaded      map[imageRef]slides.Image
+	loading     map[imageRef]bool
+	loadID      int
+	loadContext context.Context
+	cancelLoads context.CancelFunc
+	// kittySent holds the ids of the images Kitty was sent. It is made by
+	// the first Update, so that every viewer of a served presentation has
+	// their own.
+	kittySent map[uint32]bool
+	// found is the query of the finder, which is highlighted on the slide it
+	// went to until the page changes.
+	found string
+	// previewing is set on the copies of the model which preview a slide in
+	// the finder, they are drawn without images.
+	previewing bool
+	// tagBlocks is set while the slide is rendered for the mouse, the lines
+	// of code blocks are tagged with the index of the block.
+	tagBlocks bool
+	// frame is the slide as it was last drawn, which the mouse is matched
+	// against. It is made by the first Update, so that every viewer of a
+	// served presentation has their own.
+	frame *frame
+	// sections are the sections of the presentation in order.
+	sections []navigation.Section
+	// overview is set while the grid of slides is shown instead of the
+	// current slide, overviewSelected is the page of the selected card.
+	overview         bool
+	overviewSelected int
+	// animationTime is how long the animated images on the current slide
+	// have been playing for on terminals which are sent every frame.
+	animationStart time.Time
+	animationTime  time.Duration
+	animationID    int
+	// transition is the kind of the transition which is playing from the
+	// screen transitionFrom to the current slide.
+	transition     string
+	transitionFrom string
+	// transitionDest caches the screen the transition goes to, so that the
+	// slide is not rendered again for every frame. It is rendered on the
+	// first frame, once the slide was set up.
+	transitionDest     string
+	transitionStart    time.Time
+	transitionProgress float64
+	transitionID       int
 }
 
 type fileWatchMsg struct{}
@@ -110,49 +249,52 @@ func (m *Model) Load() error {
 		slides = slides[1:]
 	}
 
+	keys := keymap.Default()
+	if m.KeyMap != nil {
+		keys = *m.KeyMap
+	}
+	keys, err = keys.With(metaData.Keys)
+	if err != nil {
+		return fmt.Errorf("keys: %w", err)
+	}
+	m.keys = keys
+
+	m.imageCache()
+	m.resetImages()
+	m.header = metaData.Header
 	m.Slides = m.parseSlides(slides)
 	m.Author = metaData.Author
 	m.Date = metaData.Date
 	m.Paging = metaData.Paging
+	m.Transition = metaData.Transition
 	if m.Theme == nil {
-		m.Theme = styles.SelectTheme(metaData.Theme)
+		theme := styles.ThemeConfig(metaData.Theme)
+		m.Theme = glamour.WithStyles(theme)
+		m.themeColors = styles.ThemeColors(theme)
 	}
 
 	return nil
 }
 
-func (m *Model) updateSlides() {
-	for i, slide := range m.Slides {
-		header := slide.Header
-		img := slide.Image
-		headerStr := ""
-		if header != nil {
-			headerStr = code.RenderImage(header, m.TerminalProtocol, headerCells, m.viewport.Width)
-		}
-		imageStr := ""
-		if img != nil {
-			imageStr = code.RenderImage(img, m.TerminalProtocol, m.viewport.Height, m.viewport.Width)
-		}
-		m.Slides[i].HeaderStr = headerStr
-		m.Slides[i].ImageStr = imageStr
-	}
-}
-
 func (m *Model) parseSlides(slidesStr []string) []slides.Slide {
+	m.sections = parseSections(slidesStr)
 	newSlides := make([]slides.Slide, len(slidesStr))
 	for i, slide := range slidesStr {
-		header, slide := preprocessHeader(slide)
-		img, slide := preprocessImage(slide)
+		slide = preprocessTOC(slide, m.sections)
+		slide = preprocessMath(slide)
+		header, slide := m.preprocessHeader(slide)
 		newSlides[i] = slides.Slide{
 			Content: slide,
 			Header:  header,
-			Image:   img,
+			Images:  preprocessImages(slide),
 		}
 	}
 
 	return newSlides
 }
 
+// ExecuteCode runs the focused code block on the current slide, or every
+// block if none has focus, and displays the output below each block.
 func (m *Model) ExecuteCode() {
 	// Run code blocks
 	blocks, err := code.Parse(m.Slides[m.Page].Content)
@@ -161,112 +303,300 @@ func (m *Model) ExecuteCode() {
 		m.VirtualText = "\n" + err.Error()
 		return
 	}
-	var outs []string
 
-	for _, block := range blocks {
-		res := code.Execute(
-			block,
-			m.TerminalProtocol,
-			m.GetAvailableCells(),
-			m.viewport.Width,
-		)
-		outs = append(outs, res.Out)
+	focused, hasFocus := m.focusedBlock()
+	availableCells := m.GetAvailableCells()
+	for i, block := range blocks {
+		if (hasFocus && i != focused) || isElementLanguage(block.Language) {
+			continue
+		}
+		res := m.execute(block, availableCells)
+		m.setOutput(i, res.Out)
 	}
-	m.VirtualText = strings.TrimSpace(strings.Join(outs, "\n"))
+}
+
+// execute executes a code block. The images of img blocks are opened relative
+// to the presentation.
+func (m *Model) execute(block code.Block, availableCells int) code.Result {
+	if block.Language == "img" {
+		block.Code = m.resolvePath(strings.TrimSpace(block.Code))
+	}
+	return code.Execute(
+		block,
+		m.TerminalProtocol,
+		availableCells,
+		m.viewport.Width,
+	)
 }
 
 type autoExecuteCodeMsg struct{}
 
 // Update updates the presentation model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.frame == nil {
+		m.frame = &frame{}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.viewport.Width = msg.Width
 		m.viewport.Height = msg.Height
 		m.VirtualText = ""
-		m.updateSlides()
+		m.outputs = nil
+		m.tables = nil
+		m.resetImages()
+		m.transitionDest = ""
+		if m.pane != nil {
+			_, block, _ := m.terminalBlock()
+			m.pane.Resize(m.paneSize(block))
+		}
 		return m, ClearScreen
 
 	case autoExecuteCodeMsg:
 		m.AutoExecuteCode()
-		return m, nil
+		if m.lastStep {
+			m.step = m.steps() - 1
+			m.lastStep = false
+		}
+		return m, tea.Batch(m.startTypewriter(), m.startMorph(), m.loadCasts(), m.startAnimations(), m.loadImages())
+
+	case imageLoadedMsg:
+		return m, m.imageLoaded(msg)
+
+	case pane.UpdateMsg:
+		if msg.Pane != m.pane {
+			return m, nil
+		}
+		if msg.Exited {
+			m.paneFocused = false
+			return m, nil
+		}
+		return m, m.pane.Wait()
+
+	case castMsg:
+		if msg.id != m.castID {
+			return m, nil
+		}
+		now := time.Now()
+		for _, p := range m.casts {
+			p.Update(now)
+		}
+		return m, m.castTick()
+
+	case transitionMsg:
+		if msg.id != m.transitionID || !m.transitioning() {
+			return m, nil
+		}
+		return m, m.stepTransition()
+
+	case animationMsg:
+		if msg.id != m.animationID {
+			return m, nil
+		}
+		m.animationTime = time.Since(m.animationStart)
+		return m, m.animationTick()
+
+	case morphMsg:
+		if msg.id != m.morphID {
+			return m, nil
+		}
+		m.morphFrame++
+		return m, m.morphTick()
+
+	case typewriterMsg:
+		if msg.id != m.typewriterID {
+			return m, nil
+		}
+		if _, t, ok := m.typing(); ok {
+			m.stepTypewriter(t.byLine)
+		}
+		return m, m.typewriterTick()
 
 	case tea.KeyMsg:
 		keyPress := msg.String()
 
+		if m.paneFocused {
+			if key.Matches(msg, m.keys.LeavePane) {
+				m.paneFocused = false
+				return m, nil
+			}
+			m.pane.Send(msg)
+			return m, nil
+		}
+
+		if m.help {
+			return m, m.updateHelp(msg)
+		}
+
+		if m.overview {
+			return m, m.updateOverview(msg)
+		}
+
+		if m.Picker.Active {
+			return m, m.updatePicker(msg)
+		}
+
+		if m.Finder.Active {
+			return m, m.updateFinder(msg)
+		}
+
 		if m.Search.Active {
 			switch msg.Type {
 			case tea.KeyEnter:
 				// execute current buffer
 				if m.Search.Query() != "" {
-					m.Search.Execute(&m)
-				} else {
-					m.Search.Done()
+					return m, m.Search.Execute(&m)
 				}
 				// cancel search
+				m.Search.Done()
 				return m, nil
 			case tea.KeyCtrlC, tea.KeyEscape:
 				// quit command mode
 				m.Search.SetQuery("")
+				m.Search.Clear()
 				m.Search.Done()
 				return m, nil
 			}
 
 			var cmd tea.Cmd
 			m.Search.SearchTextInput, cmd = m.Search.SearchTextInput.Update(msg)
+			// The matches are highlighted while typing, the query is
+			// compiled once here rather than for every frame.
+			_, _ = m.Search.Pattern()
+			return m, cmd
+		}
+
+		// Recordings on the slide take their playback keys first.
+		if cmd, ok := m.castKey(msg); ok {
 			return m, cmd
 		}
 
-		switch keyPress {
-		case "/":
+		switch {
+		case key.Matches(msg, m.keys.Search):
 			// Begin search
 			m.Search.Begin()
 			m.Search.SearchTextInput.Focus()
 			return m, nil
-		case "ctrl+n":
+		case key.Matches(msg, m.keys.SearchBackward):
+			// Begin search towards the first slide
+			m.Search.BeginBackward()
+			m.Search.SearchTextInput.Focus()
+			return m, nil
+		case key.Matches(msg, m.keys.NextMatch):
 			// Go to next occurrence
-			m.Search.Execute(&m)
-		case "ctrl+x":
+			return m, m.Search.Execute(&m)
+		case key.Matches(msg, m.keys.PreviousMatch):
+			// Go to previous occurrence
+			return m, m.Search.Previous(&m)
+		case key.Matches(msg, m.keys.Clear):
 			m.VirtualText = ""
+			m.outputs = nil
+			m.found = ""
+			m.Search.Clear()
 			return m, ClearScreen
-		case "ctrl+e":
+		case key.Matches(msg, m.keys.Execute):
 			m.ExecuteCode()
 			return m, nil
-		case "y":
+		case key.Matches(msg, m.keys.FocusNext):
+			m.cycleFocus(1)
+			return m, nil
+		case key.Matches(msg, m.keys.FocusPrevious):
+			m.cycleFocus(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.FinishTyping):
+			m.finishTypewriter()
+			return m, nil
+		case key.Matches(msg, m.keys.TypeLine):
+			m.pauseTypewriter()
+			m.stepTypewriter(true)
+			return m, nil
+		case key.Matches(msg, m.keys.Copy):
 			blocks, err := code.Parse(m.Slides[m.Page].Content)
 			if err != nil {
 				return m, nil
 			}
+			if i, ok := m.focusedBlock(); ok {
+				blocks = blocks[i : i+1]
+			}
+			var snippets []string
 			for _, b := range blocks {
-				_ = clipboard.WriteAll(b.Code)
+				snippets = append(snippets, b.Code)
 			}
-			return m, nil
-		case "ctrl+c", "q":
+			return m, m.copyCmd(strings.Join(snippets, "\n\n"))
+		case key.Matches(msg, m.keys.FocusPane):
+			return m, m.focusPane()
+		case key.Matches(msg, m.keys.Overview):
+			return m, m.openOverview()
+		case key.Matches(msg, m.keys.Titles):
+			return m, m.openPicker()
+		case key.Matches(msg, m.keys.Find):
+			return m, m.openFinder()
+		case key.Matches(msg, m.keys.Help):
+			return m, m.openHelp()
+		case key.Matches(msg, m.keys.Quit), msg.Type == tea.KeyCtrlC:
+			m.closePane()
 			return m, tea.Quit
 		default:
-			newState := navigation.Navigate(navigation.State{
-				Buffer:      m.buffer,
-				Page:        m.Page,
-				TotalSlides: len(m.Slides),
-			}, keyPress)
-			m.buffer = newState.Buffer
-			return m, m.SetPage(newState.Page)
+			return m, m.navigate(navigation.Navigate(m.navigationState(), keyPress))
 		}
 
+	case tea.MouseMsg:
+		return m, m.updateMouse(msg)
+
+	case statusMsg:
+		m.status = string(msg)
+		return m, clearStatusCmd(m.status)
+
+	case clearStatusMsg:
+		if m.status == string(msg) {
+			m.status = ""
+		}
+		return m, nil
+
 	case fileWatchMsg:
 		newFileInfo, err := os.Stat(m.FileName)
 		if err == nil && newFileInfo.ModTime() != fileInfo.ModTime() {
 			fileInfo = newFileInfo
 			_ = m.Load()
+			// The slides which matched the search may have changed.
+			m.Search.Clear()
 			if m.Page >= len(m.Slides) {
 				m.Page = len(m.Slides) - 1
 			}
+			m.overviewSelected = min(m.overviewSelected, len(m.Slides)-1)
+			return m, tea.Batch(fileWatchCmd(), m.loadImages())
 		}
 		return m, fileWatchCmd()
 	}
 	return m, nil
 }
 
+// navigationState returns the state of the presentation which is navigated.
+func (m Model) navigationState() navigation.State {
+	return navigation.State{
+		Buffer:      m.buffer,
+		Page:        m.Page,
+		TotalSlides: len(m.Slides),
+		Step:        m.step,
+		TotalSteps:  m.steps(),
+		Sections:    m.sections,
+		Keys:        &m.keys,
+	}
+}
+
+// navigate goes to the step or the slide of the navigated state.
+func (m *Model) navigate(state navigation.State) tea.Cmd {
+	m.buffer = state.Buffer
+	if state.Page == m.Page {
+		m.step = state.Step
+		m.transitionDest = ""
+		return nil
+	}
+	cmd := m.SetPage(state.Page)
+	m.lastStep = state.LastStep
+	return cmd
+}
+
 func (m Model) GetAvailableCells() int {
 	slide, _ := m.GetSlide()
 	return m.viewport.Height - lipgloss.Height(slide)
@@ -275,19 +605,19 @@ func (m Model) GetAvailableCells() int {
 func (m Model) GetSlide() (string, bool) {
 	currSlide := m.Slides[m.Page]
 
-	if currSlide.Image != nil {
-		return currSlide.ImageStr, true
-	}
-
-	r, _ := glamour.NewTermRenderer(m.Theme, glamour.WithWordWrap(m.viewport.Width))
-	slide := currSlide.Content
+	r, _ := glamour.NewTermRenderer(m.Theme, glamour.WithWordWrap(m.viewport.Width-m.floatWidth()))
+	blocks, _ := code.Parse(currSlide.Content)
+	slide := m.annotateBlocks(currSlide.Content, blocks)
+	slide = m.markImages(slide)
 	slide = code.HideComments(slide)
-	header := ""
-	if currSlide.Header != nil {
-		header = currSlide.HeaderStr
-	}
+	header := m.slideHeader()
 	slide, err := r.Render(slide)
 	slide = strings.ReplaceAll(slide, "\t", tabSpaces)
+	slide = m.decorateBlocks(slide, blocks)
+	slide = highlightMatches(slide, m.found)
+	slide = m.highlightSearch(slide)
+	slide = m.placeImages(slide)
+	slide = m.floatImages(slide)
 	slide += m.VirtualText
 	if err != nil {
 		slide = fmt.Sprintf("Error: Could not render markdown! (%v)", err)
@@ -300,6 +630,10 @@ func (m Model) GetStatusLine() string {
 	if m.Search.Active {
 		// render search bar
 		left = m.Search.SearchTextInput.View()
+	} else if m.status != "" {
+		left = styles.Message.Render(m.status)
+	} else if m.Search.Found {
+		left = m.searchStatus()
 	} else {
 		// render author and date
 		left = styles.Author.Render(m.Author) + styles.Date.Render(m.Date)
@@ -312,147 +646,221 @@ func (m Model) GetStatusLine() string {
 // View renders the current slide in the presentation and the status bar which
 // contains the author, date, and pagination information.
 func (m Model) View() string {
+	if m.overview {
+		return m.overviewView()
+	}
+	if m.help {
+		return m.helpView()
+	}
+	if m.Picker.Active {
+		return m.Picker.View(m.viewport.Height)
+	}
+	if m.Finder.Active {
+		return m.finderView()
+	}
+	if m.transitioning() {
+		return m.transitionView()
+	}
+	f := m.renderFrame()
+	if m.frame != nil {
+		*m.frame = f
+	}
+	return f.String()
+}
+
+// screen renders the current slide and the status bar.
+func (m Model) screen() string {
+	return m.renderFrame().String()
+}
+
+// renderFrame renders the current slide and the status bar, along with
+// what the mouse is matched against.
+func (m Model) renderFrame() frame {
+	m.tagBlocks = m.Mouse
 	slide, _ := m.GetSlide()
 	// offset := 0
 	// if hasHeader {
 	// 	offset = 3
 	// }
-	// return styles.JoinVertical(
-	// 	slide,
-	// 	m.GetStatusLine(),
-	// 	m.viewport.Height-2,
-	// )
-	return slide
+
+	// The status line is only shown while it has something to say, so that
+	// it does not take space away from images.
+	screen := m.scrolled(slide)
+	if m.statusShown() {
+		screen = styles.JoinVertical(
+			screen,
+			m.GetStatusLine(),
+			m.viewport.Height,
+		)
+	}
+	f := newFrame(screen, lipgloss.Height(slide))
+	f.page, f.scroll = m.Page, m.scroll
+	return f
+}
+
+// statusShown returns whether the status line is shown below the slide.
+func (m Model) statusShown() bool {
+	return m.Search.Active || m.Search.Found || m.status != ""
 }
 
 func (m *Model) paging() string {
+	// %s is the title of the section of the slide, it is replaced after the
+	// page numbers so that the title is shown as it is.
+	paging := strings.ReplaceAll(m.Paging, "%s", "%%s")
 	switch strings.Count(m.Paging, "%d") {
 	case 2:
-		return fmt.Sprintf(m.Paging, m.Page+1, len(m.Slides))
+		paging = fmt.Sprintf(paging, m.Page+1, len(m.Slides))
 	case 1:
-		return fmt.Sprintf(m.Paging, m.Page+1)
+		paging = fmt.Sprintf(paging, m.Page+1)
 	default:
-		return m.Paging
+		paging = m.Paging
 	}
+	return strings.ReplaceAll(paging, "%s", m.section())
 }
 
-func preprocessImage(content string) (image.Image, string) {
-	re := regexp.MustCompile(`!\[(.*?)\]\((.*?)\)`)
-	// return re.ReplaceAllStringFunc(content, func(match string) string {
-	// 	return fmt.Sprintf("```img\n///%s\n```", strings.Split(match[:len(match)-1], "(")[1])
-	// })
+var (
+	fontsMu sync.Mutex
+	fonts   = map[string]*truetype.Font{}
+)
 
-	// check if there is even an image
-	if !re.MatchString(content) {
-		return nil, content
+// loadFont loads the TrueType font at path once, the embedded Fira Mono if
+// the path is empty.
+func loadFont(path string) (*truetype.Font, error) {
+	fontsMu.Lock()
+	defer fontsMu.Unlock()
+	if f, ok := fonts[path]; ok {
+		return f, nil
 	}
 
-	// find the path to the image
-	path := strings.Split(content, "(")[1]
-	path = strings.Split(path, ")")[0]
-
-	imgFile, err := os.Open(path)
-	if err != nil {
-		return nil, content
+	fontBytes := defaultFont
+	if path != "" {
+		var err error
+		fontBytes, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
 	}
-	defer imgFile.Close()
-
-	img, _, err := image.Decode(imgFile)
+	f, err := freetype.ParseFont(fontBytes)
 	if err != nil {
-		return nil, content
+		return nil, err
 	}
+	fonts[path] = f
+	return f, nil
+}
 
-	// remove the image from the content
-	content = re.ReplaceAllString(content, "")
+// headerStyle is how the text of headers is drawn, see meta.Header.
+type headerStyle struct {
+	// font is the path to the font, relative to the working directory.
+	font  string
+	rows  int
+	color string
+	bold  bool
+}
 
-	return img, content
+// headerStyle returns the style of the headers of the presentation.
+func (m *Model) headerStyle() headerStyle {
+	style := headerStyle{
+		rows:  max(m.header.Size, 1),
+		color: m.header.Color,
+		bold:  m.header.Weight == "bold",
+	}
+	if m.header.Size == 0 {
+		style.rows = defaultHeaderRows
+	}
+	if m.header.Font != "" {
+		style.font = m.resolvePath(m.header.Font)
+	}
+	return style
 }
 
-// CreateImageFromText takes a string and generates an image.Image of that text using a TrueType font
-func CreateImageFromText(text string, fontSize int) (image.Image, error) {
-	// Load the font
-	fontBytes, err := os.ReadFile("./assets/FiraMono-Regular.ttf") // Change this to the path to your TTF font file
-	if err != nil {
-		return nil, err
+// headerColor returns the color of a hex color or an ANSI color number, and
+// orange for anything else.
+func headerColor(s string) color.Color {
+	var c termenv.Color
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < 256 {
+		c = termenv.ANSI256Color(n)
+	} else if hexColorRegexp.MatchString(s) {
+		c = termenv.RGBColor(s)
+	} else {
+		return color.RGBA{R: 255, G: 170, B: 0, A: 255}
 	}
+	return termenv.ConvertToRGB(c)
+}
 
-	// Parse the font
-	f, err := freetype.ParseFont(fontBytes)
-	if err != nil {
-		return nil, err
+var hexColorRegexp = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
+
+// CreateImageFromText takes a string and generates an image.Image of that
+// text using a TrueType font, which is fontSize pixels high. The text is
+// measured by the advances of its glyphs, so that it is not clipped.
+func CreateImageFromText(text string, f *truetype.Font, fontSize int, c color.Color, bold bool) (image.Image, error) {
+	face := truetype.NewFace(f, &truetype.Options{Size: float64(fontSize), DPI: 72})
+	defer face.Close()
+
+	// Bold text is drawn several times, a pixel to the right each time.
+	strokes := 1
+	if bold {
+		strokes = max(fontSize/24, 2)
 	}
+	metrics := face.Metrics()
+	padding := fontSize / 2
+	width := font.MeasureString(face, text).Ceil() + strokes - 1 + 2*padding
+	height := (metrics.Ascent + metrics.Descent).Ceil() + fontSize/4
 
 	// Create a new RGBA image
-	img := image.NewRGBA(image.Rect(0, 0, 2*fontSize*len(text)/3, 3*fontSize/2))
-
-	draw.Draw(img, img.Bounds(), &image.Uniform{image.Transparent}, image.Point{}, draw.Src)
-
-	// Set up the context for drawing
-	c := freetype.NewContext()
-	c.SetDPI(float64(fontSize))
-	c.SetFont(f)
-	c.SetFontSize(float64(fontSize))
-	c.SetClip(img.Bounds())
-	c.SetDst(img)
-	c.SetSrc(image.NewUniform(color.RGBA{R: 255, G: 170, B: 0, A: 255}))
-
-	// Draw the text on the image
-	pt := freetype.Pt(fontSize/2, fontSize) // Starting point for the text
-	_, err = c.DrawString(text, pt)
-	if err != nil {
-		return nil, err
-	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
 
+	d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face}
+	for i := 0; i < strokes; i++ {
+		d.Dot = fixed.P(padding+i, metrics.Ascent.Ceil()+fontSize/8)
+		d.DrawString(text)
+	}
 	return img, nil
 }
 
-func getFirstHeader(content string) string {
+// getFirstHeader returns the first heading of one of the levels, and the
+// text of the heading.
+func getFirstHeader(content string, levels []int) (string, string) {
 	// split the content into lines
 	lines := strings.Split(content, "\n")
 
 	for _, line := range lines {
-		if strings.HasPrefix(line, "# ") {
-			return line
+		for _, level := range levels {
+			if level < 1 {
+				continue
+			}
+			if text, ok := strings.CutPrefix(line, strings.Repeat("#", level)+" "); ok {
+				return line, strings.TrimSpace(text)
+			}
 		}
 	}
-	return ""
+	return "", ""
 }
 
-func preprocessHeader(content string) (image.Image, string) {
-	// get the first header
-	match := getFirstHeader(content)
-
-	if match == "" {
-		return nil, content
-	}
-
-	img, err := CreateImageFromText(strings.TrimSpace(match[2:]), 72)
-	if err != nil {
-		return nil, content
+// preprocessHeader removes the first header of a slide, which is drawn as
+// an image instead. Headers which are only an image are left in place if
+// the image does not exist.
+func (m *Model) preprocessHeader(content string) (*slides.Image, string) {
+	levels := m.header.Levels
+	if levels == nil {
+		levels = []int{1}
 	}
-
-	return img, strings.Replace(content, match, "", 1)
-}
-
-func (m *Model) preprocessHeaders(content string) (string, string) {
 	// get the first header
-	match := getFirstHeader(content)
+	match, text := getFirstHeader(content, levels)
 
 	if match == "" {
-		return "", content
+		return nil, content
 	}
 
-	img, err := CreateImageFromText(strings.TrimSpace(match[2:]), 72)
-	if err != nil {
-		return "", content
+	header := slides.Image{Alt: text}
+	if ref := imageRegexp.FindStringSubmatch(text); ref != nil && ref[0] == text {
+		// A header which is only an image, such as a logo, shows the image.
+		header = parseImage(ref)
+		if _, err := os.Stat(m.resolvePath(header.Path)); err != nil {
+			return nil, content
+		}
 	}
 
-	// remove the header
-	content = strings.Replace(content, match, "", 1)
-
-	imgStr := code.RenderImage(img, m.TerminalProtocol, 4, m.viewport.Width)
-
-	return imgStr, content
+	return &header, strings.Replace(content, match, "", 1)
 }
 
 func readFile(path string) (string, error) {
@@ -528,7 +936,7 @@ var ClearScreen = tea.Sequence(tea.ClearScreen, func() tea.Msg {
 // }
 
 func isAutoExecuteLanguage(language string) bool {
-	for _, l := range []string{"qr", "img"} {
+	for _, l := range []string{"qr", "img", graphLanguage} {
 		if l == language {
 			return true
 		}
@@ -536,14 +944,52 @@ func isAutoExecuteLanguage(language string) bool {
 	return false
 }
 
+const (
+	// graphLanguage is the language of code blocks which are replaced by a
+	// diagram drawn from their mermaid source.
+	graphLanguage = "graph"
+	// chartLanguage is the language of code blocks which are replaced by a
+	// chart of their CSV or YAML data.
+	chartLanguage = "chart"
+)
+
 // SetPage sets which page the presentation should render.
 func (m *Model) SetPage(page int) tea.Cmd {
 	if m.Page == page {
 		return nil
 	}
 
+	// The screen the transition starts from is drawn without images.
+	kind := m.transitionTo(page)
+	var from string
+	if kind != "" {
+		m.transition = kind
+		from = m.screen()
+	}
+
 	m.VirtualText = ""
+	m.found = ""
+	m.scroll = 0
+	m.focus = 0
+	m.step = 0
+	m.lastStep = false
+	m.outputs = nil
+	m.tables = nil
+	m.typewriters = nil
+	m.pauseTypewriter()
+	m.morphFrame = 0
+	m.morphStarted = false
+	m.morphID++
+	m.closePane()
+	m.casts = nil
+	m.castID++
+	m.stopAnimations()
+	m.hideImages()
 	m.Page = page
+	if kind != "" {
+		return tea.Batch(ClearScreen, m.startTransition(kind, from))
+	}
+	m.sendImages()
 
 	return ClearScreen
 }
@@ -556,19 +1002,25 @@ func (m *Model) AutoExecuteCode() {
 		m.VirtualText = ""
 		return
 	}
-	var outs []string
-	for _, block := range blocks {
+	availableCells := m.GetAvailableCells()
+	for i, block := range blocks {
+		if isTableLanguage(block.Language) {
+			m.renderTable(i, block, availableCells)
+			continue
+		}
+		if block.Language == mathLanguage {
+			m.renderMath(i, block, availableCells)
+			continue
+		}
+		if block.Language == chartLanguage {
+			m.renderChart(i, block, availableCells)
+			continue
+		}
 		if isAutoExecuteLanguage(block.Language) {
-			res := code.Execute(
-				block,
-				m.TerminalProtocol,
-				m.GetAvailableCells(),
-				m.viewport.Width,
-			)
-			outs = append(outs, res.Out)
+			res := m.execute(block, availableCells)
+			m.setOutput(i, res.Out)
 		}
 	}
-	m.VirtualText = strings.TrimSpace(strings.Join(outs, "\n"))
 }
 
 // Pages returns all the slides in the presentation.
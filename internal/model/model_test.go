@@ -0,0 +1,46 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// newTestModel loads a presentation with the given content and sizes it like
+// an 80x30 terminal.
+func newTestModel(t *testing.T, content string) Model {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "slides.md")
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{FileName: name, NoTransitions: true}
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	return updated.(Model)
+}
+
+// plainLines returns the lines of a rendered slide without escape sequences.
+func plainLines(s string) []string {
+	lines := strings.Split(ansi.Strip(s), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return lines
+}
+
+// lineIndex returns the index of the first line containing substr, or -1.
+func lineIndex(lines []string, substr string) int {
+	for i, line := range lines {
+		if strings.Contains(line, substr) {
+			return i
+		}
+	}
+	return -1
+}
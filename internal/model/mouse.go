@@ -0,0 +1,208 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/internal/navigation"
+)
+
+// scrollLines is how many lines the wheel scrolls a slide by.
+const scrollLines = 3
+
+// urlRegexp matches the links on a rendered slide, glamour shows links with
+// their address and QR codes have theirs below them.
+var urlRegexp = regexp.MustCompile(`https?://[^\s<>"'()\[\]]*[^\s<>"'()\[\].,;:!?]`)
+
+// The lines of code blocks and their output are tagged with an escape
+// sequence which is not drawn, so that the block which was clicked can be
+// found on the rendered slide.
+var blockTagRegexp = regexp.MustCompile("\x1b]slidesblock;(\\d+)\a")
+
+func blockTag(i int) string {
+	return fmt.Sprintf("\x1b]slidesblock;%d\a", i)
+}
+
+// frame is a rendered screen, which is kept so that the mouse is matched
+// against the screen which was drawn without rendering the slide again.
+type frame struct {
+	// lines are the lines of the screen without the tags of the blocks.
+	lines []string
+	// blocks holds the index of the code block on each line, -1 if there is
+	// none.
+	blocks []int
+	// height is the height of the whole slide, scrolled or not.
+	height int
+	// page and scroll are those of the slide which was rendered.
+	page   int
+	scroll int
+}
+
+// newFrame splits the screen into lines and takes the tags of the blocks
+// out of them.
+func newFrame(screen string, height int) frame {
+	f := frame{lines: strings.Split(screen, "\n"), height: height}
+	f.blocks = make([]int, len(f.lines))
+	for y, line := range f.lines {
+		f.blocks[y] = -1
+		if match := blockTagRegexp.FindStringSubmatch(line); match != nil {
+			f.blocks[y], _ = strconv.Atoi(match[1])
+			f.lines[y] = blockTagRegexp.ReplaceAllString(line, "")
+		}
+	}
+	return f
+}
+
+// String returns the screen.
+func (f frame) String() string {
+	return strings.Join(f.lines, "\n")
+}
+
+// lastFrame returns the screen which was last drawn, it is only rendered
+// again if the slide changed since.
+func (m Model) lastFrame() frame {
+	if m.frame != nil && m.frame.lines != nil && m.frame.page == m.Page && m.frame.scroll == m.scroll {
+		return *m.frame
+	}
+	return m.renderFrame()
+}
+
+// updateMouse handles the mouse on the current slide. Clicking a link opens
+// it, clicking a code block focuses it, and clicking anywhere else goes to
+// the next slide, or the previous one with the right button. The wheel
+// scrolls slides which are taller than the terminal.
+func (m *Model) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.covered() || m.Search.Active || m.paneFocused || msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scroll = max(m.scroll-scrollLines, 0)
+	case tea.MouseButtonWheelDown:
+		m.scroll = min(m.scroll+scrollLines, m.maxScroll())
+	case tea.MouseButtonRight:
+		return m.navigate(navigation.Previous(m.navigationState()))
+	case tea.MouseButtonLeft:
+		if m.transitioning() {
+			return m.navigate(navigation.Next(m.navigationState()))
+		}
+		if url, ok := m.linkAt(msg.X, msg.Y); ok {
+			return m.openCmd(url)
+		}
+		if i, ok := m.blockAt(msg.Y); ok {
+			return m.focusBlock(i)
+		}
+		return m.navigate(navigation.Next(m.navigationState()))
+	}
+	return nil
+}
+
+// visibleLines returns how many lines of the slide fit above the status
+// line.
+func (m Model) visibleLines() int {
+	if m.statusShown() {
+		return m.viewport.Height - 1
+	}
+	return m.viewport.Height
+}
+
+// maxScroll returns how far the current slide scrolls, until its last line
+// is at the bottom of the terminal.
+func (m Model) maxScroll() int {
+	return max(m.lastFrame().height-m.visibleLines(), 0)
+}
+
+// scrolled returns the lines of the rendered slide which are in view. Slides
+// are only scrolled when the program reports the mouse, the terminal shows
+// the last lines of slides which are taller than it otherwise.
+func (m Model) scrolled(slide string) string {
+	height := m.visibleLines()
+	if !m.Mouse || height <= 0 {
+		return slide
+	}
+	lines := strings.Split(slide, "\n")
+	if len(lines) <= height {
+		return slide
+	}
+	start := min(m.scroll, len(lines)-height)
+	return strings.Join(lines[start:start+height], "\n")
+}
+
+// linkAt returns the address of the link at column x of line y of the
+// screen.
+func (m Model) linkAt(x, y int) (string, bool) {
+	f := m.lastFrame()
+	if y < 0 || y >= len(f.lines) {
+		return "", false
+	}
+	plain := ansi.Strip(f.lines[y])
+	for _, loc := range urlRegexp.FindAllStringIndex(plain, -1) {
+		start := ansi.StringWidth(plain[:loc[0]])
+		end := start + ansi.StringWidth(plain[loc[0]:loc[1]])
+		if x >= start && x < end {
+			return plain[loc[0]:loc[1]], true
+		}
+	}
+	return "", false
+}
+
+// blockAt returns the index of the code block at line y of the screen, or
+// whose output is there.
+func (m Model) blockAt(y int) (int, bool) {
+	f := m.lastFrame()
+	if y < 0 || y >= len(f.blocks) || f.blocks[y] < 0 {
+		return 0, false
+	}
+	return f.blocks[y], true
+}
+
+// focusBlock focuses the code block at index i, so that it is executed or
+// copied, and focuses the terminal pane if the block is one.
+func (m *Model) focusBlock(i int) tea.Cmd {
+	blocks, err := code.Parse(m.Slides[m.Page].Content)
+	if err != nil || i >= len(blocks) {
+		return nil
+	}
+	m.focus = i + 1
+	if blocks[i].Language == terminalLanguage {
+		return m.focusPane()
+	}
+	return nil
+}
+
+// openCmd opens url in the browser of the person viewing the presentation.
+// When the presentation is served with `slides serve` the browser belongs to
+// the server, so the link is copied to their clipboard instead.
+func (m Model) openCmd(url string) tea.Cmd {
+	if m.Remote || os.Getenv("SSH_TTY") != "" {
+		return m.copyCmd(url)
+	}
+	return func() tea.Msg {
+		if err := openURL(url); err != nil {
+			return statusMsg("Could not open " + url)
+		}
+		return statusMsg("Opened " + url)
+	}
+}
+
+// openURL opens url with the program the system opens links with.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Run()
+}
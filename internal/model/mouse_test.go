@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkAtBlockAt(t *testing.T) {
+	content := "# Title\n\nSee https://example.com/docs here.\n\n~~~go\npackage main\n~~~\n\n~~~go\nfunc main() {}\n~~~\n\nOutro\n"
+
+	m := newTestModel(t, content)
+	m.Mouse = true
+	m.setOutput(1, "printed")
+	view := m.View()
+	if strings.Contains(view, "slidesblock") {
+		t.Fatalf("expected the tags of the blocks to be removed:\n%s", view)
+	}
+	lines := plainLines(view)
+
+	link := lineIndex(lines, "https://example.com/docs")
+	if link < 0 {
+		t.Fatalf("expected the link on the screen:\n%s", strings.Join(lines, "\n"))
+	}
+	start := strings.Index(lines[link], "https://")
+	end := start + len("https://example.com/docs")
+
+	tt := []struct {
+		desc  string
+		x, y  int
+		url   string
+		block int
+	}{
+		{desc: "start of link", x: start, y: link, url: "https://example.com/docs", block: -1},
+		{desc: "end of link", x: end - 1, y: link, url: "https://example.com/docs", block: -1},
+		{desc: "after link", x: strings.Index(lines[link], "here"), y: link, block: -1},
+		{desc: "before link", x: start - 1, y: link, block: -1},
+		{desc: "first block", x: 4, y: lineIndex(lines, "package main"), block: 0},
+		{desc: "second block", x: 4, y: lineIndex(lines, "func main"), block: 1},
+		{desc: "output of block", x: 4, y: lineIndex(lines, "printed"), block: 1},
+		{desc: "text", x: 4, y: lineIndex(lines, "Outro"), block: -1},
+		{desc: "above screen", x: 0, y: -1, block: -1},
+		{desc: "below screen", x: 0, y: len(lines), block: -1},
+	}
+
+	for _, tc := range tt {
+		url, ok := m.linkAt(tc.x, tc.y)
+		if ok != (tc.url != "") || url != tc.url {
+			t.Errorf("%s: expected link %q, got %q", tc.desc, tc.url, url)
+		}
+		i, ok := m.blockAt(tc.y)
+		if !ok {
+			i = -1
+		}
+		if i != tc.block {
+			t.Errorf("%s: expected block %d, got %d", tc.desc, tc.block, i)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/styles"
+)
+
+const (
+	// overviewCardWidth is the narrowest a card of the overview is drawn,
+	// there are as many columns of cards as fit in the window.
+	overviewCardWidth = 24
+	// overviewCardLines is the number of lines inside a card, the title and
+	// the first lines of the slide.
+	overviewCardLines = 4
+	overviewGap       = 2
+)
+
+var allHeaderLevels = []int{1, 2, 3, 4, 5, 6}
+
+// openOverview shows the grid of slides instead of the current slide, with
+// the current slide selected.
+func (m *Model) openOverview() tea.Cmd {
+	m.overview = true
+	m.overviewSelected = m.Page
+	m.pauseTypewriter()
+	m.stopAnimations()
+	m.hideImages()
+	return tea.ClearScreen
+}
+
+// covered returns whether the current slide is covered by the overview, the
+// help, the picker or the finder, Kitty is not sent its images meanwhile.
+func (m Model) covered() bool {
+	return m.overview || m.help || m.Picker.Active || m.Finder.Active
+}
+
+// uncover draws the current slide again after it was covered.
+func (m *Model) uncover() tea.Cmd {
+	m.sendImages()
+	return tea.Batch(tea.ClearScreen, m.startAnimations())
+}
+
+// updateOverview handles the key presses while the overview is shown.
+func (m *Model) updateOverview(msg tea.KeyMsg) tea.Cmd {
+	cols, _ := m.overviewGrid()
+	last := len(m.Slides) - 1
+	if key.Matches(msg, m.keys.Overview) {
+		m.overview = false
+		return m.uncover()
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc", "q":
+		m.overview = false
+		return m.uncover()
+	case "enter", " ":
+		m.overview = false
+		if m.overviewSelected == m.Page {
+			return m.uncover()
+		}
+		return m.SetPage(m.overviewSelected)
+	case "left", "h":
+		m.overviewSelected--
+	case "right", "l":
+		m.overviewSelected++
+	case "up", "k":
+		if m.overviewSelected-cols >= 0 {
+			m.overviewSelected -= cols
+		}
+	case "down", "j":
+		if m.overviewSelected+cols <= last {
+			m.overviewSelected += cols
+		}
+	case "home", "g":
+		m.overviewSelected = 0
+	case "end", "G":
+		m.overviewSelected = last
+	}
+	m.overviewSelected = min(max(m.overviewSelected, 0), last)
+	return nil
+}
+
+// overviewGrid returns the number of columns and rows of cards which fit in
+// the window.
+func (m Model) overviewGrid() (int, int) {
+	width := m.viewport.Width - 2*overviewGap
+	cols := max((width+overviewGap)/(overviewCardWidth+2+overviewGap), 1)
+	// One line is left for the status line.
+	rows := max((m.viewport.Height-1)/(overviewCardLines+2), 1)
+	return cols, rows
+}
+
+// overviewView draws the cards of the slides in a grid, scrolled so that
+// the selected card is visible.
+func (m Model) overviewView() string {
+	cols, rows := m.overviewGrid()
+	width := (m.viewport.Width-2*overviewGap+overviewGap)/cols - overviewGap - 2
+	width = max(width, 3)
+
+	first := 0
+	if row := m.overviewSelected / cols; row >= rows {
+		first = (row - rows + 1) * cols
+	}
+
+	var lines []string
+	for start := first; start < len(m.Slides) && start < first+rows*cols; start += cols {
+		var cards []string
+		for i := start; i < min(start+cols, len(m.Slides)); i++ {
+			if len(cards) > 0 {
+				cards = append(cards, strings.Repeat(" ", overviewGap))
+			}
+			cards = append(cards, m.overviewCard(i, width))
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, cards...))
+	}
+	grid := lipgloss.NewStyle().MarginLeft(overviewGap).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+
+	page := styles.Page.Render(fmt.Sprintf("Slide %d / %d", m.overviewSelected+1, len(m.Slides)))
+	help := styles.Search.Render("←↓↑→ select • enter go to slide • esc close")
+	if lipgloss.Width(help)+lipgloss.Width(page) > m.viewport.Width {
+		help = ""
+	}
+	status := styles.JoinHorizontal(help, page, m.viewport.Width)
+	return lipgloss.PlaceVertical(m.viewport.Height-1, lipgloss.Top, grid) + "\n" + status
+}
+
+// overviewCard draws the card of a slide, its number and title followed by
+// the first lines of its text.
+func (m Model) overviewCard(page, width int) string {
+	style, title := styles.OverviewCard, styles.OverviewTitle
+	if page == m.overviewSelected {
+		style, title = styles.OverviewSelected, styles.OverviewSelectedTitle
+	}
+
+	// The width of the card includes its padding.
+	inner := width - 2
+	number := fmt.Sprintf("%d ", page+1)
+	heading, preview := m.slideSummary(page)
+	lines := []string{
+		styles.Dim.Render(number) + title.Render(ansi.Truncate(heading, inner-len(number), "…")),
+	}
+	for _, line := range preview {
+		if len(lines) == overviewCardLines {
+			break
+		}
+		lines = append(lines, styles.Dim.Render(ansi.Truncate(line, inner, "…")))
+	}
+	for len(lines) < overviewCardLines {
+		lines = append(lines, "")
+	}
+	return style.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// slideSummary returns the title of a slide, its header or else its first
+// heading, and its other lines of text without markdown syntax.
+func (m Model) slideSummary(page int) (string, []string) {
+	slide := m.Slides[page]
+	var title string
+	if slide.Header != nil {
+		title = slide.Header.Alt
+		if title == "" {
+			title = slide.Header.Path
+		}
+	}
+	heading, text := getFirstHeader(slide.Content, allHeaderLevels)
+	if title == "" {
+		title = text
+	} else {
+		// The heading is part of the text below the header.
+		heading = ""
+	}
+
+	var preview []string
+	fenced := false
+	for _, line := range strings.Split(slide.Content, "\n") {
+		trimmed := strings.TrimSpace(strings.ReplaceAll(line, "\t", "  "))
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if line == heading && heading != "" {
+			heading = ""
+			continue
+		}
+		trimmed = imageRegexp.ReplaceAllString(trimmed, "")
+		if !fenced {
+			trimmed = strings.TrimLeft(trimmed, "#>*-+| ")
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "<!--") {
+			continue
+		}
+		preview = append(preview, trimmed)
+	}
+	if title == "" && len(preview) > 0 {
+		title, preview = preview[0], preview[1:]
+	}
+	return title, preview
+}
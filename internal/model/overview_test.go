@@ -0,0 +1,173 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/slides"
+)
+
+func TestOverviewGrid(t *testing.T) {
+	tt := []struct {
+		width, height int
+		cols, rows    int
+	}{
+		{80, 30, 2, 4},
+		{120, 40, 4, 6},
+		{58, 7, 2, 1},
+		{57, 6, 1, 1},
+		{10, 3, 1, 1},
+	}
+
+	for _, tc := range tt {
+		m := Model{viewport: viewport.New(tc.width, tc.height)}
+		cols, rows := m.overviewGrid()
+		if cols != tc.cols || rows != tc.rows {
+			t.Errorf("%dx%d: expected %dx%d cards, got %dx%d", tc.width, tc.height, tc.cols, tc.rows, cols, rows)
+		}
+	}
+}
+
+// keyMsg returns the message of pressing the key with the given name.
+func keyMsg(name string) tea.KeyMsg {
+	switch name {
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "home":
+		return tea.KeyMsg{Type: tea.KeyHome}
+	case "end":
+		return tea.KeyMsg{Type: tea.KeyEnd}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+func TestUpdateOverview(t *testing.T) {
+	// Seven slides in two columns:
+	//
+	//	0 1
+	//	2 3
+	//	4 5
+	//	6
+	m := newTestModel(t, "---\nauthor: me\n---\n"+strings.Repeat("# Slide\n\n---\n\n", 6)+"# Slide\n")
+	if len(m.Slides) != 7 {
+		t.Fatalf("expected 7 slides, got %d", len(m.Slides))
+	}
+	m.openOverview()
+
+	tt := []struct {
+		key      string
+		selected int
+	}{
+		{"right", 1},
+		{"l", 2},
+		{"down", 4},
+		{"j", 6},
+		{"down", 6},
+		{"right", 6},
+		{"up", 4},
+		{"k", 2},
+		{"up", 0},
+		{"up", 0},
+		{"left", 0},
+		{"end", 6},
+		{"G", 6},
+		{"h", 5},
+		{"down", 5},
+		{"home", 0},
+		{"g", 0},
+	}
+
+	for _, tc := range tt {
+		m.updateOverview(keyMsg(tc.key))
+		if m.overviewSelected != tc.selected {
+			t.Errorf("%s: expected slide %d to be selected, got %d", tc.key, tc.selected, m.overviewSelected)
+		}
+		if !m.overview {
+			t.Fatalf("%s: expected the overview to stay open", tc.key)
+		}
+	}
+
+	m.overviewSelected = 3
+	m.updateOverview(keyMsg("enter"))
+	if m.overview || m.Page != 3 {
+		t.Errorf("expected enter to go to slide 3 and close the overview, got slide %d", m.Page)
+	}
+
+	m.openOverview()
+	m.overviewSelected = 5
+	m.updateOverview(keyMsg("esc"))
+	if m.overview || m.Page != 3 {
+		t.Errorf("expected esc to close the overview on slide 3, got slide %d", m.Page)
+	}
+}
+
+func TestSlideSummary(t *testing.T) {
+	tt := []struct {
+		desc    string
+		slide   slides.Slide
+		title   string
+		preview []string
+	}{
+		{
+			desc:    "heading",
+			slide:   slides.Slide{Content: "# Title\n\nSome text\n\n* a list item"},
+			title:   "Title",
+			preview: []string{"Some text", "a list item"},
+		},
+		{
+			desc:    "header image",
+			slide:   slides.Slide{Header: &slides.Image{Alt: "Header"}, Content: "## Subtitle\n\nText"},
+			title:   "Header",
+			preview: []string{"Subtitle", "Text"},
+		},
+		{
+			desc:    "header image without alt text",
+			slide:   slides.Slide{Header: &slides.Image{Path: "header.png"}, Content: "Text"},
+			title:   "header.png",
+			preview: []string{"Text"},
+		},
+		{
+			desc:    "no heading",
+			slide:   slides.Slide{Content: "First line\nSecond line"},
+			title:   "First line",
+			preview: []string{"Second line"},
+		},
+		{
+			desc:    "code keeps its syntax",
+			slide:   slides.Slide{Content: "# Code\n\n```go\n// comment\n```"},
+			title:   "Code",
+			preview: []string{"// comment"},
+		},
+		{
+			desc:    "images and comments are left out",
+			slide:   slides.Slide{Content: "# Images\n\n![alt](image.png)\n<!-- notes -->\n> quote"},
+			title:   "Images",
+			preview: []string{"quote"},
+		},
+		{
+			desc:  "empty",
+			slide: slides.Slide{},
+		},
+	}
+
+	for _, tc := range tt {
+		m := Model{Slides: []slides.Slide{tc.slide}}
+		title, preview := m.slideSummary(0)
+		if title != tc.title || !reflect.DeepEqual(preview, tc.preview) {
+			t.Errorf("%s: expected %q %q, got %q %q", tc.desc, tc.title, tc.preview, title, preview)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/navigation"
+	"github.com/maaslalani/slides/internal/slides"
+)
+
+// tocRegexp matches the directive which is replaced by the agenda of the
+// presentation.
+var tocRegexp = regexp.MustCompile(`<!--\s*toc\s*-->`)
+
+// parseSections returns the sections of the slides. Sections start at
+// slides with a section directive or, in presentations without any, at
+// slides with a level one heading. The agenda is not a section of its own,
+// and slides which carry on the section before do not start another one.
+func parseSections(contents []string) []navigation.Section {
+	titles := make([]string, len(contents))
+	directives := false
+	for i, content := range contents {
+		if title, ok := (slides.Slide{Content: content}).Directive("section"); ok {
+			titles[i] = title
+			directives = true
+		}
+	}
+	if !directives {
+		for i, content := range contents {
+			if tocRegexp.MatchString(content) {
+				continue
+			}
+			_, text := getFirstHeader(content, []int{1})
+			if ref := imageRegexp.FindString(text); ref != "" && ref == text {
+				// Logos are not the titles of sections.
+				continue
+			}
+			titles[i] = text
+		}
+	}
+
+	var sections []navigation.Section
+	for page, title := range titles {
+		if title == "" || (len(sections) > 0 && sections[len(sections)-1].Title == title) {
+			continue
+		}
+		sections = append(sections, navigation.Section{Title: title, Page: page})
+	}
+	return sections
+}
+
+// preprocessTOC replaces the toc directive with the agenda of the
+// presentation, a list of its sections and the slides they start at.
+func preprocessTOC(content string, sections []navigation.Section) string {
+	if !tocRegexp.MatchString(content) {
+		return content
+	}
+	var b strings.Builder
+	for i, s := range sections {
+		fmt.Fprintf(&b, "%d. %s *(slide %d)*\n", i+1, s.Title, s.Page+1)
+	}
+	return tocRegexp.ReplaceAllLiteralString(content, strings.TrimSuffix(b.String(), "\n"))
+}
+
+// section returns the title of the section the current slide is in.
+func (m Model) section() string {
+	if i := navigation.SectionAt(m.sections, m.Page); i >= 0 {
+		return m.sections[i].Title
+	}
+	return ""
+}
+
+// openPicker shows the picker for going to a slide by its title.
+func (m *Model) openPicker() tea.Cmd {
+	titles := make([]string, len(m.Slides))
+	for page := range m.Slides {
+		titles[page], _ = m.slideSummary(page)
+	}
+	m.stopAnimations()
+	m.hideImages()
+	return tea.Batch(tea.ClearScreen, m.Picker.Begin(titles))
+}
+
+// updatePicker handles the key presses while the picker is shown, enter goes
+// to the selected slide.
+func (m *Model) updatePicker(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.Picker.Done()
+		page, ok := m.Picker.Selection()
+		if !ok || page == m.Page {
+			return m.uncover()
+		}
+		return m.SetPage(page)
+	case tea.KeyCtrlC, tea.KeyEscape:
+		m.Picker.Done()
+		return m.uncover()
+	}
+	return m.Picker.Update(msg)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/maaslalani/slides/internal/navigation"
+	"github.com/maaslalani/slides/internal/slides"
+)
+
+func TestPaging(t *testing.T) {
+	tt := []struct {
+		paging   string
+		section  string
+		expected string
+	}{
+		{"Slide %d / %d", "Intro", "Slide 2 / 3"},
+		{"%d", "Intro", "2"},
+		{"Slides", "Intro", "Slides"},
+		{"%s · %d / %d", "Intro", "Intro · 2 / 3"},
+		{"%s", "Intro", "Intro"},
+		{"%s · %d", "100%d done", "100%d done · 2"},
+		{"%s · %d / %d", "50% off %s", "50% off %s · 2 / 3"},
+		{"%s · %d / %d", "", " · 2 / 3"},
+	}
+
+	for _, tc := range tt {
+		m := Model{
+			Slides: make([]slides.Slide, 3),
+			Page:   1,
+			Paging: tc.paging,
+		}
+		if tc.section != "" {
+			m.sections = []navigation.Section{{Title: tc.section, Page: 0}}
+		}
+		if got := m.paging(); got != tc.expected {
+			t.Errorf("%q with section %q: expected %q, got %q", tc.paging, tc.section, tc.expected, got)
+		}
+	}
+}
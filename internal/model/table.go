@@ -0,0 +1,78 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/internal/table"
+	"github.com/maaslalani/slides/styles"
+)
+
+// includeTableLanguage is the language of code blocks which are replaced by a
+// table of the CSV or TSV file at the path in the block. The code of csv and
+// tsv blocks is the data of the table itself.
+//
+//	```include-table {align=lrr decimals=2}
+//	benchmarks.csv
+//	```
+const includeTableLanguage = "include-table"
+
+func isTableLanguage(language string) bool {
+	switch language {
+	case "csv", "tsv", includeTableLanguage:
+		return true
+	}
+	return false
+}
+
+// renderTable renders the table of the block at index i into pages which fit
+// the available space, the pages are stepped through like highlighted lines.
+func (m *Model) renderTable(i int, block code.Block, availableCells int) {
+	src, comma := block.Code, ','
+	if block.Language == "tsv" {
+		comma = '\t'
+	}
+	if block.Language == includeTableLanguage {
+		path := strings.TrimSpace(block.Code)
+		data, err := os.ReadFile(m.resolvePath(path))
+		if err != nil {
+			m.setOutput(i, "Error: could not read table: "+err.Error())
+			return
+		}
+		src = string(data)
+		if strings.EqualFold(filepath.Ext(path), ".tsv") {
+			comma = '\t'
+		}
+	}
+
+	records, err := table.Parse(src, comma)
+	if err != nil {
+		m.setOutput(i, "Error: could not parse table: "+err.Error())
+		return
+	}
+
+	opts := table.Options{
+		Decimals: -1,
+		Width:    styles.SlideWidth(m.viewport.Width),
+		Height:   availableCells,
+	}
+	opts.Align, _ = block.Option("align")
+	if value, ok := block.Option("decimals"); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			opts.Decimals = n
+		}
+	}
+	if value, ok := block.Option("rows"); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			opts.Rows = n
+		}
+	}
+
+	if m.tables == nil {
+		m.tables = make(map[int][]string)
+	}
+	m.tables[i] = table.Render(records, opts)
+}
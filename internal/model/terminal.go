@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/code"
+	"github.com/maaslalani/slides/internal/pane"
+	"github.com/maaslalani/slides/styles"
+)
+
+// terminalLanguage is the language of code blocks which are replaced by an
+// interactive terminal pane. The code of the block is the command to run in
+// the pane, the user's shell is started if it is empty.
+//
+//	```terminal {rows=10}
+//	htop
+//	```
+const terminalLanguage = "terminal"
+
+const defaultPaneRows = 12
+
+// terminalBlock returns the first terminal block on the current slide, only
+// one pane is run per slide.
+func (m Model) terminalBlock() (int, code.Block, bool) {
+	blocks, _ := code.Parse(m.Slides[m.Page].Content)
+	for i, b := range blocks {
+		if b.Language == terminalLanguage {
+			return i, b, true
+		}
+	}
+	return 0, code.Block{}, false
+}
+
+// paneSize returns the size of the terminal inside the pane, which spans the
+// width of the slide.
+func (m Model) paneSize(block code.Block) (int, int) {
+	rows := defaultPaneRows
+	if value, ok := block.Option("rows"); ok {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			rows = n
+		}
+	}
+	cols := max(styles.SlideWidth(m.viewport.Width), 10)
+	return cols, rows
+}
+
+func (m Model) context() context.Context {
+	if m.Context == nil {
+		return context.Background()
+	}
+	return m.Context
+}
+
+// startPane starts the terminal pane of the current slide, if it has one. It
+// is only started once the pane is focused, so that showing a slide never runs
+// a command.
+func (m *Model) startPane() tea.Cmd {
+	if m.pane != nil {
+		return nil
+	}
+	i, block, ok := m.terminalBlock()
+	if !ok {
+		return nil
+	}
+	// Anyone who can connect to `slides serve` would get a shell on the
+	// server, unless the presenter allows it.
+	if m.Remote && !m.AllowTerminal {
+		m.setOutput(i, "Terminals are turned off over SSH, start slides serve with --allow-terminal to run them.")
+		return nil
+	}
+
+	cols, rows := m.paneSize(block)
+	p, err := pane.Start(m.context(), strings.TrimSpace(block.Code), cols, rows)
+	if err != nil {
+		m.setOutput(i, "Error: could not start terminal: "+err.Error())
+		return nil
+	}
+	m.pane = p
+	return p.Wait()
+}
+
+// closePane stops the command running in the terminal pane.
+func (m *Model) closePane() {
+	if m.pane != nil {
+		m.pane.Close()
+		m.pane = nil
+	}
+	m.paneFocused = false
+}
+
+// focusPane starts the terminal pane and sends key presses to it until the
+// key which leaves it, ctrl+] by default, is pressed. A pane whose command has
+// exited is restarted.
+func (m *Model) focusPane() tea.Cmd {
+	var cmd tea.Cmd
+	if m.pane != nil && m.pane.Exited() {
+		m.closePane()
+	}
+	if m.pane == nil {
+		cmd = m.startPane()
+	}
+	m.paneFocused = m.pane != nil
+	return cmd
+}
+
+// blockOutput returns what is displayed below the code block at index i: the
+// terminal pane for the terminal block, the playback of a recording, the
+// current page of a table, or the output of the executed block.
+func (m Model) blockOutput(i int) string {
+	if j, _, ok := m.terminalBlock(); ok && i == j && m.pane != nil {
+		style := styles.Pane
+		if m.paneFocused {
+			style = styles.PaneFocused
+		}
+		return style.Render(m.pane.View(m.paneFocused))
+	}
+	if j, _, ok := m.terminalBlock(); ok && i == j {
+		if out, ok := m.outputs[i]; ok {
+			return out
+		}
+		return styles.Pane.Render("Press " + m.keys.FocusPane.Help().Key + " to start the terminal")
+	}
+	if p, ok := m.casts[i]; ok {
+		return m.castView(p)
+	}
+	if pages, ok := m.tables[i]; ok {
+		blocks, _ := code.Parse(m.Slides[m.Page].Content)
+		return pages[m.blockStep(blocks, i)]
+	}
+	return m.outputs[i]
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerminalStartsWhenFocused(t *testing.T) {
+	m := newTestModel(t, "# Terminal\n\n~~~terminal\necho started\n~~~\n")
+	updated, _ := m.Update(autoExecuteCodeMsg{})
+	m = updated.(Model)
+	defer m.closePane()
+
+	if m.pane != nil {
+		t.Fatal("expected the terminal not to start when the slide is shown")
+	}
+	if view := strings.Join(plainLines(m.View()), "\n"); !strings.Contains(view, "Press i to start the terminal") {
+		t.Errorf("expected a hint to start the terminal:\n%s", view)
+	}
+
+	m.focusPane()
+	if m.pane == nil || !m.paneFocused {
+		t.Fatal("expected focusing the terminal to start it")
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/transition"
+)
+
+const (
+	transitionDuration = 400 * time.Millisecond
+	// transitionFrame is the time between two frames of a transition, over
+	// SSH every frame is sent through the network, so fewer of them are
+	// drawn.
+	transitionFrame       = 33 * time.Millisecond
+	remoteTransitionFrame = 100 * time.Millisecond
+)
+
+type transitionMsg struct {
+	id int
+}
+
+// transitionTo returns the transition to a slide, which is set by its
+// transition directive or else by the front matter.
+func (m Model) transitionTo(page int) string {
+	if m.NoTransitions || page < 0 || page >= len(m.Slides) {
+		return ""
+	}
+	kind, ok := m.Slides[page].Directive("transition")
+	if !ok {
+		kind = m.Transition
+	}
+	if kind == transition.None || !transition.Valid(kind) {
+		return ""
+	}
+	return kind
+}
+
+// transitioning returns whether a transition between slides is playing.
+// Images are left out of the frames of transitions, and Kitty is sent the
+// images of the slide when it is over.
+func (m Model) transitioning() bool {
+	return m.transition != ""
+}
+
+// startTransition plays a transition from the screen which was shown before
+// the page changed to the current slide.
+func (m *Model) startTransition(kind, from string) tea.Cmd {
+	m.transition = kind
+	m.transitionFrom = from
+	m.transitionDest = ""
+	m.transitionStart = time.Now()
+	m.transitionProgress = 0
+	m.transitionID++
+	return m.transitionTick()
+}
+
+func (m Model) transitionTick() tea.Cmd {
+	frame := transitionFrame
+	if m.Remote {
+		frame = remoteTransitionFrame
+	}
+	id := m.transitionID
+	return tea.Tick(frame, func(time.Time) tea.Msg {
+		return transitionMsg{id: id}
+	})
+}
+
+// stepTransition moves the transition to its next frame, or ends it.
+func (m *Model) stepTransition() tea.Cmd {
+	m.transitionProgress = float64(time.Since(m.transitionStart)) / float64(transitionDuration)
+	if m.transitionProgress < 1 {
+		if m.transitionDest == "" {
+			m.transitionDest = m.screen()
+		}
+		return m.transitionTick()
+	}
+	m.transition = ""
+	m.transitionFrom = ""
+	m.transitionDest = ""
+	m.sendImages()
+	// The frames of the transition are drawn over the images, the screen
+	// is cleared so that the images are drawn again.
+	return tea.ClearScreen
+}
+
+// transitionView returns the current frame of the transition.
+func (m Model) transitionView() string {
+	dest := m.transitionDest
+	if dest == "" {
+		dest = m.screen()
+	}
+	return transition.Frame(m.transition, m.transitionFrom, dest, m.viewport.Width, m.viewport.Height, m.transitionProgress)
+}
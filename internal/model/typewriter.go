@@ -0,0 +1,189 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/code"
+)
+
+const (
+	typewriterCharDelay = 35 * time.Millisecond
+	typewriterLineDelay = 400 * time.Millisecond
+)
+
+// typewriter tracks how much of a code block has been typed out so far.
+// Blocks type themselves one character (or one line) at a time when they
+// have the {typewriter} option or the slide has a <!-- typewriter -->
+// directive; the value `line` types a line at a time.
+type typewriter struct {
+	shown  int
+	total  int
+	byLine bool
+}
+
+// typewriterMsg advances the typewriter animation on the current slide. Ticks
+// from an earlier animation are told apart by their id and ignored.
+type typewriterMsg struct {
+	id int
+}
+
+func (t typewriter) done() bool {
+	return t.shown >= t.total
+}
+
+// advance reveals the next character, or the rest of the current line when
+// typing line by line. Whitespace is revealed instantly, so indentation does
+// not slow the animation down.
+func (t typewriter) advance(body []rune, byLine bool) typewriter {
+	if byLine {
+		for t.shown < t.total && body[t.shown] != '\n' {
+			t.shown++
+		}
+	}
+	t.shown = min(t.shown+1, t.total)
+	for t.shown < t.total && unicode.IsSpace(body[t.shown]) {
+		t.shown++
+	}
+	return t
+}
+
+// splitBlock splits the markdown of a code block into the opening fence, the
+// code as it is displayed (without hidden comments) and the closing fence.
+func splitBlock(markdown string) (string, string, string, bool) {
+	open, rest, ok := strings.Cut(code.HideComments(markdown), "\n")
+	if !ok {
+		return "", "", "", false
+	}
+	end := max(strings.LastIndex(rest, "\n```"), strings.LastIndex(rest, "\n~~~"))
+	if end < 0 {
+		return "", "", "", false
+	}
+	return open, rest[:end], rest[end:], true
+}
+
+// reveal returns the markdown of a code block with only the typed out part of
+// the code. The rest is replaced by empty lines so that the block keeps its
+// final size while it is being typed.
+func (t typewriter) reveal(markdown string) string {
+	open, body, close, ok := splitBlock(markdown)
+	if !ok {
+		return markdown
+	}
+	shown := string([]rune(body)[:t.shown])
+	padding := strings.Repeat("\n", strings.Count(body, "\n")-strings.Count(shown, "\n"))
+	return open + "\n" + shown + padding + close
+}
+
+// typewriterMode returns whether the block should be typed out and whether it
+// is typed line by line.
+func (m Model) typewriterMode(block code.Block) (bool, bool) {
+	if isAutoExecuteLanguage(block.Language) || isElementLanguage(block.Language) {
+		return false, false
+	}
+	if isDiff, _ := diffMode(block); isDiff {
+		return false, false
+	}
+	mode, ok := block.Option("typewriter")
+	if !ok {
+		mode, ok = m.Slides[m.Page].Directive("typewriter")
+	}
+	return ok, mode == "line"
+}
+
+// startTypewriter begins typing out the code blocks of the current slide,
+// unless they are already being typed.
+func (m *Model) startTypewriter() tea.Cmd {
+	if m.typewriters != nil {
+		return nil
+	}
+
+	content := m.Slides[m.Page].Content
+	blocks, err := code.Parse(content)
+	if err != nil {
+		return nil
+	}
+
+	for i, b := range blocks {
+		ok, byLine := m.typewriterMode(b)
+		if !ok {
+			continue
+		}
+		_, body, _, ok := splitBlock(content[b.Start:b.End])
+		if !ok {
+			continue
+		}
+		runes := []rune(body)
+		t := typewriter{total: len(runes), byLine: byLine}
+		// Start with the first visible character so that the block is never
+		// empty, empty blocks are removed when rendering.
+		for t.shown < t.total && unicode.IsSpace(runes[t.shown]) {
+			t.shown++
+		}
+		if m.typewriters == nil {
+			m.typewriters = make(map[int]typewriter)
+		}
+		m.typewriters[i] = t.advance(runes, byLine)
+	}
+
+	m.typewriterID++
+	return m.typewriterTick()
+}
+
+// typing returns the block currently being typed, blocks are typed one after
+// the other.
+func (m Model) typing() (int, typewriter, bool) {
+	blocks, err := code.Parse(m.Slides[m.Page].Content)
+	if err != nil {
+		return 0, typewriter{}, false
+	}
+	for i := range blocks {
+		if t, ok := m.typewriters[i]; ok && !t.done() {
+			return i, t, true
+		}
+	}
+	return 0, typewriter{}, false
+}
+
+func (m Model) typewriterTick() tea.Cmd {
+	_, t, ok := m.typing()
+	if !ok {
+		return nil
+	}
+	delay := typewriterCharDelay
+	if t.byLine {
+		delay = typewriterLineDelay
+	}
+	id := m.typewriterID
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return typewriterMsg{id: id}
+	})
+}
+
+// stepTypewriter types the next character of the block currently being
+// typed, or the rest of its current line if byLine is set.
+func (m *Model) stepTypewriter(byLine bool) {
+	i, t, ok := m.typing()
+	if !ok {
+		return
+	}
+	b, _ := code.Parse(m.Slides[m.Page].Content)
+	_, body, _, _ := splitBlock(m.Slides[m.Page].Content[b[i].Start:b[i].End])
+	m.typewriters[i] = t.advance([]rune(body), byLine)
+}
+
+// pauseTypewriter stops the automatic typing, pending ticks are ignored.
+func (m *Model) pauseTypewriter() {
+	m.typewriterID++
+}
+
+// finishTypewriter reveals all code blocks on the current slide.
+func (m *Model) finishTypewriter() {
+	m.pauseTypewriter()
+	for i, t := range m.typewriters {
+		t.shown = t.total
+		m.typewriters[i] = t
+	}
+}
@@ -0,0 +1,130 @@
+package navigation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/styles"
+)
+
+// FinderRows is the number of results the finder lists at once.
+const FinderRows = 6
+
+// Finder finds the slides whose text matches a query fuzzily, and lists
+// them with the line which matched best.
+type Finder struct {
+	listInput
+	// pages are the lines of text of the slides by page.
+	pages   [][]string
+	results []Result
+}
+
+// Result is a slide which matched the query of the finder.
+type Result struct {
+	Page int
+	// Match is the line of the slide which matched best.
+	Match
+}
+
+// NewFinder creates and returns a new finder with the default settings.
+func NewFinder() Finder {
+	return Finder{listInput: newListInput("find slides")}
+}
+
+// Begin shows the finder for the slides with the given lines of text.
+func (f *Finder) Begin(pages [][]string) tea.Cmd {
+	f.pages = pages
+	f.results = nil
+	return f.begin()
+}
+
+// Selection returns the selected result, false if no slide matches.
+func (f *Finder) Selection() (Result, bool) {
+	if f.selected >= len(f.results) {
+		return Result{}, false
+	}
+	return f.results[f.selected], true
+}
+
+// Update moves the selection or updates the query.
+func (f *Finder) Update(msg tea.KeyMsg) tea.Cmd {
+	cmd, changed := f.update(msg, len(f.results))
+	if changed {
+		f.results = Find(f.Query(), f.pages)
+	}
+	return cmd
+}
+
+// Find returns the slides which have a line that matches query fuzzily,
+// the best matches first. Every slide is listed once, with its best line.
+func Find(query string, pages [][]string) []Result {
+	if query == "" {
+		return nil
+	}
+	var results []Result
+	for page, lines := range pages {
+		matches := FuzzyFind(query, lines)
+		if len(matches) > 0 {
+			results = append(results, Result{Page: page, Match: matches[0]})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// View renders the query and the results around the selected one, each
+// with its line cut to the width around the matched characters.
+func (f Finder) View(width int) string {
+	lines := []string{f.Input.View()}
+	first := max(f.selected-FinderRows+1, 0)
+	for i := first; i < len(f.results) && i < first+FinderRows; i++ {
+		r := f.results[i]
+		marker := "  "
+		style := styles.PickerItem
+		if i == f.selected {
+			marker = styles.PickerSelected.Render("› ")
+			style = styles.PickerSelected
+		}
+		number := fmt.Sprintf("%3d  ", r.Page+1)
+		context := matchContext(r.Match, width-ansi.StringWidth(number)-6)
+		lines = append(lines, "  "+marker+styles.Dim.Render(number)+highlight(context, style))
+	}
+	switch {
+	case f.Query() != "" && len(f.results) == 0:
+		lines = append(lines, styles.Search.Render("no matching slides"))
+	case len(f.results) > 0:
+		lines = append(lines, styles.Search.Render(fmt.Sprintf("%d/%d", f.selected+1, len(f.results))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchContext cuts the line of a match to about width runes, keeping the
+// start of the match in view.
+func matchContext(m Match, width int) Match {
+	runes := []rune(m.Str)
+	if len(runes) <= width || width <= 1 || len(m.Positions) == 0 {
+		return m
+	}
+	// A few runes before the match are kept to show what it is part of.
+	start := min(max(m.Positions[0]-width/4, 0), len(runes)-width)
+	end := min(start+width, len(runes))
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+	}
+	if end < len(runes) {
+		suffix = "…"
+	}
+	cut := Match{Index: m.Index, Score: m.Score, Str: prefix + string(runes[start:end]) + suffix}
+	for _, p := range m.Positions {
+		if p >= start && p < end {
+			cut.Positions = append(cut.Positions, p-start+len([]rune(prefix)))
+		}
+	}
+	return cut
+}
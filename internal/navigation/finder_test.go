@@ -0,0 +1,52 @@
+package navigation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	deck := [][]string{
+		{"# Welcome", "slides in the terminal"},
+		{"# Installation", "go install github.com/maaslalani/slides"},
+		{"# Usage", "slides presentation.md"},
+		{"# Questions"},
+	}
+
+	tests := []struct {
+		query string
+		pages []int
+		lines []string
+	}{
+		{query: ""},
+		{query: "zzz"},
+		{query: "install", pages: []int{1}, lines: []string{"# Installation"}},
+		{query: "slides", pages: []int{0, 2, 1}, lines: []string{"slides in the terminal", "slides presentation.md", "go install github.com/maaslalani/slides"}},
+		{query: "ques", pages: []int{3}, lines: []string{"# Questions"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var pages []int
+			var lines []string
+			for _, r := range Find(tt.query, deck) {
+				pages = append(pages, r.Page)
+				lines = append(lines, r.Str)
+			}
+			assert.Equal(t, tt.pages, pages)
+			assert.Equal(t, tt.lines, lines)
+		})
+	}
+}
+
+func TestMatchContext(t *testing.T) {
+	m, ok := FuzzyMatch("needle", "a very long line of text with a needle somewhere near its end")
+	assert.True(t, ok)
+
+	cut := matchContext(m, 20)
+	assert.Equal(t, "…th a needle somewher…", cut.Str)
+	assert.Equal(t, []int{6, 7, 8, 9, 10, 11}, cut.Positions)
+
+	assert.Equal(t, m, matchContext(m, 100))
+}
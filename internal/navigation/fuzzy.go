@@ -0,0 +1,129 @@
+package navigation
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Scores of the characters of a fuzzy match.
+const (
+	matchScore       = 1
+	consecutiveBonus = 5
+	wordStartBonus   = 8
+	gapPenalty       = 1
+	maxGapPenalty    = 10
+)
+
+// Match is a string which matched a fuzzy pattern.
+type Match struct {
+	// Index is the index of the string in the strings which were matched.
+	Index int
+	Str   string
+	// Positions are the indexes of the matched runes in the string.
+	Positions []int
+	Score     int
+}
+
+// FuzzyMatch returns whether the runes of pattern appear in s in order,
+// ignoring case, and the positions of the best match. Matches at the start
+// of words, of consecutive runes and early in the string score higher.
+func FuzzyMatch(pattern, s string) (Match, bool) {
+	p := []rune(pattern)
+	r := []rune(s)
+	if len(p) == 0 {
+		return Match{Str: s}, true
+	}
+
+	// score[j][i] is the best score of the first j+1 runes of the pattern
+	// with rune j at position i of the string, from[j][i] the position of
+	// rune j-1 in that match.
+	const none = -1 << 31
+	// Gaps of far runes or more have the largest penalty, the best match
+	// before them is kept while going through the string.
+	const far = maxGapPenalty / gapPenalty
+	score := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for j := range p {
+		score[j] = make([]int, len(r))
+		from[j] = make([]int, len(r))
+		farBest := -1
+		for i := range r {
+			score[j][i] = none
+			if j > 0 && i-far-1 >= 0 {
+				if k := i - far - 1; score[j-1][k] != none && (farBest < 0 || score[j-1][k] > score[j-1][farBest]) {
+					farBest = k
+				}
+			}
+			if !equalFold(r[i], p[j]) {
+				continue
+			}
+			bonus := matchScore
+			if i == 0 || !isWordRune(r[i-1]) {
+				bonus += wordStartBonus
+			}
+			if j == 0 {
+				score[j][i] = bonus - min(i*gapPenalty, maxGapPenalty)
+				continue
+			}
+			try := func(k int) {
+				if k < 0 || score[j-1][k] == none {
+					return
+				}
+				total := score[j-1][k] + bonus
+				if gap := i - k - 1; gap == 0 {
+					total += consecutiveBonus
+				} else {
+					total -= min(gap*gapPenalty, maxGapPenalty)
+				}
+				if total > score[j][i] {
+					score[j][i], from[j][i] = total, k
+				}
+			}
+			try(farBest)
+			for k := max(i-far, 0); k < i; k++ {
+				try(k)
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	for i := range r {
+		if score[last][i] != none && (end < 0 || score[last][i] > score[last][end]) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return Match{Str: s}, false
+	}
+	positions := make([]int, len(p))
+	for j, i := last, end; j >= 0; j-- {
+		positions[j] = i
+		i = from[j][i]
+	}
+	return Match{Str: s, Positions: positions, Score: score[last][end]}, true
+}
+
+// FuzzyFind returns the strings which match pattern, the best matches
+// first.
+func FuzzyFind(pattern string, strs []string) []Match {
+	var matches []Match
+	for i, s := range strs {
+		if m, ok := FuzzyMatch(pattern, s); ok {
+			m.Index = i
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+func equalFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,49 @@
+package navigation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		str       string
+		ok        bool
+		positions []int
+	}{
+		{pattern: "", str: "anything", ok: true},
+		{pattern: "intro", str: "Introduction", ok: true, positions: []int{0, 1, 2, 3, 4}},
+		{pattern: "gs", str: "Getting Started", ok: true, positions: []int{0, 8}},
+		{pattern: "ts", str: "test Starts", ok: true, positions: []int{0, 5}},
+		{pattern: "xyz", str: "Introduction", ok: false},
+		{pattern: "zi", str: "Introduction", ok: false},
+		{pattern: "ü", str: "Über", ok: true, positions: []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.str, func(t *testing.T) {
+			m, ok := FuzzyMatch(tt.pattern, tt.str)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.positions, m.Positions)
+			}
+		})
+	}
+}
+
+func TestFuzzyFind(t *testing.T) {
+	titles := []string{
+		"Questions",
+		"Installing the server",
+		"Introduction",
+		"Summary",
+		"Demo: install",
+	}
+	var found []string
+	for _, m := range FuzzyFind("ins", titles) {
+		found = append(found, m.Str)
+	}
+	assert.Equal(t, []string{"Installing the server", "Demo: install", "Questions"}, found)
+}
@@ -0,0 +1,66 @@
+package navigation
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/styles"
+)
+
+// listInput is a query typed above a list of slides with one of them
+// selected, which the picker and the finder share.
+type listInput struct {
+	Active bool
+	Input  textinput.Model
+	// selected is the index of the selected item of the list.
+	selected int
+}
+
+func newListInput(placeholder string) listInput {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.Prompt = "> "
+	ti.PromptStyle = styles.Search
+	ti.TextStyle = styles.Search
+	return listInput{Input: ti}
+}
+
+// begin shows the input with an empty query and the first item selected.
+func (l *listInput) begin() tea.Cmd {
+	l.Active = true
+	l.Input.SetValue("")
+	l.selected = 0
+	return l.Input.Focus()
+}
+
+// Done hides the input.
+func (l *listInput) Done() {
+	l.Active = false
+	l.Input.Blur()
+}
+
+// Query returns the text which is typed.
+func (l *listInput) Query() string {
+	return l.Input.Value()
+}
+
+// update moves the selection among the n items of the list or updates the
+// query, and returns whether the query changed. The first item is selected
+// when it did.
+func (l *listInput) update(msg tea.KeyMsg, n int) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "up", "ctrl+p", "ctrl+k":
+		l.selected = max(l.selected-1, 0)
+		return nil, false
+	case "down", "ctrl+n", "ctrl+j":
+		l.selected = max(min(l.selected+1, n-1), 0)
+		return nil, false
+	}
+	query := l.Input.Value()
+	var cmd tea.Cmd
+	l.Input, cmd = l.Input.Update(msg)
+	changed := l.Input.Value() != query
+	if changed {
+		l.selected = 0
+	}
+	return cmd, changed
+}
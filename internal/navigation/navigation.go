@@ -2,21 +2,41 @@ package navigation
 
 import (
 	"strconv"
+	"unicode/utf8"
+
+	"github.com/maaslalani/slides/internal/keymap"
 )
 
 type repeatableFunc func(slide, totalSlides int) int
 
-// State tracks the current buffer, page, and total number of slides
+// State tracks the current buffer, page, and total number of slides. Slides
+// can also have steps, such as groups of highlighted lines in a code block,
+// which are stepped through before moving on to the next slide. LastStep is
+// set when going back to the previous slide, which then starts at its last
+// step rather than its first. Sections are the sections of the presentation
+// in order, which are jumped between. Keys are the key bindings, the default
+// ones if it is nil.
 type State struct {
 	Buffer      string
 	Page        int
 	TotalSlides int
+	Step        int
+	TotalSteps  int
+	LastStep    bool
+	Sections    []Section
+	Keys        *keymap.KeyMap
 }
 
 // Navigate receives the current State and keyPress, and returns the new State.
 func Navigate(state State, keyPress string) State {
-	switch keyPress {
-	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+	keys := state.Keys
+	if keys == nil {
+		defaults := keymap.Default()
+		keys = &defaults
+	}
+
+	switch {
+	case len(keyPress) == 1 && keyPress[0] >= '0' && keyPress[0] <= '9':
 		newBuffer := keyPress
 
 		if bufferIsNumeric(state.Buffer) {
@@ -28,21 +48,28 @@ func Navigate(state State, keyPress string) State {
 			Page:        state.Page,
 			TotalSlides: state.TotalSlides,
 		}
-	case "g":
+	case keymap.Has(keys.First, keyPress):
+		// Keys which are a single character, such as g, are pressed twice.
+		if utf8.RuneCountInString(keyPress) > 1 {
+			return State{
+				Page:        0,
+				TotalSlides: state.TotalSlides,
+			}
+		}
 		switch state.Buffer {
-		case "g":
+		case keyPress:
 			return State{
 				Page:        0,
 				TotalSlides: state.TotalSlides,
 			}
 		default:
 			return State{
-				Buffer:      "g",
+				Buffer:      keyPress,
 				Page:        state.Page,
 				TotalSlides: state.TotalSlides,
 			}
 		}
-	case "G":
+	case keymap.Has(keys.Last, keyPress):
 		targetSlide := state.TotalSlides - 1
 		if bufferIsNumeric(state.Buffer) {
 			targetSlide = navigateSlide(state.Buffer, state.TotalSlides)
@@ -52,14 +79,22 @@ func Navigate(state State, keyPress string) State {
 			Page:        targetSlide,
 			TotalSlides: state.TotalSlides,
 		}
-	case " ", "down", "j", "right", "l", "enter", "n", "pgdown":
+	case keymap.Has(keys.Next, keyPress):
+		return Next(state)
+	case keymap.Has(keys.Previous, keyPress):
+		return Previous(state)
+	case keymap.Has(keys.NextSection, keyPress):
 		return State{
-			Page:        navigateNext(state),
+			Page: repeatableAction(func(slide, _ int) int {
+				return nextSection(state.Sections, slide)
+			}, state),
 			TotalSlides: state.TotalSlides,
 		}
-	case "up", "k", "left", "h", "p", "pgup", "N":
+	case keymap.Has(keys.PreviousSection, keyPress):
 		return State{
-			Page:        navigatePrevious(state),
+			Page: repeatableAction(func(slide, _ int) int {
+				return previousSection(state.Sections, slide)
+			}, state),
 			TotalSlides: state.TotalSlides,
 		}
 	default:
@@ -70,6 +105,48 @@ func Navigate(state State, keyPress string) State {
 	}
 }
 
+// Next returns the State after going forward, to the next step of the slide
+// or to the next slide.
+func Next(state State) State {
+	if !bufferIsNumeric(state.Buffer) && state.Step < state.TotalSteps-1 {
+		return State{
+			Page:        state.Page,
+			TotalSlides: state.TotalSlides,
+			Step:        state.Step + 1,
+			TotalSteps:  state.TotalSteps,
+		}
+	}
+	return State{
+		Page:        navigateNext(state),
+		TotalSlides: state.TotalSlides,
+	}
+}
+
+// Previous returns the State after going back, to the previous step of the
+// slide or to the last step of the previous slide.
+func Previous(state State) State {
+	if bufferIsNumeric(state.Buffer) {
+		return State{
+			Page:        navigatePrevious(state),
+			TotalSlides: state.TotalSlides,
+		}
+	}
+	if state.Step > 0 {
+		return State{
+			Page:        state.Page,
+			TotalSlides: state.TotalSlides,
+			Step:        state.Step - 1,
+			TotalSteps:  state.TotalSteps,
+		}
+	}
+	page := navigatePrevious(state)
+	return State{
+		Page:        page,
+		TotalSlides: state.TotalSlides,
+		LastStep:    page != state.Page,
+	}
+}
+
 func bufferIsNumeric(buffer string) bool {
 	_, err := strconv.Atoi(buffer)
 	return err == nil
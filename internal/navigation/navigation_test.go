@@ -4,13 +4,15 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/maaslalani/slides/internal/keymap"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNavigation(t *testing.T) {
 	tests := []struct {
-		keys   string
-		target int
+		keys     string
+		target   int
+		lastStep bool
 	}{
 		{target: 0},
 		{keys: "l", target: 1},
@@ -25,7 +27,7 @@ func TestNavigation(t *testing.T) {
 		{keys: "3G", target: 2},
 		{keys: "11G", target: 10},
 		{keys: "101G", target: 10},
-		{keys: "nnN", target: 1},
+		{keys: "nnN", target: 1, lastStep: true},
 	}
 
 	for _, tt := range tests {
@@ -40,8 +42,142 @@ func TestNavigation(t *testing.T) {
 				currentState = Navigate(currentState, key)
 			}
 
-			targetState := State{Page: tt.target, TotalSlides: 11}
+			targetState := State{Page: tt.target, TotalSlides: 11, LastStep: tt.lastStep}
 			assert.Equal(t, targetState, currentState)
 		})
 	}
 }
+
+func TestNavigationSteps(t *testing.T) {
+	tests := []struct {
+		keys string
+		page int
+		step int
+	}{
+		{keys: "", page: 0, step: 0},
+		{keys: "j", page: 0, step: 1},
+		{keys: "jj", page: 0, step: 2},
+		{keys: "jjj", page: 1, step: 0},
+		{keys: "jjk", page: 0, step: 1},
+		{keys: "k", page: 0, step: 0},
+		{keys: "2j", page: 2, step: 0},
+		{keys: "jjjk", page: 0, step: 2},
+		{keys: "jjjkk", page: 0, step: 1},
+		{keys: "2j2k", page: 0, step: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			currentState := State{
+				Page:        0,
+				TotalSlides: 11,
+				TotalSteps:  3,
+			}
+
+			for _, key := range strings.Split(tt.keys, "") {
+				currentState = Navigate(currentState, key)
+				// Only the first slide has steps
+				if currentState.Page != 0 {
+					currentState.TotalSteps = 0
+				} else {
+					currentState.TotalSteps = 3
+				}
+				if currentState.LastStep {
+					currentState.Step = currentState.TotalSteps - 1
+				}
+			}
+
+			assert.Equal(t, tt.page, currentState.Page)
+			assert.Equal(t, tt.step, currentState.Step)
+		})
+	}
+}
+
+func TestNavigationSections(t *testing.T) {
+	sections := []Section{
+		{Title: "Introduction", Page: 1},
+		{Title: "Usage", Page: 4},
+		{Title: "Questions", Page: 9},
+	}
+	tests := []struct {
+		keys   string
+		target int
+	}{
+		{keys: "}", target: 1},
+		{keys: "}}", target: 4},
+		{keys: "}}}}", target: 9},
+		{keys: "2}", target: 4},
+		{keys: "}}{", target: 1},
+		{keys: "}}{{", target: 0},
+		{keys: "}jj{", target: 1},
+		{keys: "}jj{{", target: 0},
+		{keys: "G{", target: 9},
+		{keys: "G{{", target: 4},
+		{keys: "{", target: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			currentState := State{TotalSlides: 11, Sections: sections}
+			for _, key := range strings.Split(tt.keys, "") {
+				currentState = Navigate(currentState, key)
+				currentState.Sections = sections
+			}
+			assert.Equal(t, tt.target, currentState.Page)
+		})
+	}
+}
+
+func TestSectionAt(t *testing.T) {
+	sections := []Section{{Title: "A", Page: 2}, {Title: "B", Page: 5}}
+	for page, want := range []int{-1, -1, 0, 0, 0, 1, 1} {
+		assert.Equal(t, want, SectionAt(sections, page), "page %d", page)
+	}
+}
+
+func TestNavigationKeys(t *testing.T) {
+	keys, err := keymap.Default().With(map[string]keymap.Keys{
+		"next":     {".", "pagedown"},
+		"previous": {"b"},
+		"first":    {"home"},
+	})
+	assert.NoError(t, err)
+	tests := []struct {
+		keys   []string
+		target int
+	}{
+		{keys: []string{"."}, target: 1},
+		{keys: []string{"pgdown", "pgdown", "b"}, target: 1},
+		{keys: []string{"l"}, target: 0},
+		{keys: []string{"3", "."}, target: 3},
+		{keys: []string{"G", "home"}, target: 0},
+		{keys: []string{"G", "g", "g"}, target: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.keys, " "), func(t *testing.T) {
+			currentState := State{TotalSlides: 11}
+			for _, key := range tt.keys {
+				currentState.Keys = &keys
+				currentState = Navigate(currentState, key)
+			}
+			assert.Equal(t, tt.target, currentState.Page)
+		})
+	}
+}
+
+func TestNextPrevious(t *testing.T) {
+	state := State{Page: 1, TotalSlides: 3, TotalSteps: 2}
+	state = Next(state)
+	assert.Equal(t, State{Page: 1, TotalSlides: 3, Step: 1, TotalSteps: 2}, state)
+	state = Next(state)
+	assert.Equal(t, State{Page: 2, TotalSlides: 3}, state)
+	state = Next(state)
+	assert.Equal(t, State{Page: 2, TotalSlides: 3}, state)
+	state = Previous(state)
+	assert.Equal(t, State{Page: 1, TotalSlides: 3, LastStep: true}, state)
+	state = Previous(State{Page: 0, TotalSlides: 3})
+	assert.Equal(t, State{Page: 0, TotalSlides: 3}, state)
+	state = Previous(State{Page: 2, TotalSlides: 3, Buffer: "2"})
+	assert.Equal(t, State{Page: 0, TotalSlides: 3}, state)
+}
@@ -0,0 +1,100 @@
+package navigation
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/styles"
+)
+
+// Picker picks a slide by its title, the titles are filtered and ranked
+// fuzzily as the query is typed.
+type Picker struct {
+	listInput
+	// titles are the titles of the slides by page.
+	titles  []string
+	matches []Match
+}
+
+// NewPicker creates and returns a new picker with the default settings.
+func NewPicker() Picker {
+	return Picker{listInput: newListInput("go to slide")}
+}
+
+// Begin shows the picker for the slides with the given titles, every slide
+// matches until something is typed.
+func (p *Picker) Begin(titles []string) tea.Cmd {
+	p.titles = titles
+	cmd := p.begin()
+	p.matches = FuzzyFind("", titles)
+	return cmd
+}
+
+// Selection returns the page of the selected slide, false if no slide
+// matches.
+func (p *Picker) Selection() (int, bool) {
+	if p.selected >= len(p.matches) {
+		return 0, false
+	}
+	return p.matches[p.selected].Index, true
+}
+
+// Update moves the selection or updates the query.
+func (p *Picker) Update(msg tea.KeyMsg) tea.Cmd {
+	cmd, changed := p.update(msg, len(p.matches))
+	if changed {
+		p.matches = FuzzyFind(p.Query(), p.titles)
+	}
+	return cmd
+}
+
+// View renders the query and as many of the matching slides as fit in the
+// height, with the matched characters highlighted.
+func (p Picker) View(height int) string {
+	lines := []string{p.Input.View(), ""}
+	rows := max(height-len(lines), 1)
+	first := max(p.selected-rows+1, 0)
+	for i := first; i < len(p.matches) && i < first+rows; i++ {
+		m := p.matches[i]
+		marker := "  "
+		title := styles.PickerItem
+		if i == p.selected {
+			marker = styles.PickerSelected.Render("› ")
+			title = styles.PickerSelected
+		}
+		number := styles.Dim.Render(fmt.Sprintf("%3d  ", m.Index+1))
+		lines = append(lines, "  "+marker+number+highlight(m, title))
+	}
+	if len(p.matches) == 0 {
+		lines = append(lines, styles.Search.Render("no matching slides"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlight renders the string of a match in style, with its matched runes
+// in the match style.
+func highlight(m Match, style lipgloss.Style) string {
+	matched := map[int]bool{}
+	for _, i := range m.Positions {
+		matched[i] = true
+	}
+	var b strings.Builder
+	runes := []rune(m.Str)
+	// Runs of runes which are matched or not are rendered together.
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && matched[end] == matched[start] {
+			end++
+		}
+		run := string(runes[start:end])
+		if matched[start] {
+			b.WriteString(styles.PickerMatch.Render(run))
+		} else {
+			b.WriteString(style.Render(run))
+		}
+		start = end
+	}
+	return b.String()
+}
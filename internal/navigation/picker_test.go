@@ -0,0 +1,43 @@
+package navigation
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPicker(t *testing.T) {
+	p := NewPicker()
+	p.Begin([]string{"Welcome", "Installation", "Usage", "Questions"})
+	assert.True(t, p.Active)
+
+	tests := []struct {
+		msg  tea.KeyMsg
+		page int
+	}{
+		{tea.KeyMsg{Type: tea.KeyDown}, 1},
+		{tea.KeyMsg{Type: tea.KeyCtrlN}, 2},
+		{tea.KeyMsg{Type: tea.KeyCtrlN}, 3},
+		{tea.KeyMsg{Type: tea.KeyDown}, 3},
+		{tea.KeyMsg{Type: tea.KeyUp}, 2},
+		{tea.KeyMsg{Type: tea.KeyCtrlP}, 1},
+		// Typing selects the best match.
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}, 2},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("es")}, 3},
+		{tea.KeyMsg{Type: tea.KeyUp}, 3},
+	}
+	for _, tt := range tests {
+		p.Update(tt.msg)
+		page, ok := p.Selection()
+		assert.True(t, ok, tt.msg.String())
+		assert.Equal(t, tt.page, page, tt.msg.String())
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	_, ok := p.Selection()
+	assert.False(t, ok)
+
+	p.Done()
+	assert.False(t, p.Active)
+}
@@ -24,6 +24,18 @@ type Search struct {
 	Active bool
 	// Query stores the current "search term"
 	SearchTextInput textinput.Model
+	// Backward searches from the current slide towards the first one,
+	// instead of towards the last one.
+	Backward bool
+	// Found is set once a search matched a slide, until it is cleared. The
+	// matches of the query are highlighted meanwhile.
+	Found bool
+	// pattern is the compiled query, compiled from patternQuery, so that it
+	// is not compiled again for every frame.
+	pattern      *regexp.Regexp
+	patternQuery string
+	// matches are the pages which matched when the search last ran.
+	matches []int
 }
 
 // NewSearch creates and returns a new search model with the default settings.
@@ -56,41 +68,109 @@ func (s *Search) Done() {
 // Begin a new search (deletes old buffer)
 func (s *Search) Begin() {
 	s.Active = true
+	s.Backward = false
+	s.SearchTextInput.Prompt = "/"
 	s.SetQuery("")
 }
 
-// Execute search
-func (s *Search) Execute(m Model) {
-	defer s.Done()
-	expr := s.Query()
-	if expr == "" {
-		return
+// BeginBackward begins a new search towards the first slide.
+func (s *Search) BeginBackward() {
+	s.Begin()
+	s.Backward = true
+	s.SearchTextInput.Prompt = "?"
+}
+
+// Clear stops highlighting the matches of the last search and forgets which
+// slides matched.
+func (s *Search) Clear() {
+	s.Found = false
+	s.matches = nil
+}
+
+// Pattern returns the regular expression of the query, the /i flag makes
+// it case-insensitive. It is only compiled again when the query changed.
+func (s *Search) Pattern() (*regexp.Regexp, error) {
+	query := s.Query()
+	if s.pattern != nil && s.patternQuery == query {
+		return s.pattern, nil
 	}
+	expr := query
 	if strings.HasSuffix(expr, "/i") {
 		expr = "(?i)" + expr[:len(expr)-2]
 	}
 	pattern, err := regexp.Compile(expr)
 	if err != nil {
-		return
+		return nil, err
 	}
-	check := func(i int) bool {
-		content := m.Pages()[i].Content
-		if len(pattern.FindAllStringSubmatch(content, 1)) != 0 {
-			m.SetPage(i)
-			return true
+	s.pattern, s.patternQuery = pattern, query
+	return pattern, nil
+}
+
+// Execute search, it returns the command of the page change if a slide
+// matched.
+func (s *Search) Execute(m Model) tea.Cmd {
+	defer s.Done()
+	return s.find(m, s.Backward)
+}
+
+// Previous goes to the previous match of the search, in the opposite
+// direction of the search.
+func (s *Search) Previous(m Model) tea.Cmd {
+	return s.find(m, !s.Backward)
+}
+
+func (s *Search) find(m Model, backward bool) tea.Cmd {
+	if s.Query() == "" {
+		return nil
+	}
+	pattern, err := s.Pattern()
+	if err != nil {
+		return nil
+	}
+	pages := m.Pages()
+	s.matches = nil
+	for page, slide := range pages {
+		if pattern.MatchString(Text(slide)) {
+			s.matches = append(s.matches, page)
 		}
-		return false
 	}
-	// search from next slide to end
-	for i := m.CurrentPage() + 1; i < len(m.Pages()); i++ {
-		if check(i) {
-			return
+	s.Found = len(s.matches) > 0
+
+	n := len(pages)
+	matched := make(map[int]bool, len(s.matches))
+	for _, page := range s.matches {
+		matched[page] = true
+	}
+	// search from the next slide around to the previous one
+	for i := 1; i < n; i++ {
+		page := (m.CurrentPage() + i) % n
+		if backward {
+			page = (m.CurrentPage() - i + n) % n
+		}
+		if matched[page] {
+			return m.SetPage(page)
 		}
 	}
-	// search from first slide to previous
-	for i := 0; i < m.CurrentPage(); i++ {
-		if check(i) {
-			return
+	return nil
+}
+
+// Count returns the number of the current slide among the slides which
+// matched when the search last ran, zero if it did not match, and the number
+// of slides which matched.
+func (s *Search) Count(m Model) (int, int) {
+	for i, page := range s.matches {
+		if page == m.CurrentPage() {
+			return i + 1, len(s.matches)
 		}
 	}
+	return 0, len(s.matches)
+}
+
+// Text returns the text of a slide which is searched, its header and its
+// content with the code and comments, such as speaker notes.
+func Text(slide slides.Slide) string {
+	if slide.Header == nil || slide.Header.Alt == "" {
+		return slide.Content
+	}
+	return slide.Header.Alt + "\n" + slide.Content
 }
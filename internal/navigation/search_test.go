@@ -72,3 +72,88 @@ func TestSearch(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchBackward(t *testing.T) {
+	data := []slides.Slide{
+		{Content: "intro"},
+		{Content: "match one"},
+		{Content: "nothing"},
+		{Content: "match two"},
+		{Header: &slides.Image{Alt: "Match in header"}, Content: "body"},
+	}
+	m := &mockModel{slides: data, page: 2}
+
+	s := NewSearch()
+	s.BeginBackward()
+	s.SetQuery("match/i")
+	s.Execute(m)
+	if m.page != 1 {
+		t.Errorf("expected backward search to go to page 1, got %d", m.page)
+	}
+	if !s.Found || s.Active {
+		t.Errorf("expected search to be found and done")
+	}
+
+	// Next keeps the direction of the search, previous turns it around.
+	for _, tt := range []struct {
+		previous bool
+		page     int
+	}{
+		{false, 4},
+		{false, 3},
+		{true, 4},
+		{true, 1},
+	} {
+		if tt.previous {
+			s.Previous(m)
+		} else {
+			s.Execute(m)
+		}
+		if m.page != tt.page {
+			t.Errorf("expected page %d, got %d", tt.page, m.page)
+		}
+	}
+
+	current, total := s.Count(m)
+	if current != 1 || total != 3 {
+		t.Errorf("expected 1/3, got %d/%d", current, total)
+	}
+	m.page = 2
+	if current, _ := s.Count(m); current != 0 {
+		t.Errorf("expected slide without a match to count 0, got %d", current)
+	}
+
+	s.Clear()
+	if s.Found {
+		t.Errorf("expected search to be cleared")
+	}
+	if _, total := s.Count(m); total != 0 {
+		t.Errorf("expected a cleared search to have no matches, got %d", total)
+	}
+}
+
+func TestSearchNotFound(t *testing.T) {
+	m := &mockModel{slides: []slides.Slide{{Content: "one"}, {Content: "two"}}}
+
+	s := NewSearch()
+	s.Begin()
+	s.SetQuery("three")
+	s.Execute(m)
+	if s.Found || m.page != 0 {
+		t.Errorf("expected a search without matches not to be found, got page %d", m.page)
+	}
+
+	s.SetQuery("two")
+	s.Execute(m)
+	if !s.Found || m.page != 1 {
+		t.Errorf("expected the search to be found on page 1, got page %d", m.page)
+	}
+
+	// Searching again for something which is not there forgets the earlier
+	// matches.
+	s.SetQuery("three")
+	s.Execute(m)
+	if _, total := s.Count(m); s.Found || total != 0 {
+		t.Errorf("expected no matches, got %d", total)
+	}
+}
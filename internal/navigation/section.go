@@ -0,0 +1,44 @@
+package navigation
+
+// Section is a part of a presentation, it starts at Page and goes on until
+// the next section.
+type Section struct {
+	Title string
+	Page  int
+}
+
+// SectionAt returns the index of the section that page is in, or -1 if the
+// page comes before the first section.
+func SectionAt(sections []Section, page int) int {
+	for i := len(sections) - 1; i >= 0; i-- {
+		if sections[i].Page <= page {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextSection returns the first page of the section after the one page is
+// in, or the page if it is in the last section.
+func nextSection(sections []Section, page int) int {
+	if i := SectionAt(sections, page) + 1; i < len(sections) {
+		return sections[i].Page
+	}
+	return page
+}
+
+// previousSection returns the first page of the section page is in, or of
+// the section before if the page is the first of its section.
+func previousSection(sections []Section, page int) int {
+	i := SectionAt(sections, page)
+	if i < 0 {
+		return page
+	}
+	if sections[i].Page < page {
+		return sections[i].Page
+	}
+	if i > 0 {
+		return sections[i-1].Page
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package pane
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// sequences are the bytes terminals send for keys which are not characters.
+var sequences = map[tea.KeyType]string{
+	tea.KeyUp:       "\x1b[A",
+	tea.KeyDown:     "\x1b[B",
+	tea.KeyRight:    "\x1b[C",
+	tea.KeyLeft:     "\x1b[D",
+	tea.KeyHome:     "\x1b[H",
+	tea.KeyEnd:      "\x1b[F",
+	tea.KeyPgUp:     "\x1b[5~",
+	tea.KeyPgDown:   "\x1b[6~",
+	tea.KeyInsert:   "\x1b[2~",
+	tea.KeyDelete:   "\x1b[3~",
+	tea.KeyShiftTab: "\x1b[Z",
+	tea.KeySpace:    " ",
+	tea.KeyF1:       "\x1bOP",
+	tea.KeyF2:       "\x1bOQ",
+	tea.KeyF3:       "\x1bOR",
+	tea.KeyF4:       "\x1bOS",
+	tea.KeyF5:       "\x1b[15~",
+	tea.KeyF6:       "\x1b[17~",
+	tea.KeyF7:       "\x1b[18~",
+	tea.KeyF8:       "\x1b[19~",
+	tea.KeyF9:       "\x1b[20~",
+	tea.KeyF10:      "\x1b[21~",
+	tea.KeyF11:      "\x1b[23~",
+	tea.KeyF12:      "\x1b[24~",
+}
+
+// KeyBytes turns a key press back into the bytes a terminal would send for
+// it to a program.
+func KeyBytes(key tea.KeyMsg) []byte {
+	var s string
+	switch {
+	case key.Type == tea.KeyRunes:
+		s = string(key.Runes)
+	case key.Type >= 0 && key.Type < 0x20, key.Type == 0x7f:
+		// Control characters, enter, tab, escape and backspace are their
+		// own key types.
+		s = string(rune(key.Type))
+	default:
+		s = sequences[key.Type]
+	}
+
+	if key.Alt && s != "" {
+		s = "\x1b" + s
+	}
+	return []byte(s)
+}
@@ -0,0 +1,138 @@
+// Package pane runs a shell or command in a pseudo terminal so that it can be
+// displayed and used from inside a slide.
+package pane
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/creack/pty"
+	"github.com/maaslalani/slides/internal/vt"
+)
+
+// Pane is a command running in a pseudo terminal.
+type Pane struct {
+	cmd  *exec.Cmd
+	pty  *os.File
+	term *vt.Terminal
+
+	// updated is signalled whenever the command writes output. It holds at
+	// most one pending update so that bursts of output are drawn once.
+	updated chan struct{}
+	exited  chan struct{}
+
+	closeOnce sync.Once
+}
+
+// UpdateMsg is sent when the command in the pane wrote output or exited.
+type UpdateMsg struct {
+	Pane   *Pane
+	Exited bool
+}
+
+// Start runs command in a pseudo terminal of the given size. An empty command
+// starts the user's shell. The command is killed when ctx is done.
+func Start(ctx context.Context, command string, cols, rows int) (*Pane, error) {
+	var cmd *exec.Cmd
+	if command == "" {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "sh"
+		}
+		cmd = exec.CommandContext(ctx, shell)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+
+	term := vt.New(cols, rows)
+	term.Reply = f
+
+	p := &Pane{
+		cmd:     cmd,
+		pty:     f,
+		term:    term,
+		updated: make(chan struct{}, 1),
+		exited:  make(chan struct{}),
+	}
+	go p.read()
+	return p, nil
+}
+
+func (p *Pane) read() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := p.pty.Read(buf)
+		if n > 0 {
+			_, _ = p.term.Write(buf[:n])
+			select {
+			case p.updated <- struct{}{}:
+			default:
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	_ = p.cmd.Wait()
+	close(p.exited)
+}
+
+// Wait returns a command which waits for the pane to change.
+func (p *Pane) Wait() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-p.updated:
+			return UpdateMsg{Pane: p}
+		case <-p.exited:
+			return UpdateMsg{Pane: p, Exited: true}
+		}
+	}
+}
+
+// Exited returns whether the command has exited.
+func (p *Pane) Exited() bool {
+	select {
+	case <-p.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// Send writes the key to the command's input.
+func (p *Pane) Send(key tea.KeyMsg) {
+	_, _ = p.pty.Write(KeyBytes(key))
+}
+
+// Resize changes the size of the pseudo terminal.
+func (p *Pane) Resize(cols, rows int) {
+	if c, r := p.term.Size(); c == cols && r == rows {
+		return
+	}
+	p.term.Resize(cols, rows)
+	_ = pty.Setsize(p.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// View renders the screen of the pane, with a cursor if it is focused.
+func (p *Pane) View(focused bool) string {
+	return p.term.Render(focused && !p.Exited())
+}
+
+// Close kills the command and releases the pseudo terminal.
+func (p *Pane) Close() {
+	p.closeOnce.Do(func() {
+		if p.cmd.Process != nil {
+			_ = p.cmd.Process.Kill()
+		}
+		_ = p.pty.Close()
+	})
+}
@@ -0,0 +1,58 @@
+package pane_test
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/maaslalani/slides/internal/pane"
+)
+
+func TestKeyBytes(t *testing.T) {
+	tt := []struct {
+		key      tea.KeyMsg
+		expected string
+	}{
+		{key: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ls")}, expected: "ls"},
+		{key: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b"), Alt: true}, expected: "\x1bb"},
+		{key: tea.KeyMsg{Type: tea.KeyEnter}, expected: "\r"},
+		{key: tea.KeyMsg{Type: tea.KeyBackspace}, expected: "\x7f"},
+		{key: tea.KeyMsg{Type: tea.KeyCtrlC}, expected: "\x03"},
+		{key: tea.KeyMsg{Type: tea.KeyUp}, expected: "\x1b[A"},
+		{key: tea.KeyMsg{Type: tea.KeySpace}, expected: " "},
+	}
+
+	for _, tc := range tt {
+		if got := string(pane.KeyBytes(tc.key)); got != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.key, tc.expected, got)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo terminals are not supported on windows")
+	}
+
+	p, err := pane.Start(context.Background(), "echo hello from the pane", 40, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	deadline := time.After(5 * time.Second)
+	for !p.Exited() {
+		select {
+		case <-deadline:
+			t.Fatal("command did not exit")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if view := p.View(false); !strings.Contains(view, "hello from the pane") {
+		t.Errorf("expected output in view, got %q", view)
+	}
+}
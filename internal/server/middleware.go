@@ -7,6 +7,7 @@ import (
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	bm "github.com/charmbracelet/wish/bubbletea"
+	"github.com/maaslalani/slides/internal/term"
 	"github.com/muesli/termenv"
 )
 
@@ -25,7 +26,16 @@ func slidesMiddleware(srv *Server) wish.Middleware {
 			}
 			return nil
 		}
-		return newProg(srv.presentation, tea.WithInput(s), tea.WithOutput(s), tea.WithAltScreen())
+		out := term.Synchronized(s)
+		presentation := srv.presentation
+		presentation.Output = out
+		presentation.Remote = true
+		presentation.Context = s.Context()
+		opts := []tea.ProgramOption{tea.WithInput(s), tea.WithOutput(out), tea.WithAltScreen()}
+		if presentation.Mouse {
+			opts = append(opts, tea.WithMouseCellMotion())
+		}
+		return newProg(presentation, opts...)
 	}
 	return bm.MiddlewareWithProgramHandler(teaHandler, termenv.ANSI256)
 }
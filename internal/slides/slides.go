@@ -1,11 +1,63 @@
 package slides
 
-import "image"
+import (
+	"image"
+	"regexp"
+	"strings"
+
+	"github.com/maaslalani/slides/internal/animation"
+)
 
 type Slide struct {
-	Header    image.Image
-	Image     image.Image
-	Content   string
-	HeaderStr string
-	ImageStr  string
+	// Header is the first header of the slide, which is drawn as an image.
+	// A header which is only an image has its Path, the text of other
+	// headers is their Alt.
+	Header  *Image
+	Content string
+	// Images are the images on the slide in the order they appear in.
+	Images []Image
+}
+
+// Image is an image on a slide, written ![alt](path){width=40% float=left}.
+type Image struct {
+	// Image is nil if the image could not be loaded, the alt text is shown
+	// instead.
+	Image image.Image
+	// ID is the id the image is sent to Kitty with, the same image has the
+	// same id on every slide.
+	ID uint32
+	// Animation holds the frames of animated images, Image is the first
+	// frame.
+	Animation animation.Animation
+	Alt       string
+	Path      string
+	// Width and Height are the size of the image in cells, or in percent of
+	// the slide, e.g. 30 or 40%. The image keeps its aspect ratio and fits
+	// into the slide when they are empty.
+	Width  string
+	Height string
+	// Float is left or right for images placed next to the text of the
+	// slide, rather than between its paragraphs.
+	Float string
+	// Str is the image rendered for the terminal, it takes up Cols columns.
+	Str  string
+	Cols int
+	// FrameStrs are the frames of an animated image rendered for terminals
+	// which are sent every frame in turn.
+	FrameStrs []string
+}
+
+// Directives are HTML comments of the form <!-- name: value --> or
+// <!-- name -->, they configure a single slide and are hidden when rendered.
+var directiveRegexp = regexp.MustCompile(`<!--\s*([\w-]+)\s*(?::\s*(.*?))?\s*-->`)
+
+// Directive returns the value of the named directive on the slide and whether
+// the slide has that directive.
+func (s Slide) Directive(name string) (string, bool) {
+	for _, match := range directiveRegexp.FindAllStringSubmatch(s.Content, -1) {
+		if match[1] == name {
+			return strings.TrimSpace(match[2]), true
+		}
+	}
+	return "", false
 }
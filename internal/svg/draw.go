@@ -0,0 +1,571 @@
+package svg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// maxDepth limits how deeply use elements are followed, so that documents
+// which use themselves end.
+const maxDepth = 16
+
+var (
+	fontsOnce         sync.Once
+	regular, boldFont *truetype.Font
+)
+
+// fonts returns the fonts text is set in, whatever font family it asks for.
+func fonts() (*truetype.Font, *truetype.Font) {
+	fontsOnce.Do(func() {
+		regular, _ = truetype.Parse(goregular.TTF)
+		boldFont, _ = truetype.Parse(gobold.TTF)
+	})
+	return regular, boldFont
+}
+
+type renderer struct {
+	doc   *document
+	dst   *image.RGBA
+	depth int
+}
+
+// style returns the style of an element: its presentation attributes, then
+// the CSS rules which match it from least to most specific, then its style
+// attribute.
+func (r *renderer) style(e *element, s style) style {
+	for _, name := range []string{
+		"color", "fill", "stroke", "opacity", "fill-opacity", "stroke-opacity",
+		"stroke-width", "stroke-linecap", "stroke-dasharray", "font-size",
+		"font-weight", "font", "text-anchor", "marker-start", "marker-end",
+		"marker", "display", "visibility",
+	} {
+		if value, ok := e.attrs[name]; ok {
+			s.apply(name, value)
+		}
+	}
+
+	var rules []rule
+	for _, rule := range r.doc.rules {
+		if rule.matches(e) {
+			rules = append(rules, rule)
+		}
+	}
+	slices.SortStableFunc(rules, func(a, b rule) int { return a.specificity - b.specificity })
+	for _, rule := range rules {
+		for _, decl := range rule.decls {
+			s.apply(decl[0], decl[1])
+		}
+	}
+
+	for _, decl := range declarations(e.attrs["style"]) {
+		s.apply(decl[0], decl[1])
+	}
+	return s
+}
+
+// length returns a length attribute of an element, percentages are of the
+// width, the height or the diagonal of the view box.
+func (r *renderer) length(e *element, name string, axis byte) float64 {
+	vb := r.doc.viewBox
+	ref := math.Hypot(vb[2], vb[3]) / math.Sqrt2
+	switch axis {
+	case 'x':
+		ref = vb[2]
+	case 'y':
+		ref = vb[3]
+	}
+	n, _ := parseLength(e.attrs[name], ref)
+	return n
+}
+
+func (r *renderer) render(e *element, m matrix, s style) {
+	s = r.style(e, s)
+	if !s.display {
+		return
+	}
+	if t, ok := e.attrs["transform"]; ok {
+		m = m.mul(parseTransform(t))
+	}
+
+	switch e.name {
+	case "svg", "g", "a", "switch", "symbol":
+		if e != r.doc.root {
+			m = m.mul(translate(r.length(e, "x", 'x'), r.length(e, "y", 'y')))
+		}
+		for _, child := range e.children {
+			r.render(child, m, s)
+		}
+	case "use":
+		href := e.attrs["href"]
+		target, ok := r.doc.ids[strings.TrimPrefix(href, "#")]
+		if !ok || r.depth >= maxDepth {
+			return
+		}
+		r.depth++
+		r.render(target, m.mul(translate(r.length(e, "x", 'x'), r.length(e, "y", 'y'))), s)
+		r.depth--
+	case "text":
+		r.text(e, m, s)
+	default:
+		b := pathBuilder{m: m}
+		if !r.shape(e, &b) {
+			return
+		}
+		r.paint(b.subpaths, e.name != "line", m, s)
+	}
+}
+
+// shape adds the outline of a basic shape or path to b, it returns false for
+// elements which are not drawn.
+func (r *renderer) shape(e *element, b *pathBuilder) bool {
+	switch e.name {
+	case "rect":
+		x, y := r.length(e, "x", 'x'), r.length(e, "y", 'y')
+		w, h := r.length(e, "width", 'x'), r.length(e, "height", 'y')
+		if w <= 0 || h <= 0 {
+			return false
+		}
+		rx, ry := r.length(e, "rx", 'x'), r.length(e, "ry", 'y')
+		if _, ok := e.attrs["rx"]; !ok {
+			rx = ry
+		}
+		if _, ok := e.attrs["ry"]; !ok {
+			ry = rx
+		}
+		rx, ry = math.Min(rx, w/2), math.Min(ry, h/2)
+		if rx <= 0 || ry <= 0 {
+			b.moveTo(point{x, y})
+			b.lineTo(point{x + w, y})
+			b.lineTo(point{x + w, y + h})
+			b.lineTo(point{x, y + h})
+			b.close()
+			return true
+		}
+		b.moveTo(point{x + rx, y})
+		b.lineTo(point{x + w - rx, y})
+		b.arcTo(rx, ry, 0, false, true, point{x + w, y + ry})
+		b.lineTo(point{x + w, y + h - ry})
+		b.arcTo(rx, ry, 0, false, true, point{x + w - rx, y + h})
+		b.lineTo(point{x + rx, y + h})
+		b.arcTo(rx, ry, 0, false, true, point{x, y + h - ry})
+		b.lineTo(point{x, y + ry})
+		b.arcTo(rx, ry, 0, false, true, point{x + rx, y})
+		b.close()
+	case "circle":
+		radius := r.length(e, "r", 0)
+		if radius <= 0 {
+			return false
+		}
+		b.ellipse(r.length(e, "cx", 'x'), r.length(e, "cy", 'y'), radius, radius)
+	case "ellipse":
+		rx, ry := r.length(e, "rx", 'x'), r.length(e, "ry", 'y')
+		if rx <= 0 || ry <= 0 {
+			return false
+		}
+		b.ellipse(r.length(e, "cx", 'x'), r.length(e, "cy", 'y'), rx, ry)
+	case "line":
+		b.moveTo(point{r.length(e, "x1", 'x'), r.length(e, "y1", 'y')})
+		b.lineTo(point{r.length(e, "x2", 'x'), r.length(e, "y2", 'y')})
+	case "polyline", "polygon":
+		ns := numbers(e.attrs["points"])
+		for i := 0; i+1 < len(ns); i += 2 {
+			if i == 0 {
+				b.moveTo(point{ns[i], ns[i+1]})
+			} else {
+				b.lineTo(point{ns[i], ns[i+1]})
+			}
+		}
+		if e.name == "polygon" {
+			b.close()
+		}
+	case "path":
+		b.parsePath(e.attrs["d"])
+	default:
+		return false
+	}
+	return true
+}
+
+// paint fills and strokes the outline of a shape, and draws its markers.
+func (r *renderer) paint(subpaths []subpath, fill bool, m matrix, s style) {
+	if c, ok := r.color(s.fill, s.fillOpacity*s.opacity); ok && fill {
+		r.fill(subpaths, c)
+	}
+	width := s.strokeWidth * m.scale()
+	if c, ok := r.color(s.stroke, s.strokeOpacity*s.opacity); ok && width > 0 {
+		lines := subpaths
+		if len(s.dashes) > 0 {
+			dashes := make([]float64, len(s.dashes))
+			for i, d := range s.dashes {
+				dashes[i] = d * m.scale()
+			}
+			lines = dash(subpaths, dashes)
+		}
+		r.fill(stroke(lines, width, s.lineCap), c)
+	}
+	r.markers(subpaths, m, s)
+}
+
+// color returns the color of a paint, gradients are filled with the
+// average color of their stops.
+func (r *renderer) color(p paint, opacity float64) (color.NRGBA, bool) {
+	if p.none {
+		return color.NRGBA{}, false
+	}
+	c := p.color
+	if p.url != "" {
+		var ok bool
+		if c, ok = r.gradient(p.url); !ok {
+			return color.NRGBA{}, false
+		}
+	}
+	c.A = uint8(math.Round(float64(c.A) * math.Max(0, math.Min(1, opacity))))
+	return c, c.A > 0
+}
+
+func (r *renderer) gradient(id string) (color.NRGBA, bool) {
+	for depth := 0; depth < maxDepth; depth++ {
+		g, ok := r.doc.ids[id]
+		if !ok {
+			return color.NRGBA{}, false
+		}
+		var sum [4]float64
+		n := 0
+		for _, stop := range g.children {
+			if stop.name != "stop" {
+				continue
+			}
+			stopColor, stopOpacity := color.NRGBA{A: 255}, 1.0
+			for _, decl := range append([][2]string{
+				{"stop-color", stop.attrs["stop-color"]},
+				{"stop-opacity", stop.attrs["stop-opacity"]},
+			}, declarations(stop.attrs["style"])...) {
+				switch decl[0] {
+				case "stop-color":
+					if c, ok := parseColor(decl[1]); ok {
+						stopColor = c
+					}
+				case "stop-opacity":
+					if n, ok := parseOpacity(decl[1]); ok {
+						stopOpacity = n
+					}
+				}
+			}
+			a := float64(stopColor.A) * stopOpacity
+			sum[0] += float64(stopColor.R) * a
+			sum[1] += float64(stopColor.G) * a
+			sum[2] += float64(stopColor.B) * a
+			sum[3] += a
+			n++
+		}
+		if n == 0 {
+			// The stops may be those of another gradient.
+			id = strings.TrimPrefix(g.attrs["href"], "#")
+			continue
+		}
+		if sum[3] == 0 {
+			return color.NRGBA{}, true
+		}
+		return color.NRGBA{
+			uint8(sum[0] / sum[3]),
+			uint8(sum[1] / sum[3]),
+			uint8(sum[2] / sum[3]),
+			uint8(sum[3] / float64(n)),
+		}, true
+	}
+	return color.NRGBA{}, false
+}
+
+// fill fills subpaths in device space. Overlapping subpaths going the same
+// way add up, those going opposite ways cut holes into each other.
+func (r *renderer) fill(subpaths []subpath, c color.NRGBA) {
+	b := r.dst.Bounds()
+	z := vector.NewRasterizer(b.Dx(), b.Dy())
+	drawn := false
+	for _, sp := range subpaths {
+		if len(sp.points) < 3 {
+			continue
+		}
+		z.MoveTo(float32(sp.points[0].x), float32(sp.points[0].y))
+		for _, p := range sp.points[1:] {
+			z.LineTo(float32(p.x), float32(p.y))
+		}
+		z.ClosePath()
+		drawn = true
+	}
+	if drawn {
+		z.Draw(r.dst, b, image.NewUniform(c), image.Point{})
+	}
+}
+
+// dash splits lines into dashes, the lengths of the dashes and gaps
+// alternate as given.
+func dash(subpaths []subpath, dashes []float64) []subpath {
+	if len(dashes)%2 == 1 {
+		dashes = append(dashes, dashes...)
+	}
+	total := 0.0
+	for _, d := range dashes {
+		if d < 0 {
+			return subpaths
+		}
+		total += d
+	}
+	if total <= 0 {
+		return subpaths
+	}
+
+	var out []subpath
+	for _, sp := range subpaths {
+		pts := sp.points
+		if sp.closed && len(pts) > 0 {
+			pts = append(slices.Clone(pts), pts[0])
+		}
+		i, left, on := 0, dashes[0], true
+		var cur []point
+		if len(pts) > 0 {
+			cur = []point{pts[0]}
+		}
+		for j := 0; j+1 < len(pts); j++ {
+			p, q := pts[j], pts[j+1]
+			l := q.sub(p).length()
+			pos := 0.0
+			for l-pos > left {
+				pos += left
+				x := p.lerp(q, pos/l)
+				if on {
+					out = append(out, subpath{points: append(cur, x)})
+				}
+				cur = []point{x}
+				i = (i + 1) % len(dashes)
+				left, on = dashes[i], !on
+			}
+			left -= l - pos
+			cur = append(cur, q)
+		}
+		if on && len(cur) > 1 {
+			out = append(out, subpath{points: cur})
+		}
+	}
+	return out
+}
+
+// stroke returns the outline of lines of the given width as polygons which
+// all go the same way: a quadrilateral for every segment and a circle for
+// every join, so that lines meet without gaps.
+func stroke(subpaths []subpath, width float64, lineCap string) []subpath {
+	hw := width / 2
+	var polygons []subpath
+	circle := func(c point) {
+		n := max(8, min(32, int(width)))
+		pts := make([]point, n)
+		for i := range pts {
+			// The circle goes the same way as the quadrilaterals.
+			s, cos := math.Sincos(-2 * math.Pi * float64(i) / float64(n))
+			pts[i] = point{c.x + hw*cos, c.y + hw*s}
+		}
+		polygons = append(polygons, subpath{points: pts, closed: true})
+	}
+
+	for _, sp := range subpaths {
+		pts := sp.points
+		if sp.closed && len(pts) > 1 && pts[0] != pts[len(pts)-1] {
+			pts = append(slices.Clone(pts), pts[0])
+		}
+		last := len(pts) - 2
+		for i := 0; i <= last; i++ {
+			p, q := pts[i], pts[i+1]
+			d := q.sub(p)
+			l := d.length()
+			if l == 0 {
+				continue
+			}
+			n := point{-d.y, d.x}.scale(hw / l)
+			if lineCap == "square" && !sp.closed {
+				if i == 0 {
+					p = p.sub(d.scale(hw / l))
+				}
+				if i == last {
+					q = q.add(d.scale(hw / l))
+				}
+			}
+			polygons = append(polygons, subpath{
+				points: []point{p.add(n), q.add(n), q.sub(n), p.sub(n)},
+				closed: true,
+			})
+		}
+
+		for i, p := range pts {
+			end := i == 0 || i == len(pts)-1
+			if !end || sp.closed || lineCap == "round" {
+				circle(p)
+			}
+		}
+	}
+	return polygons
+}
+
+// markers draws the markers at the start and end of a path.
+func (r *renderer) markers(subpaths []subpath, m matrix, s style) {
+	if len(subpaths) == 0 {
+		return
+	}
+	if s.markerStart != "" {
+		pts := subpaths[0].points
+		if len(pts) > 1 {
+			r.marker(s.markerStart, pts[0], pts[1].sub(pts[0]), true, m, s)
+		}
+	}
+	if s.markerEnd != "" {
+		pts := subpaths[len(subpaths)-1].points
+		if n := len(pts); n > 1 {
+			r.marker(s.markerEnd, pts[n-1], pts[n-1].sub(pts[n-2]), false, m, s)
+		}
+	}
+}
+
+// marker draws the marker with the given id at a point of a path in device
+// space, turned in the direction of the path.
+func (r *renderer) marker(id string, at, dir point, start bool, m matrix, s style) {
+	e, ok := r.doc.ids[id]
+	if !ok || e.name != "marker" || r.depth >= maxDepth {
+		return
+	}
+
+	angle := 0.0
+	switch orient := e.attrs["orient"]; orient {
+	case "auto", "auto-start-reverse":
+		angle = math.Atan2(dir.y, dir.x) * 180 / math.Pi
+		if start && orient == "auto-start-reverse" {
+			angle += 180
+		}
+	default:
+		angle = numbersOr(orient, 0)
+	}
+
+	f := m.scale()
+	if e.attrs["markerUnits"] != "userSpaceOnUse" {
+		f *= s.strokeWidth
+	}
+	w, h := 3.0, 3.0
+	if _, ok := e.attrs["markerWidth"]; ok {
+		w = r.length(e, "markerWidth", 'x')
+	}
+	if _, ok := e.attrs["markerHeight"]; ok {
+		h = r.length(e, "markerHeight", 'y')
+	}
+	t := translate(at.x, at.y).mul(rotation(angle)).mul(scaling(f, f))
+	if vb := numbers(e.attrs["viewBox"]); len(vb) == 4 && vb[2] > 0 && vb[3] > 0 {
+		k := math.Min(w/vb[2], h/vb[3])
+		t = t.mul(scaling(k, k))
+	}
+	t = t.mul(translate(-numbersOr(e.attrs["refX"], 0), -numbersOr(e.attrs["refY"], 0)))
+
+	r.depth++
+	ms := r.style(e, defaultStyle)
+	for _, child := range e.children {
+		r.render(child, t, ms)
+	}
+	r.depth--
+}
+
+func numbersOr(s string, def float64) float64 {
+	if ns := numbers(s); len(ns) > 0 {
+		return ns[0]
+	}
+	return def
+}
+
+// text draws a text element and its tspan elements. Text is set in the Go
+// fonts, turned and skewed text is drawn upright.
+func (r *renderer) text(e *element, m matrix, s style) {
+	cursor := point{r.length(e, "x", 'x'), r.length(e, "y", 'y')}
+	r.textRuns(e, m, s, &cursor, true)
+}
+
+func (r *renderer) textRuns(e *element, m matrix, s style, cursor *point, first bool) {
+	for _, child := range e.children {
+		switch child.name {
+		case "#text":
+			text := strings.Join(strings.Fields(child.text), " ")
+			if text == "" {
+				continue
+			}
+			if !first && strings.TrimLeft(child.text, " \t\r\n") != child.text {
+				text = " " + text
+			}
+			r.drawText(text, m, s, cursor, first)
+			first = false
+		case "tspan", "textPath":
+			cs := r.style(child, s)
+			if !cs.display {
+				continue
+			}
+			newChunk := false
+			if xs := numbers(child.attrs["x"]); len(xs) > 0 {
+				cursor.x, newChunk = xs[0], true
+			}
+			if ys := numbers(child.attrs["y"]); len(ys) > 0 {
+				cursor.y = ys[0]
+			}
+			if dy := numbers(child.attrs["dy"]); len(dy) > 0 {
+				cursor.y += dy[0]
+			}
+			if dx := numbers(child.attrs["dx"]); len(dx) > 0 {
+				cursor.x += dx[0]
+			}
+			r.textRuns(child, m, cs, cursor, first || newChunk)
+			first = false
+		}
+	}
+}
+
+// drawText draws a run of text at the cursor and moves the cursor past it.
+// Runs which start a line are anchored by the text-anchor property.
+func (r *renderer) drawText(text string, m matrix, s style, cursor *point, anchored bool) {
+	c, ok := r.color(s.fill, s.fillOpacity*s.opacity)
+	regular, bold := fonts()
+	f := regular
+	if s.fontWeight == "bold" || s.fontWeight == "bolder" || s.fontWeight >= "600" && s.fontWeight <= "900" {
+		f = bold
+	}
+	size := s.fontSize * m.scale()
+	if f == nil || size <= 0 {
+		return
+	}
+	face := truetype.NewFace(f, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingNone})
+	defer face.Close()
+
+	width := float64(font.MeasureString(face, text)) / 64
+	at := m.apply(*cursor)
+	if anchored {
+		switch s.textAnchor {
+		case "middle":
+			at.x -= width / 2
+		case "end":
+			at.x -= width
+		}
+	}
+	if ok {
+		d := font.Drawer{
+			Dst:  r.dst,
+			Src:  image.NewUniform(c),
+			Face: face,
+			Dot:  fixed.Point26_6{X: fixed.Int26_6(at.x * 64), Y: fixed.Int26_6(at.y * 64)},
+		}
+		d.DrawString(text)
+	}
+	// The cursor is in user space.
+	cursor.x += (at.x + width - m.apply(*cursor).x) / m.scale()
+}
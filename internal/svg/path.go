@@ -0,0 +1,396 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+type point struct{ x, y float64 }
+
+func (p point) add(q point) point             { return point{p.x + q.x, p.y + q.y} }
+func (p point) sub(q point) point             { return point{p.x - q.x, p.y - q.y} }
+func (p point) scale(f float64) point         { return point{p.x * f, p.y * f} }
+func (p point) length() float64               { return math.Hypot(p.x, p.y) }
+func (p point) lerp(q point, t float64) point { return p.add(q.sub(p).scale(t)) }
+
+// matrix is an affine transform [a b c d e f], which maps (x, y) to
+// (a*x + c*y + e, b*x + d*y + f) like the SVG matrix() transform.
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns the transform which applies n and then m.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (m matrix) apply(p point) point {
+	return point{m[0]*p.x + m[2]*p.y + m[4], m[1]*p.x + m[3]*p.y + m[5]}
+}
+
+// scale returns how much the transform enlarges lengths on average.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+func translate(x, y float64) matrix { return matrix{1, 0, 0, 1, x, y} }
+func scaling(x, y float64) matrix   { return matrix{x, 0, 0, y, 0, 0} }
+func rotation(deg float64) matrix {
+	s, c := math.Sincos(deg * math.Pi / 180)
+	return matrix{c, s, -s, c, 0, 0}
+}
+
+// parseTransform parses a transform attribute such as
+// "translate(10 20) rotate(45)".
+func parseTransform(s string) matrix {
+	m := identity
+	for {
+		open := strings.IndexByte(s, '(')
+		close := strings.IndexByte(s, ')')
+		if open < 0 || close < open {
+			return m
+		}
+		name := strings.TrimSpace(strings.Trim(s[:open], ", \t\n"))
+		args := numbers(s[open+1 : close])
+		s = s[close+1:]
+		arg := func(i int, def float64) float64 {
+			if i < len(args) {
+				return args[i]
+			}
+			return def
+		}
+
+		var t matrix
+		switch name {
+		case "matrix":
+			if len(args) != 6 {
+				continue
+			}
+			copy(t[:], args)
+		case "translate":
+			t = translate(arg(0, 0), arg(1, 0))
+		case "scale":
+			t = scaling(arg(0, 1), arg(1, arg(0, 1)))
+		case "rotate":
+			cx, cy := arg(1, 0), arg(2, 0)
+			t = translate(cx, cy).mul(rotation(arg(0, 0))).mul(translate(-cx, -cy))
+		case "skewX":
+			t = matrix{1, 0, math.Tan(arg(0, 0) * math.Pi / 180), 1, 0, 0}
+		case "skewY":
+			t = matrix{1, math.Tan(arg(0, 0) * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			continue
+		}
+		m = m.mul(t)
+	}
+}
+
+// numbers parses a list of numbers separated by spaces or commas, such as
+// the points of a polygon.
+func numbers(s string) []float64 {
+	sc := scanner{s: s}
+	var ns []float64
+	for {
+		n, ok := sc.number()
+		if !ok {
+			return ns
+		}
+		ns = append(ns, n)
+	}
+}
+
+// scanner reads the numbers of path data, which need not be separated when
+// the sign or decimal point of a number starts the next, e.g. "1-2.5.5".
+type scanner struct {
+	s   string
+	pos int
+}
+
+func (sc *scanner) skip() {
+	for sc.pos < len(sc.s) && strings.IndexByte(" \t\r\n,", sc.s[sc.pos]) >= 0 {
+		sc.pos++
+	}
+}
+
+func (sc *scanner) number() (float64, bool) {
+	sc.skip()
+	start, i := sc.pos, sc.pos
+	if i < len(sc.s) && (sc.s[i] == '+' || sc.s[i] == '-') {
+		i++
+	}
+	digits, dot := false, false
+	for ; i < len(sc.s); i++ {
+		c := sc.s[i]
+		if c >= '0' && c <= '9' {
+			digits = true
+		} else if c == '.' && !dot {
+			dot = true
+		} else {
+			break
+		}
+	}
+	if !digits {
+		return 0, false
+	}
+	if i < len(sc.s) && (sc.s[i] == 'e' || sc.s[i] == 'E') {
+		j := i + 1
+		if j < len(sc.s) && (sc.s[j] == '+' || sc.s[j] == '-') {
+			j++
+		}
+		if j < len(sc.s) && sc.s[j] >= '0' && sc.s[j] <= '9' {
+			for i = j; i < len(sc.s) && sc.s[i] >= '0' && sc.s[i] <= '9'; i++ {
+			}
+		}
+	}
+	n, err := strconv.ParseFloat(sc.s[start:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	sc.pos = i
+	return n, true
+}
+
+// flag reads the large arc and sweep flags of arcs, which are single digits
+// that may be written without separators.
+func (sc *scanner) flag() (bool, bool) {
+	sc.skip()
+	if sc.pos < len(sc.s) && (sc.s[sc.pos] == '0' || sc.s[sc.pos] == '1') {
+		sc.pos++
+		return sc.s[sc.pos-1] == '1', true
+	}
+	return false, false
+}
+
+// subpath is a flattened part of a path in device space.
+type subpath struct {
+	points []point
+	closed bool
+}
+
+// pathBuilder flattens paths in user space into subpaths in device space.
+// Curves are flattened after they are transformed, so that they are smooth
+// at any size.
+type pathBuilder struct {
+	m        matrix
+	subpaths []subpath
+	start    point // user space start of the current subpath
+	current  point // user space current point
+}
+
+func (b *pathBuilder) moveTo(p point) {
+	b.subpaths = append(b.subpaths, subpath{points: []point{b.m.apply(p)}})
+	b.start, b.current = p, p
+}
+
+func (b *pathBuilder) lineTo(p point) {
+	// A path goes on from the start of a closed subpath in a new one.
+	if len(b.subpaths) == 0 || b.subpaths[len(b.subpaths)-1].closed {
+		b.moveTo(b.current)
+	}
+	sp := &b.subpaths[len(b.subpaths)-1]
+	sp.points = append(sp.points, b.m.apply(p))
+	b.current = p
+}
+
+func (b *pathBuilder) close() {
+	if len(b.subpaths) == 0 {
+		return
+	}
+	b.subpaths[len(b.subpaths)-1].closed = true
+	b.current = b.start
+}
+
+// segments returns how many lines a curve of the given device length is
+// flattened into.
+func segments(length float64) int {
+	return max(2, min(64, int(length/3)))
+}
+
+func (b *pathBuilder) cubicTo(c1, c2, p point) {
+	p0 := b.current
+	d0, d1, d2, d3 := b.m.apply(p0), b.m.apply(c1), b.m.apply(c2), b.m.apply(p)
+	n := segments(d1.sub(d0).length() + d2.sub(d1).length() + d3.sub(d2).length())
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		a, bb, c := p0.lerp(c1, t), c1.lerp(c2, t), c2.lerp(p, t)
+		b.lineTo(a.lerp(bb, t).lerp(bb.lerp(c, t), t))
+	}
+}
+
+func (b *pathBuilder) quadTo(c, p point) {
+	p0 := b.current
+	b.cubicTo(p0.lerp(c, 2.0/3), p.lerp(c, 2.0/3), p)
+}
+
+// arcTo draws an elliptical arc as described in the implementation notes of
+// the SVG specification, converting its endpoints to a center.
+func (b *pathBuilder) arcTo(rx, ry, angle float64, large, sweep bool, p point) {
+	p0 := b.current
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || p0 == p {
+		b.lineTo(p)
+		return
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	dx, dy := (p0.x-p.x)/2, (p0.y-p.y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	f := math.Sqrt(math.Max(num, 0) / den)
+	if large == sweep {
+		f = -f
+	}
+	cx1, cy1 := f*rx*y1/ry, -f*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (p0.x+p.x)/2
+	cy := sin*cx1 + cos*cy1 + (p0.y+p.y)/2
+
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	n := segments(math.Abs(delta) * math.Max(rx, ry) * b.m.scale())
+	for i := 1; i <= n; i++ {
+		s, c := math.Sincos(theta + delta*float64(i)/float64(n))
+		b.lineTo(point{cx + rx*c*cos - ry*s*sin, cy + rx*c*sin + ry*s*cos})
+	}
+	b.current = p
+}
+
+// ellipse adds a closed ellipse.
+func (b *pathBuilder) ellipse(cx, cy, rx, ry float64) {
+	b.moveTo(point{cx + rx, cy})
+	b.arcTo(rx, ry, 0, false, true, point{cx - rx, cy})
+	b.arcTo(rx, ry, 0, false, true, point{cx + rx, cy})
+	b.close()
+}
+
+// parsePath adds the path data of a path element, such as "M0 0 L10 10 Z".
+func (b *pathBuilder) parsePath(d string) {
+	sc := scanner{s: d}
+	var cmd byte
+	var ctrl point // last control point, for smooth curves
+	var last byte  // last command, for smooth curves
+	for {
+		sc.skip()
+		if sc.pos >= len(sc.s) {
+			return
+		}
+		if c := sc.s[sc.pos]; strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", c) >= 0 {
+			cmd = c
+			sc.pos++
+		} else if cmd == 0 {
+			return
+		}
+
+		rel := cmd >= 'a'
+		origin := point{}
+		if rel {
+			origin = b.current
+		}
+		pt := func() (point, bool) {
+			x, ok1 := sc.number()
+			y, ok2 := sc.number()
+			return origin.add(point{x, y}), ok1 && ok2
+		}
+
+		ok := true
+		switch cmd | 0x20 {
+		case 'm':
+			var p point
+			if p, ok = pt(); ok {
+				b.moveTo(p)
+				// Further coordinates are lines.
+				cmd = 'L' | (cmd & 0x20)
+			}
+		case 'l':
+			var p point
+			if p, ok = pt(); ok {
+				b.lineTo(p)
+			}
+		case 'h':
+			var x float64
+			if x, ok = sc.number(); ok {
+				b.lineTo(point{origin.x + x, b.current.y})
+			}
+		case 'v':
+			var y float64
+			if y, ok = sc.number(); ok {
+				b.lineTo(point{b.current.x, origin.y + y})
+			}
+		case 'c':
+			c1, ok1 := pt()
+			c2, ok2 := pt()
+			p, ok3 := pt()
+			if ok = ok1 && ok2 && ok3; ok {
+				b.cubicTo(c1, c2, p)
+				ctrl = c2
+			}
+		case 's':
+			c1 := b.current
+			if last|0x20 == 'c' || last|0x20 == 's' {
+				c1 = b.current.add(b.current.sub(ctrl))
+			}
+			c2, ok1 := pt()
+			p, ok2 := pt()
+			if ok = ok1 && ok2; ok {
+				b.cubicTo(c1, c2, p)
+				ctrl = c2
+			}
+		case 'q':
+			c, ok1 := pt()
+			p, ok2 := pt()
+			if ok = ok1 && ok2; ok {
+				b.quadTo(c, p)
+				ctrl = c
+			}
+		case 't':
+			c := b.current
+			if last|0x20 == 'q' || last|0x20 == 't' {
+				c = b.current.add(b.current.sub(ctrl))
+			}
+			var p point
+			if p, ok = pt(); ok {
+				b.quadTo(c, p)
+				ctrl = c
+			}
+		case 'a':
+			rx, ok1 := sc.number()
+			ry, ok2 := sc.number()
+			angle, ok3 := sc.number()
+			large, ok4 := sc.flag()
+			sweep, ok5 := sc.flag()
+			p, ok6 := pt()
+			if ok = ok1 && ok2 && ok3 && ok4 && ok5 && ok6; ok {
+				b.arcTo(rx, ry, angle, large, sweep, p)
+			}
+		case 'z':
+			b.close()
+			// Z takes no coordinates, a number after it is an error.
+			sc.skip()
+			if sc.pos < len(sc.s) && strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", sc.s[sc.pos]) < 0 {
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+		last = cmd
+	}
+}
@@ -0,0 +1,324 @@
+package svg
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// defaultFontSize is the font size of text without one, like in browsers.
+const defaultFontSize = 16
+
+// parseColor parses a CSS color: a name, #rgb, #rrggbb, #rrggbbaa, rgb() or
+// rgba().
+func parseColor(s string) (color.NRGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "transparent" {
+		return color.NRGBA{}, true
+	}
+	if c, ok := colornames.Map[s]; ok {
+		return color.NRGBA{c.R, c.G, c.B, c.A}, true
+	}
+
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		if len(hex) == 3 || len(hex) == 4 {
+			var long strings.Builder
+			for _, r := range hex {
+				long.WriteString(string(r) + string(r))
+			}
+			hex = long.String()
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if len(hex) != 8 || err != nil {
+			return color.NRGBA{}, false
+		}
+		return color.NRGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}, true
+	}
+
+	open, close := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open < 0 || close < open || !strings.HasPrefix(s, "rgb") {
+		return color.NRGBA{}, false
+	}
+	args := strings.FieldsFunc(s[open+1:close], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '/'
+	})
+	if len(args) < 3 {
+		return color.NRGBA{}, false
+	}
+	channel := func(s string, full float64) uint8 {
+		if p, ok := strings.CutSuffix(s, "%"); ok {
+			n, _ := strconv.ParseFloat(p, 64)
+			return uint8(math.Round(math.Max(0, math.Min(100, n)) * 2.55))
+		}
+		n, _ := strconv.ParseFloat(s, 64)
+		return uint8(math.Round(math.Max(0, math.Min(full, n)) * 255 / full))
+	}
+	c := color.NRGBA{channel(args[0], 255), channel(args[1], 255), channel(args[2], 255), 255}
+	if len(args) > 3 {
+		c.A = channel(args[3], 1)
+	}
+	return c, true
+}
+
+// parseLength parses a length in user units. Percentages are of ref.
+func parseLength(s string, ref float64) (float64, bool) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		unit   string
+		factor float64
+	}{
+		{"px", 1}, {"pt", 4.0 / 3}, {"pc", 16}, {"mm", 96 / 25.4}, {"cm", 96 / 2.54}, {"in", 96},
+		{"rem", defaultFontSize}, {"em", defaultFontSize}, {"ex", defaultFontSize / 2},
+		{"%", ref / 100},
+	}
+	factor := 1.0
+	for _, u := range units {
+		if n, ok := strings.CutSuffix(s, u.unit); ok {
+			s, factor = n, u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return n * factor, err == nil
+}
+
+// parseOpacity parses an opacity, a number or a percentage.
+func parseOpacity(s string) (float64, bool) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		n, err := strconv.ParseFloat(p, 64)
+		return n / 100, err == nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+// paint is how a shape is filled or stroked.
+type paint struct {
+	none  bool
+	color color.NRGBA
+	// url is the id of a gradient.
+	url string
+}
+
+func parsePaint(s string, current color.NRGBA) (paint, bool) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "none":
+		return paint{none: true}, true
+	case s == "currentColor":
+		return paint{color: current}, true
+	case strings.HasPrefix(s, "url("):
+		id, _, _ := strings.Cut(strings.TrimPrefix(s, "url("), ")")
+		return paint{url: strings.TrimPrefix(strings.Trim(id, `'" `), "#")}, true
+	}
+	c, ok := parseColor(s)
+	return paint{color: c}, ok
+}
+
+// style holds the properties of an element which it inherits from its
+// parents.
+type style struct {
+	fill          paint
+	stroke        paint
+	color         color.NRGBA
+	opacity       float64
+	fillOpacity   float64
+	strokeOpacity float64
+	strokeWidth   float64
+	lineCap       string
+	dashes        []float64
+	fontSize      float64
+	fontWeight    string
+	textAnchor    string
+	markerStart   string
+	markerEnd     string
+	display       bool
+}
+
+var defaultStyle = style{
+	fill:          paint{color: color.NRGBA{A: 255}},
+	stroke:        paint{none: true},
+	color:         color.NRGBA{A: 255},
+	opacity:       1,
+	fillOpacity:   1,
+	strokeOpacity: 1,
+	strokeWidth:   1,
+	fontSize:      defaultFontSize,
+	textAnchor:    "start",
+	display:       true,
+}
+
+// apply sets a property of the style from a presentation attribute or CSS
+// declaration.
+func (s *style) apply(name, value string) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+	if value == "inherit" || value == "" {
+		return
+	}
+	switch name {
+	case "fill":
+		if p, ok := parsePaint(value, s.color); ok {
+			s.fill = p
+		}
+	case "stroke":
+		if p, ok := parsePaint(value, s.color); ok {
+			s.stroke = p
+		}
+	case "color":
+		if c, ok := parseColor(value); ok {
+			s.color = c
+		}
+	case "opacity":
+		if n, ok := parseOpacity(value); ok {
+			s.opacity *= n
+		}
+	case "fill-opacity":
+		if n, ok := parseOpacity(value); ok {
+			s.fillOpacity = n
+		}
+	case "stroke-opacity":
+		if n, ok := parseOpacity(value); ok {
+			s.strokeOpacity = n
+		}
+	case "stroke-width":
+		if n, ok := parseLength(value, 100); ok {
+			s.strokeWidth = n
+		}
+	case "stroke-linecap":
+		s.lineCap = value
+	case "stroke-dasharray":
+		s.dashes = nil
+		if value != "none" {
+			s.dashes = numbers(value)
+		}
+	case "font-size":
+		if n, ok := parseLength(value, s.fontSize); ok {
+			s.fontSize = n
+		}
+	case "font-weight":
+		s.fontWeight = value
+	case "font":
+		// Only the size is taken from the shorthand, e.g. "bold 14px sans".
+		for _, f := range strings.Fields(value) {
+			if n, ok := parseLength(f, s.fontSize); ok && strings.IndexAny(f, "0123456789") == 0 {
+				s.fontSize = n
+			} else if f == "bold" {
+				s.fontWeight = f
+			}
+		}
+	case "text-anchor":
+		s.textAnchor = value
+	case "marker-start":
+		s.markerStart = markerID(value)
+	case "marker-end":
+		s.markerEnd = markerID(value)
+	case "marker":
+		s.markerStart, s.markerEnd = markerID(value), markerID(value)
+	case "display":
+		s.display = value != "none"
+	case "visibility":
+		s.display = value != "hidden" && value != "collapse"
+	}
+}
+
+func markerID(value string) string {
+	p, _ := parsePaint(value, color.NRGBA{})
+	return p.url
+}
+
+// declarations parses the declarations of a style attribute or CSS rule,
+// such as "fill: red; stroke: none".
+func declarations(s string) [][2]string {
+	var decls [][2]string
+	for _, decl := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if ok {
+			decls = append(decls, [2]string{strings.TrimSpace(name), strings.TrimSpace(value)})
+		}
+	}
+	return decls
+}
+
+// rule is a CSS rule of a style element with a simple selector: an element
+// name, a class, an id or an element name with a class.
+type rule struct {
+	name, class, id string
+	specificity     int
+	decls           [][2]string
+}
+
+func (r rule) matches(e *element) bool {
+	if r.name != "" && r.name != "*" && r.name != e.name {
+		return false
+	}
+	if r.id != "" && r.id != e.attrs["id"] {
+		return false
+	}
+	if r.class != "" {
+		for _, c := range strings.Fields(e.attrs["class"]) {
+			if c == r.class {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// parseCSS parses the rules of style elements. Selectors with combinators,
+// attributes or pseudo-classes are not supported and skipped.
+func parseCSS(css string) []rule {
+	var rules []rule
+	for {
+		css = stripComments(css)
+		open := strings.IndexByte(css, '{')
+		close := strings.IndexByte(css, '}')
+		if open < 0 || close < open {
+			return rules
+		}
+		selectors, body := css[:open], css[open+1:close]
+		css = css[close+1:]
+		if strings.HasPrefix(strings.TrimSpace(selectors), "@") {
+			continue
+		}
+		decls := declarations(body)
+		for _, sel := range strings.Split(selectors, ",") {
+			sel = strings.TrimSpace(sel)
+			if sel == "" || strings.ContainsAny(sel, " >+~[:") {
+				continue
+			}
+			r := rule{decls: decls}
+			name, id, hasID := strings.Cut(sel, "#")
+			name, class, hasClass := strings.Cut(name, ".")
+			r.name = name
+			if hasID {
+				r.id, r.specificity = id, 100
+			} else if hasClass {
+				r.class, r.specificity = class, 10
+			} else {
+				r.specificity = 1
+			}
+			rules = append(rules, r)
+		}
+	}
+}
+
+func stripComments(css string) string {
+	for {
+		start := strings.Index(css, "/*")
+		if start < 0 {
+			return css
+		}
+		end := strings.Index(css[start:], "*/")
+		if end < 0 {
+			return css[:start]
+		}
+		css = css[:start] + css[start+end+2:]
+	}
+}
@@ -0,0 +1,191 @@
+// Package svg rasterizes SVG images. It registers the format with the image
+// package, so that image.Decode reads SVG documents once the package is
+// imported.
+//
+// Shapes, paths, strokes with dashes, markers, text, groups, use elements,
+// transforms and simple CSS rules are supported. Gradients are filled with
+// the average color of their stops; filters, masks, clipping paths and
+// embedded images are ignored.
+package svg
+
+import (
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// maxSize is the largest width or height an SVG document is rasterized at
+// by Decode.
+const maxSize = 2048
+
+var errNotSVG = errors.New("svg: not an SVG document")
+
+func init() {
+	image.RegisterFormat("svg", "<svg", Decode, DecodeConfig)
+	image.RegisterFormat("svg", "<?xml", Decode, DecodeConfig)
+	image.RegisterFormat("svg", "<!--", Decode, DecodeConfig)
+}
+
+// Image is an SVG document rasterized at its own size. Rasterize renders it
+// again at another size, so that it stays sharp when it is enlarged.
+type Image struct {
+	*image.RGBA
+	doc *document
+}
+
+// Rasterize renders the document at the given size in pixels. The document
+// keeps its aspect ratio and is centered.
+func (img *Image) Rasterize(width, height int) *image.RGBA {
+	return img.doc.rasterize(max(width, 1), max(height, 1))
+}
+
+// Decode reads an SVG document and rasterizes it at its own size.
+func Decode(r io.Reader) (image.Image, error) {
+	doc, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	w, h := doc.size()
+	return &Image{RGBA: doc.rasterize(w, h), doc: doc}, nil
+}
+
+// DecodeConfig returns the size of an SVG document.
+func DecodeConfig(r io.Reader) (image.Config, error) {
+	doc, err := parse(r)
+	if err != nil {
+		return image.Config{}, err
+	}
+	w, h := doc.size()
+	return image.Config{ColorModel: color.RGBAModel, Width: w, Height: h}, nil
+}
+
+// element is an element of the document. Character data is kept in
+// elements named #text, so that it stays in order with tspan elements.
+type element struct {
+	name     string
+	attrs    map[string]string
+	children []*element
+	text     string
+}
+
+type document struct {
+	root  *element
+	ids   map[string]*element
+	rules []rule
+	// width and height are the size of the document in pixels, viewBox
+	// is the part of the user space which is shown.
+	width, height float64
+	viewBox       [4]float64
+}
+
+func parse(r io.Reader) (*document, error) {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	doc := &document{ids: map[string]*element{}}
+	var stack []*element
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e := &element{name: tok.Name.Local, attrs: map[string]string{}}
+			for _, a := range tok.Attr {
+				e.attrs[a.Name.Local] = a.Value
+			}
+			if id := e.attrs["id"]; id != "" {
+				doc.ids[id] = e
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			} else if doc.root == nil {
+				doc.root = e
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				e := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if e.name == "style" {
+					doc.rules = append(doc.rules, parseCSS(e.text)...)
+				}
+			}
+		case xml.CharData:
+			if len(stack) == 0 {
+				continue
+			}
+			e := stack[len(stack)-1]
+			switch e.name {
+			case "style":
+				e.text += string(tok)
+			case "text", "tspan", "textPath":
+				e.children = append(e.children, &element{name: "#text", text: string(tok)})
+			}
+		}
+	}
+	if doc.root == nil || doc.root.name != "svg" {
+		return nil, errNotSVG
+	}
+
+	vb := numbers(doc.root.attrs["viewBox"])
+	hasViewBox := len(vb) == 4 && vb[2] > 0 && vb[3] > 0
+	w, okW := parseLength(doc.root.attrs["width"], 0)
+	h, okH := parseLength(doc.root.attrs["height"], 0)
+	if strings.HasSuffix(doc.root.attrs["width"], "%") {
+		okW = false
+	}
+	if strings.HasSuffix(doc.root.attrs["height"], "%") {
+		okH = false
+	}
+	switch {
+	case okW && okH:
+	case hasViewBox && okW:
+		h = w * vb[3] / vb[2]
+	case hasViewBox && okH:
+		w = h * vb[2] / vb[3]
+	case hasViewBox:
+		w, h = vb[2], vb[3]
+	default:
+		w, h = 300, 150
+	}
+	doc.width, doc.height = math.Max(w, 1), math.Max(h, 1)
+	if hasViewBox {
+		copy(doc.viewBox[:], vb)
+	} else {
+		doc.viewBox = [4]float64{0, 0, doc.width, doc.height}
+	}
+	return doc, nil
+}
+
+// size returns the size of the document in whole pixels, no larger than
+// maxSize.
+func (doc *document) size() (int, int) {
+	f := math.Min(1, maxSize/math.Max(doc.width, doc.height))
+	return max(int(math.Ceil(doc.width*f)), 1), max(int(math.Ceil(doc.height*f)), 1)
+}
+
+func (doc *document) rasterize(width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	vb := doc.viewBox
+	f := math.Min(float64(width)/vb[2], float64(height)/vb[3])
+	m := translate((float64(width)-vb[2]*f)/2, (float64(height)-vb[3]*f)/2).
+		mul(scaling(f, f)).
+		mul(translate(-vb[0], -vb[1]))
+
+	r := renderer{doc: doc, dst: dst}
+	r.render(doc.root, m, defaultStyle)
+	return dst
+}
@@ -0,0 +1,109 @@
+package svg_test
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/svg"
+)
+
+func decode(t *testing.T, src string) image.Image {
+	t.Helper()
+	img, format, err := image.Decode(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "svg" {
+		t.Fatalf("expected svg format, got %s", format)
+	}
+	return img
+}
+
+func rgba(img image.Image, x, y int) color.RGBA {
+	return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+}
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		src  string
+		w, h int
+	}{
+		{`<svg width="40" height="20"></svg>`, 40, 20},
+		{`<svg viewBox="0 0 100 50"></svg>`, 100, 50},
+		{`<svg width="200" viewBox="0 0 100 50"></svg>`, 200, 100},
+		{`<?xml version="1.0"?><svg width="1in" height="72pt"></svg>`, 96, 96},
+		{`<svg></svg>`, 300, 150},
+	}
+	for _, tt := range tests {
+		cfg, _, err := image.DecodeConfig(strings.NewReader(tt.src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Width != tt.w || cfg.Height != tt.h {
+			t.Errorf("%s: expected %dx%d, got %dx%d", tt.src, tt.w, tt.h, cfg.Width, cfg.Height)
+		}
+	}
+}
+
+func TestNotSVG(t *testing.T) {
+	if _, err := svg.Decode(strings.NewReader(`<?xml version="1.0"?><html></html>`)); err == nil {
+		t.Error("expected an error for a document which is not SVG")
+	}
+}
+
+func TestShapes(t *testing.T) {
+	img := decode(t, `<svg width="100" height="100">
+		<style>.blue { fill: #0000ff }</style>
+		<rect x="0" y="0" width="50" height="50" fill="red"/>
+		<circle class="blue" cx="75" cy="75" r="20"/>
+		<path d="M50 0 h50 v50 z" fill="rgb(0, 128, 0)"/>
+		<line x1="0" y1="75" x2="50" y2="75" stroke="black" stroke-width="4"/>
+	</svg>`)
+
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{25, 25, color.RGBA{255, 0, 0, 255}},
+		{75, 75, color.RGBA{0, 0, 255, 255}},
+		{90, 10, color.RGBA{0, 128, 0, 255}},
+		{60, 40, color.RGBA{}},
+		{25, 75, color.RGBA{0, 0, 0, 255}},
+		{25, 85, color.RGBA{}},
+	}
+	for _, tt := range tests {
+		if got := rgba(img, tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel (%d, %d): expected %v, got %v", tt.x, tt.y, tt.want, got)
+		}
+	}
+}
+
+func TestTransformAndUse(t *testing.T) {
+	img := decode(t, `<svg width="100" height="100" viewBox="0 0 10 10">
+		<defs><rect id="r" width="2" height="2" fill="#0f0"/></defs>
+		<g transform="translate(5 5)"><use href="#r" x="1"/></g>
+	</svg>`)
+	if got := rgba(img, 70, 60); got != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("expected the used rectangle to be drawn, got %v", got)
+	}
+	if got := rgba(img, 10, 10); got != (color.RGBA{}) {
+		t.Errorf("expected the defs not to be drawn, got %v", got)
+	}
+}
+
+func TestRasterize(t *testing.T) {
+	img := decode(t, `<svg viewBox="0 0 10 10"><rect width="10" height="10"/></svg>`)
+	big := img.(*svg.Image).Rasterize(300, 100)
+	if b := big.Bounds(); b.Dx() != 300 || b.Dy() != 100 {
+		t.Fatalf("expected 300x100, got %v", b)
+	}
+	// The square is centered.
+	if got := rgba(big, 150, 50); got.A != 255 {
+		t.Errorf("expected the center to be filled, got %v", got)
+	}
+	if got := rgba(big, 20, 50); got.A != 0 {
+		t.Errorf("expected the sides to be empty, got %v", got)
+	}
+}
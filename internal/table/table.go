@@ -0,0 +1,247 @@
+// Package table renders CSV and TSV data as tables which fit the slide,
+// splitting long tables into pages.
+package table
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/styles"
+)
+
+// ErrEmpty is returned for tables without any rows.
+var ErrEmpty = errors.New("table is empty")
+
+// Options are the options of a table.
+type Options struct {
+	// Align holds the alignment of each column, l, c or r. Columns of
+	// numbers are aligned to the right by default, other columns to the
+	// left.
+	Align string
+	// Decimals is the number of decimals numbers are formatted with, along
+	// with commas between their thousands. Numbers are left as they are
+	// written when it is negative.
+	Decimals int
+	// Width and Height are the space available for the table. Wider tables
+	// are truncated and longer tables are split into pages.
+	Width  int
+	Height int
+	// Rows is the number of rows on each page, it is derived from the
+	// height when it is zero.
+	Rows int
+}
+
+// borderRows are the lines a table takes up besides its rows: the top and
+// bottom border, the header, the line below the header and the page number.
+const borderRows = 5
+
+// Parse reads CSV data, or TSV data if comma is a tab.
+func Parse(src string, comma rune) ([][]string, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimSpace(src)))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = comma != '\t'
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmpty
+	}
+	return records, nil
+}
+
+// Render renders the records, the first of which is the header, as pages of
+// a table. The records are formatted in place.
+func Render(records [][]string, opts Options) []string {
+	header, rows := records[0], records[1:]
+	columns := len(header)
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	for len(header) < columns {
+		header = append(header, "")
+	}
+
+	numeric := make([]bool, columns)
+	for c := range numeric {
+		numeric[c] = isNumeric(rows, c)
+		if numeric[c] && opts.Decimals >= 0 {
+			formatColumn(rows, c, opts.Decimals)
+		}
+	}
+
+	align := make([]lipgloss.Position, columns)
+	for c := range align {
+		align[c] = lipgloss.Left
+		if numeric[c] {
+			align[c] = lipgloss.Right
+		}
+		if c < len(opts.Align) {
+			switch opts.Align[c] {
+			case 'l':
+				align[c] = lipgloss.Left
+			case 'c':
+				align[c] = lipgloss.Center
+			case 'r':
+				align[c] = lipgloss.Right
+			}
+		}
+	}
+
+	// Every page has the same column widths, the widest columns are
+	// truncated to fit the table into the available width.
+	widths := make([]int, columns)
+	for c := range widths {
+		widths[c] = ansi.StringWidth(header[c])
+		for _, row := range rows {
+			widths[c] = max(widths[c], ansi.StringWidth(cell(row, c)))
+		}
+	}
+	for opts.Width > 0 && tableWidth(widths) > opts.Width {
+		widest := 0
+		for c := range widths {
+			if widths[c] > widths[widest] {
+				widest = c
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+	}
+	truncate := func(row []string) []string {
+		truncated := make([]string, columns)
+		for c := range truncated {
+			truncated[c] = ansi.Truncate(cell(row, c), widths[c], "…")
+		}
+		return truncated
+	}
+	header = truncate(header)
+	for i, row := range rows {
+		rows[i] = truncate(row)
+	}
+
+	perPage := opts.Rows
+	if perPage <= 0 {
+		perPage = max(opts.Height-borderRows, 3)
+	}
+
+	var pages []string
+	for start := 0; ; start += perPage {
+		end := min(start+perPage, len(rows))
+		t := table.New().
+			Border(lipgloss.RoundedBorder()).
+			BorderStyle(styles.TableBorder).
+			Headers(header...).
+			Rows(rows[start:end]...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				style := styles.TableCell
+				if row == 0 {
+					style = styles.TableHeader
+				}
+				return style.Align(align[col]).Width(widths[col] + style.GetHorizontalPadding())
+			})
+		page := t.String()
+		if len(rows) > perPage {
+			page += "\n" + styles.Dim.Render(fmt.Sprintf(" rows %d–%d of %d", start+1, end, len(rows)))
+		}
+		pages = append(pages, page)
+		if end == len(rows) {
+			break
+		}
+	}
+	return pages
+}
+
+// minColumnWidth is the narrowest a column is truncated to.
+const minColumnWidth = 3
+
+// tableWidth returns the width of a table with columns of the given widths,
+// including their padding and the borders between them.
+func tableWidth(widths []int) int {
+	w := 1
+	for _, width := range widths {
+		w += width + 3
+	}
+	return w
+}
+
+func cell(row []string, c int) string {
+	if c < len(row) {
+		return strings.TrimSpace(row[c])
+	}
+	return ""
+}
+
+// numberRegexp matches decimal numbers, unlike strconv.ParseFloat it does
+// not accept Inf, NaN or hexadecimal numbers.
+var numberRegexp = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// leadingZeroRegexp matches numbers such as zip codes, whose leading zeros
+// would be lost by formatting them.
+var leadingZeroRegexp = regexp.MustCompile(`^[+-]?0\d`)
+
+// isNumeric returns whether every non-empty cell of the column is a number.
+func isNumeric(rows [][]string, c int) bool {
+	found := false
+	for _, row := range rows {
+		v := cell(row, c)
+		if v == "" {
+			continue
+		}
+		if !numberRegexp.MatchString(v) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// formatColumn formats the numbers in a column with the same number of
+// decimals and separates their thousands. Numbers with leading zeros are
+// left as they are.
+func formatColumn(rows [][]string, c, decimals int) {
+	for _, row := range rows {
+		v := cell(row, c)
+		if v == "" || leadingZeroRegexp.MatchString(v) {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		row[c] = FormatNumber(f, decimals)
+	}
+}
+
+// FormatNumber formats a number with the given decimals and commas between
+// the thousands, e.g. 1,234.50.
+func FormatNumber(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString("." + frac)
+	}
+	return sign + b.String()
+}
@@ -0,0 +1,114 @@
+package table_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/maaslalani/slides/internal/table"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tt := []struct {
+		number   float64
+		decimals int
+		expected string
+	}{
+		{number: 0, decimals: 0, expected: "0"},
+		{number: 999, decimals: 0, expected: "999"},
+		{number: 1234, decimals: 0, expected: "1,234"},
+		{number: 1234567.5, decimals: 2, expected: "1,234,567.50"},
+		{number: -98765.432, decimals: 1, expected: "-98,765.4"},
+	}
+
+	for _, tc := range tt {
+		if got := table.FormatNumber(tc.number, tc.decimals); got != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	records, err := table.Parse("name\tstars\ngo\t12\n", '\t')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1][0] != "go" || records[1][1] != "12" {
+		t.Errorf("unexpected records %q", records)
+	}
+
+	if _, err := table.Parse("", ','); err == nil {
+		t.Error("expected an error for an empty table")
+	}
+}
+
+func TestRender(t *testing.T) {
+	records, err := table.Parse("name,stars\ngo,1200\nrust,900.5\n", ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := table.Render(records, table.Options{Decimals: 1})
+	expected := strings.Join([]string{
+		"╭──────┬─────────╮",
+		"│ name │   stars │",
+		"├──────┼─────────┤",
+		"│ go   │ 1,200.0 │",
+		"│ rust │   900.5 │",
+		"╰──────┴─────────╯",
+	}, "\n")
+	if len(pages) != 1 || pages[0] != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, strings.Join(pages, "\n"))
+	}
+}
+
+func TestRenderNumbers(t *testing.T) {
+	tt := []struct {
+		cell     string
+		decimals int
+		expected string
+	}{
+		{cell: "2023", decimals: -1, expected: "2023"},
+		{cell: "02134", decimals: -1, expected: "02134"},
+		{cell: "12", decimals: -1, expected: "12"},
+		{cell: "1.5e3", decimals: -1, expected: "1.5e3"},
+		{cell: "+Inf", decimals: -1, expected: "+Inf"},
+		{cell: "2023", decimals: 0, expected: "2,023"},
+		{cell: "1.5e3", decimals: 1, expected: "1,500.0"},
+		{cell: "02134", decimals: 2, expected: "02134"},
+		{cell: "+Inf", decimals: 2, expected: "+Inf"},
+		{cell: "NaN", decimals: 2, expected: "NaN"},
+	}
+
+	for _, tc := range tt {
+		records := [][]string{{"value"}, {tc.cell}}
+		table.Render(records, table.Options{Decimals: tc.decimals})
+		if got := records[1][0]; got != tc.expected {
+			t.Errorf("%q with %d decimals: expected %q, got %q", tc.cell, tc.decimals, tc.expected, got)
+		}
+	}
+}
+
+func TestRenderOverflow(t *testing.T) {
+	var csv strings.Builder
+	csv.WriteString("name,description\n")
+	for i := 0; i < 10; i++ {
+		csv.WriteString("row,a description which is much too long to fit\n")
+	}
+	records, err := table.Parse(csv.String(), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages := table.Render(records, table.Options{Width: 30, Rows: 4})
+	if len(pages) != 3 {
+		t.Fatalf("expected 3 pages, got %d", len(pages))
+	}
+	for _, page := range pages {
+		if w := lipgloss.Width(page); w > 30 {
+			t.Errorf("expected the table to fit 30 cells, got %d", w)
+		}
+	}
+	if !strings.HasSuffix(pages[2], "rows 9–10 of 10") {
+		t.Errorf("expected the last page to be numbered, got\n%s", pages[2])
+	}
+}
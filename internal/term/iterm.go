@@ -43,6 +43,9 @@ type ItermImgOpts struct {
 
 	// If set, the image's inherent aspect ratio will not be respected.
 	IgnoreAspectRatio bool
+
+	// If set, the cursor stays where it is instead of moving below the image.
+	DoNotMoveCursor bool
 }
 
 func (o ItermImgOpts) ToHeader() string {
@@ -74,6 +77,11 @@ func (o ItermImgOpts) ToHeader() string {
 		opts = append(opts, "preserveAspectRatio=0")
 	}
 
+	// default: doNotMoveCursor=0
+	if o.DoNotMoveCursor {
+		opts = append(opts, "doNotMoveCursor=1")
+	}
+
 	return ITERM_IMG_HDR + strings.Join(opts, ";") + ":"
 }
 
@@ -107,8 +115,9 @@ func ItermWriteImageWithOptions(out io.Writer, iImg image.Image, opts ItermImgOp
 	var E error
 
 	// NOTE: doing this under suspicion that wezterm PNG handling is slow
-	if _, bOK := iImg.(*image.Paletted); bOK {
-		// PNG IF PALETTED
+	opaque, bOpaque := iImg.(interface{ Opaque() bool })
+	if _, bOK := iImg.(*image.Paletted); bOK || (bOpaque && !opaque.Opaque()) {
+		// PNG IF PALETTED OR TRANSPARENT, JPG HAS NO ALPHA
 		E = png.Encode(pBuf, iImg)
 	} else {
 		// JPG IF NOT
@@ -31,6 +31,8 @@ type KittyImgOpts struct {
 	ImageId     uint32 // i=
 	ImageNo     uint32 // I=
 	PlacementId uint32 // p=
+	CursorMove  uint32 // C= (1 to leave the cursor where it is)
+	Quiet       uint32 // q= (2 to suppress the responses of the terminal)
 }
 
 func (o KittyImgOpts) ToHeader(opts ...string) string {
@@ -50,6 +52,8 @@ func (o KittyImgOpts) ToHeader(opts ...string) string {
 		{&o.ImageId, 'i'},
 		{&o.ImageNo, 'I'},
 		{&o.PlacementId, 'p'},
+		{&o.CursorMove, 'C'},
+		{&o.Quiet, 'q'},
 	}
 
 	for _, f := range sFld {
@@ -109,7 +113,68 @@ func KittyWriteImage(out io.Writer, iImg image.Image, opts KittyImgOpts) error {
 
 // Serialize PNG image from io.Reader into Kitty terminal in-band format.
 func KittyCopyPNGInline(out io.Writer, in io.Reader, opts KittyImgOpts) error {
-	_, err := fmt.Fprint(out, opts.ToHeader("a=T", "f=100", "t=d", "m=1"), KITTY_IMG_FTR)
+	return kittyCopyPNG(out, in, opts.ToHeader("a=T", "f=100", "t=d", "m=1"))
+}
+
+// Transmit image.Image to Kitty without displaying it, it is displayed by
+// placing it with KittyPlace. opts should have an ImageId.
+func KittyTransmit(out io.Writer, iImg image.Image, opts KittyImgOpts) error {
+	pBuf := new(bytes.Buffer)
+	if E := png.Encode(pBuf, iImg); E != nil {
+		return E
+	}
+
+	return kittyCopyPNG(out, pBuf, opts.ToHeader("a=t", "f=100", "t=d", "m=1"))
+}
+
+// Display an image which was transmitted before. Placing it again with the
+// same PlacementId moves the placement rather than adding another.
+func KittyPlace(out io.Writer, opts KittyImgOpts) error {
+	_, err := fmt.Fprint(out, opts.ToHeader("a=p"), KITTY_IMG_FTR)
+	return err
+}
+
+// Serialize image.Image as another frame of the animation of the image
+// imageId, shown for gap milliseconds.
+func KittyWriteFrame(out io.Writer, iImg image.Image, imageId, gap uint32) error {
+	pBuf := new(bytes.Buffer)
+	if E := png.Encode(pBuf, iImg); E != nil {
+		return E
+	}
+
+	opts := KittyImgOpts{ImageId: imageId, Quiet: 2}
+	return kittyCopyPNG(out, pBuf, opts.ToHeader("a=f", "f=100", "t=d", "m=1", fmt.Sprintf("z=%d", gap)))
+}
+
+// Start the animation of the image imageId, after setting the gap of its
+// first frame. It plays loops times, or forever if loops is 0.
+func KittyAnimate(out io.Writer, imageId, gap uint32, loops int) error {
+	opts := KittyImgOpts{ImageId: imageId, Quiet: 2}
+	_, err := fmt.Fprint(out,
+		opts.ToHeader("a=a", "r=1", fmt.Sprintf("z=%d", gap)), KITTY_IMG_FTR,
+		// v=1 loops forever, v=n plays n-1 loops
+		opts.ToHeader("a=a", "s=3", fmt.Sprintf("v=%d", loops+1)), KITTY_IMG_FTR,
+	)
+	return err
+}
+
+// Stop the animation of the image imageId.
+func KittyStopAnimation(out io.Writer, imageId uint32) error {
+	opts := KittyImgOpts{ImageId: imageId, Quiet: 2}
+	_, err := fmt.Fprint(out, opts.ToHeader("a=a", "s=1"), KITTY_IMG_FTR)
+	return err
+}
+
+// Delete the placements of the image imageId, Kitty keeps the image so that
+// it can be placed again.
+func KittyDeletePlacements(out io.Writer, imageId uint32) error {
+	opts := KittyImgOpts{ImageId: imageId, Quiet: 2}
+	_, err := fmt.Fprint(out, opts.ToHeader("a=d", "d=i"), KITTY_IMG_FTR)
+	return err
+}
+
+func kittyCopyPNG(out io.Writer, in io.Reader, header string) error {
+	_, err := fmt.Fprint(out, header, KITTY_IMG_FTR)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,49 @@
+package term
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// Synchronized returns a writer to w which writes one thing at a time. The
+// presentation is given to the program and to the model through it, so that
+// the escape sequences the model writes itself, such as images and clipboard
+// requests, are never interleaved with the frames of the renderer.
+//
+// Terminals stay files, so that the program can still put them into raw
+// mode and read their size.
+func Synchronized(w io.Writer) io.Writer {
+	if f, ok := w.(*os.File); ok {
+		return &syncFile{File: f}
+	}
+	return &syncWriter{w: w}
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+type syncFile struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (s *syncFile) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.File.Write(p)
+}
+
+// WriteString is written one at a time like Write, the embedded file would
+// bypass it otherwise.
+func (s *syncFile) WriteString(str string) (int, error) {
+	return s.Write([]byte(str))
+}
@@ -0,0 +1,169 @@
+package tex
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// box is a block of text laid out in rows. The baseline is the row which
+// lines up with the text to the left and right of the box, e.g. the bar of
+// a fraction.
+type box struct {
+	lines    []string
+	baseline int
+}
+
+func text(s string) box {
+	return box{lines: []string{s}}
+}
+
+func (b box) width() int {
+	w := 0
+	for _, line := range b.lines {
+		w = max(w, ansi.StringWidth(line))
+	}
+	return w
+}
+
+func (b box) height() int {
+	return len(b.lines)
+}
+
+// flat returns the box as a single line of text.
+func (b box) flat() string {
+	return strings.Join(b.lines, " ")
+}
+
+func (b box) String() string {
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// padRight pads s with spaces to the given width.
+func padRight(s string, w int) string {
+	return s + strings.Repeat(" ", max(w-ansi.StringWidth(s), 0))
+}
+
+// align pads every line of the box to the given width, aligning the lines
+// to the left (l), right (r) or center (c).
+func align(b box, w int, how byte) box {
+	lines := make([]string, len(b.lines))
+	bw := b.width()
+	for i, line := range b.lines {
+		left := 0
+		switch how {
+		case 'r':
+			left = w - bw
+		case 'c':
+			left = (w - bw) / 2
+		}
+		lines[i] = padRight(strings.Repeat(" ", max(left, 0))+padRight(line, bw), w)
+	}
+	return box{lines: lines, baseline: b.baseline}
+}
+
+// hjoin places boxes next to each other with their baselines lined up.
+func hjoin(boxes ...box) box {
+	above, below := 0, 0
+	for _, b := range boxes {
+		above = max(above, b.baseline)
+		below = max(below, b.height()-b.baseline-1)
+	}
+	lines := make([]string, above+below+1)
+	for _, b := range boxes {
+		w := b.width()
+		for r := range lines {
+			line := ""
+			if i := r - above + b.baseline; i >= 0 && i < b.height() {
+				line = b.lines[i]
+			}
+			lines[r] += padRight(line, w)
+		}
+	}
+	return box{lines: lines, baseline: above}
+}
+
+// vjoin stacks boxes on top of each other, centering them. The baseline is
+// the given row.
+func vjoin(baseline int, boxes ...box) box {
+	w := 0
+	for _, b := range boxes {
+		w = max(w, b.width())
+	}
+	var lines []string
+	for _, b := range boxes {
+		lines = append(lines, align(b, w, 'c').lines...)
+	}
+	return box{lines: lines, baseline: baseline}
+}
+
+// appendText adds s to the end of the baseline of the box.
+func appendText(b box, s string) box {
+	w := b.width()
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		lines[i] = padRight(line, w)
+	}
+	lines[b.baseline] += s
+	return box{lines: lines, baseline: b.baseline}
+}
+
+// delimiters holds the top, middle, extension and bottom pieces of tall
+// delimiters.
+var delimiters = map[string][4]string{
+	"(": {"⎛", "⎜", "⎜", "⎝"},
+	")": {"⎞", "⎟", "⎟", "⎠"},
+	"[": {"⎡", "⎢", "⎢", "⎣"},
+	"]": {"⎤", "⎥", "⎥", "⎦"},
+	"{": {"⎧", "⎨", "⎪", "⎩"},
+	"}": {"⎫", "⎬", "⎪", "⎭"},
+	"⌊": {"⎢", "⎢", "⎢", "⎣"},
+	"⌋": {"⎥", "⎥", "⎥", "⎦"},
+	"⌈": {"⎡", "⎢", "⎢", "⎢"},
+	"⌉": {"⎤", "⎥", "⎥", "⎥"},
+	"⟨": {"╱", "╱", "╱", "╲"},
+	"⟩": {"╲", "╲", "╲", "╱"},
+}
+
+// delimiter returns the delimiter d as tall as the box b.
+func delimiter(d string, b box) box {
+	h := b.height()
+	if d == "" || h == 1 {
+		return box{lines: []string{d}}
+	}
+
+	lines := make([]string, h)
+	pieces, ok := delimiters[d]
+	switch {
+	case !ok:
+		for i := range lines {
+			lines[i] = d
+		}
+	case d == "⟨" || d == "⟩":
+		// Angles point at the middle, the upper half leans one way and the
+		// lower half the other.
+		for i := range lines {
+			lines[i] = pieces[0]
+			if i >= h/2 {
+				lines[i] = pieces[3]
+			}
+		}
+	case h == 2 && d == "{":
+		lines[0], lines[1] = "⎰", "⎱"
+	case h == 2 && d == "}":
+		lines[0], lines[1] = "⎱", "⎰"
+	default:
+		for i := range lines {
+			lines[i] = pieces[2]
+		}
+		if d == "{" || d == "}" {
+			lines[h/2] = pieces[1]
+		}
+		lines[0], lines[h-1] = pieces[0], pieces[3]
+	}
+	return box{lines: lines, baseline: b.baseline}
+}
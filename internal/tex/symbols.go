@@ -0,0 +1,183 @@
+package tex
+
+// kind is the class of an atom of a formula, it decides the space around
+// the atom.
+type kind int
+
+const (
+	ord kind = iota
+	// bin is a binary operator, such as +.
+	bin
+	// rel is a relation, such as = or →.
+	rel
+	open
+	closing
+	punct
+	// op is a function name or large operator, such as sin or ∑, it is set
+	// apart from its argument.
+	op
+	space
+)
+
+type symbol struct {
+	text string
+	kind kind
+}
+
+var symbols = map[string]symbol{
+	// Greek letters
+	"alpha": {"α", ord}, "beta": {"β", ord}, "gamma": {"γ", ord}, "delta": {"δ", ord},
+	"epsilon": {"ϵ", ord}, "varepsilon": {"ε", ord}, "zeta": {"ζ", ord}, "eta": {"η", ord},
+	"theta": {"θ", ord}, "vartheta": {"ϑ", ord}, "iota": {"ι", ord}, "kappa": {"κ", ord},
+	"lambda": {"λ", ord}, "mu": {"μ", ord}, "nu": {"ν", ord}, "xi": {"ξ", ord},
+	"omicron": {"ο", ord}, "pi": {"π", ord}, "varpi": {"ϖ", ord}, "rho": {"ρ", ord},
+	"varrho": {"ϱ", ord}, "sigma": {"σ", ord}, "varsigma": {"ς", ord}, "tau": {"τ", ord},
+	"upsilon": {"υ", ord}, "phi": {"ϕ", ord}, "varphi": {"φ", ord}, "chi": {"χ", ord},
+	"psi": {"ψ", ord}, "omega": {"ω", ord},
+	"Gamma": {"Γ", ord}, "Delta": {"Δ", ord}, "Theta": {"Θ", ord}, "Lambda": {"Λ", ord},
+	"Xi": {"Ξ", ord}, "Pi": {"Π", ord}, "Sigma": {"Σ", ord}, "Upsilon": {"Υ", ord},
+	"Phi": {"Φ", ord}, "Psi": {"Ψ", ord}, "Omega": {"Ω", ord},
+
+	// Letter-like symbols
+	"infty": {"∞", ord}, "partial": {"∂", ord}, "nabla": {"∇", ord}, "forall": {"∀", ord},
+	"exists": {"∃", ord}, "nexists": {"∄", ord}, "emptyset": {"∅", ord}, "varnothing": {"∅", ord},
+	"neg": {"¬", ord}, "lnot": {"¬", ord}, "ldots": {"…", ord}, "dots": {"…", ord},
+	"cdots": {"⋯", ord}, "vdots": {"⋮", ord}, "ddots": {"⋱", ord}, "prime": {"′", ord},
+	"angle": {"∠", ord}, "triangle": {"△", ord}, "hbar": {"ℏ", ord}, "ell": {"ℓ", ord},
+	"Re": {"ℜ", ord}, "Im": {"ℑ", ord}, "aleph": {"ℵ", ord}, "top": {"⊤", ord},
+	"bot": {"⊥", ord}, "circ": {"∘", bin}, "degree": {"°", ord},
+	"%": {"%", ord}, "$": {"$", ord}, "#": {"#", ord}, "&": {"&", ord}, "_": {"_", ord},
+
+	// Binary operators
+	"pm": {"±", bin}, "mp": {"∓", bin}, "times": {"×", bin}, "div": {"÷", bin},
+	"cdot": {"·", bin}, "ast": {"∗", bin}, "star": {"⋆", bin}, "bullet": {"∙", bin},
+	"oplus": {"⊕", bin}, "ominus": {"⊖", bin}, "otimes": {"⊗", bin}, "odot": {"⊙", bin},
+	"cup": {"∪", bin}, "cap": {"∩", bin}, "setminus": {"∖", bin}, "wedge": {"∧", bin},
+	"land": {"∧", bin}, "vee": {"∨", bin}, "lor": {"∨", bin},
+
+	// Relations
+	"leq": {"≤", rel}, "le": {"≤", rel}, "geq": {"≥", rel}, "ge": {"≥", rel},
+	"neq": {"≠", rel}, "ne": {"≠", rel}, "approx": {"≈", rel}, "equiv": {"≡", rel},
+	"sim": {"∼", rel}, "simeq": {"≃", rel}, "cong": {"≅", rel}, "propto": {"∝", rel},
+	"ll": {"≪", rel}, "gg": {"≫", rel}, "in": {"∈", rel}, "notin": {"∉", rel},
+	"ni": {"∋", rel}, "subset": {"⊂", rel}, "subseteq": {"⊆", rel}, "supset": {"⊃", rel},
+	"supseteq": {"⊇", rel}, "to": {"→", rel}, "rightarrow": {"→", rel}, "leftarrow": {"←", rel},
+	"gets": {"←", rel}, "leftrightarrow": {"↔", rel}, "Rightarrow": {"⇒", rel},
+	"Leftarrow": {"⇐", rel}, "Leftrightarrow": {"⇔", rel}, "implies": {"⟹", rel},
+	"iff": {"⟺", rel}, "longrightarrow": {"⟶", rel}, "longleftarrow": {"⟵", rel},
+	"mapsto": {"↦", rel}, "uparrow": {"↑", rel}, "downarrow": {"↓", rel}, "perp": {"⊥", rel},
+	"parallel": {"∥", rel}, "mid": {"∣", rel}, "models": {"⊨", rel}, "vdash": {"⊢", rel},
+	"coloneqq": {"≔", rel}, "prec": {"≺", rel}, "succ": {"≻", rel},
+
+	// Delimiters
+	"{": {"{", open}, "}": {"}", closing}, "|": {"‖", ord}, "langle": {"⟨", open},
+	"rangle": {"⟩", closing}, "lfloor": {"⌊", open}, "rfloor": {"⌋", closing},
+	"lceil": {"⌈", open}, "rceil": {"⌉", closing}, "lvert": {"|", open}, "rvert": {"|", closing},
+	"lVert": {"‖", open}, "rVert": {"‖", closing},
+
+	// Spaces
+	",": {" ", space}, ":": {" ", space}, ";": {" ", space}, " ": {" ", space},
+	"!": {"", space}, "quad": {"  ", space}, "qquad": {"    ", space},
+}
+
+// largeOperators have their limits above and below them in display math,
+// except for integrals.
+var largeOperators = map[string]struct {
+	text   string
+	limits bool
+}{
+	"sum": {"∑", true}, "prod": {"∏", true}, "coprod": {"∐", true},
+	"bigcup": {"⋃", true}, "bigcap": {"⋂", true}, "bigoplus": {"⨁", true},
+	"bigotimes": {"⨂", true}, "bigvee": {"⋁", true}, "bigwedge": {"⋀", true},
+	"int": {"∫", false}, "iint": {"∬", false}, "iiint": {"∭", false}, "oint": {"∮", false},
+	"lim": {"lim", true}, "limsup": {"lim sup", true}, "liminf": {"lim inf", true},
+	"max": {"max", true}, "min": {"min", true}, "sup": {"sup", true}, "inf": {"inf", true},
+	"argmax": {"arg max", true}, "argmin": {"arg min", true},
+}
+
+var functions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true,
+	"tanh": true, "log": true, "ln": true, "lg": true, "exp": true, "det": true,
+	"dim": true, "ker": true, "deg": true, "arg": true, "gcd": true, "Pr": true,
+}
+
+// accents are combining characters placed over each character of their
+// argument.
+var accents = map[string]rune{
+	"hat": '\u0302', "widehat": '\u0302', "tilde": '\u0303', "widetilde": '\u0303',
+	"bar": '\u0304', "overline": '\u0305', "dot": '\u0307', "ddot": '\u0308',
+	"vec": '\u20d7', "underline": '\u0332',
+}
+
+// ignored commands only change the size or style of what follows them.
+var ignored = map[string]bool{
+	"displaystyle": true, "textstyle": true, "limits": true, "nolimits": true,
+	"big": true, "Big": true, "bigg": true, "Bigg": true, "bigl": true, "bigr": true,
+	"Bigl": true, "Bigr": true, "biggl": true, "biggr": true, "Biggl": true, "Biggr": true,
+	"middle": true,
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷',
+	'8': '⁸', '9': '⁹', '+': '⁺', '−': '⁻', '-': '⁻', '=': '⁼', '(': '⁽', ')': '⁾',
+	'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ', 'g': 'ᵍ', 'h': 'ʰ',
+	'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ', 'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ',
+	'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ', 'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ',
+	'z': 'ᶻ', 'A': 'ᴬ', 'B': 'ᴮ', 'D': 'ᴰ', 'E': 'ᴱ', 'G': 'ᴳ', 'H': 'ᴴ', 'I': 'ᴵ',
+	'J': 'ᴶ', 'K': 'ᴷ', 'L': 'ᴸ', 'M': 'ᴹ', 'N': 'ᴺ', 'O': 'ᴼ', 'P': 'ᴾ', 'R': 'ᴿ',
+	'T': 'ᵀ', 'U': 'ᵁ', 'V': 'ⱽ', 'W': 'ᵂ', 'α': 'ᵅ', 'β': 'ᵝ', 'γ': 'ᵞ', 'δ': 'ᵟ',
+	'θ': 'ᶿ', 'φ': 'ᵠ', 'ϕ': 'ᵠ', 'χ': 'ᵡ', '′': '′', '∗': '*',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇',
+	'8': '₈', '9': '₉', '+': '₊', '−': '₋', '-': '₋', '=': '₌', '(': '₍', ')': '₎',
+	'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ',
+	'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ', 't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ',
+	'x': 'ₓ', 'β': 'ᵦ', 'γ': 'ᵧ', 'ρ': 'ᵨ', 'φ': 'ᵩ', 'ϕ': 'ᵩ', 'χ': 'ᵪ',
+}
+
+// script converts s to superscript or subscript characters, if there is
+// one for every character.
+func script(s string, table map[rune]rune) (string, bool) {
+	out := []rune(s)
+	for i, r := range out {
+		c, ok := table[r]
+		if !ok {
+			return "", false
+		}
+		out[i] = c
+	}
+	return string(out), true
+}
+
+var doubleStruck = map[rune]rune{
+	'C': 'ℂ', 'H': 'ℍ', 'N': 'ℕ', 'P': 'ℙ', 'Q': 'ℚ', 'R': 'ℝ', 'Z': 'ℤ',
+	'1': '𝟙', 'E': '𝔼',
+}
+
+var calligraphic = map[rune]rune{
+	'A': '𝒜', 'B': 'ℬ', 'C': '𝒞', 'D': '𝒟', 'E': 'ℰ', 'F': 'ℱ', 'G': '𝒢', 'H': 'ℋ',
+	'I': 'ℐ', 'J': '𝒥', 'K': '𝒦', 'L': 'ℒ', 'M': 'ℳ', 'N': '𝒩', 'O': '𝒪', 'P': '𝒫',
+	'Q': '𝒬', 'R': 'ℛ', 'S': '𝒮', 'T': '𝒯', 'U': '𝒰', 'V': '𝒱', 'W': '𝒲', 'X': '𝒳',
+	'Y': '𝒴', 'Z': '𝒵',
+}
+
+// alphabet replaces the characters of s which are in the table.
+func alphabet(s string, table map[rune]rune) string {
+	out := []rune(s)
+	for i, r := range out {
+		if c, ok := table[r]; ok {
+			out[i] = c
+		}
+	}
+	return string(out)
+}
+
+// fractions are the vulgar fractions with their own character.
+var fractions = map[string]string{
+	"1/2": "½", "1/3": "⅓", "2/3": "⅔", "1/4": "¼", "3/4": "¾", "1/5": "⅕",
+	"2/5": "⅖", "3/5": "⅗", "4/5": "⅘", "1/6": "⅙", "5/6": "⅚", "1/7": "⅐",
+	"1/8": "⅛", "3/8": "⅜", "5/8": "⅝", "7/8": "⅞", "1/9": "⅑", "1/10": "⅒",
+}
@@ -0,0 +1,684 @@
+// Package tex converts LaTeX math to Unicode text. It understands the
+// common constructs of formulas: Greek letters and symbols, sub- and
+// superscripts, fractions, roots, large operators with limits, delimiters
+// and matrices.
+//
+// Inline math is set on a single line:
+//
+//	\frac{1}{2} \sum_{i=1}^n x_i^2  →  ½ ∑ᵢ₌₁ⁿ xᵢ²
+//
+// Display math is laid out over as many lines as it needs, with stacked
+// fractions and limits above and below large operators:
+//
+//	 n
+//	 ∑ xᵢ
+//	i=1
+package tex
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Inline converts the formula to a single line of text.
+func Inline(src string) string {
+	p := parser{src: []rune(src)}
+	return p.formula().flat()
+}
+
+// Display converts the formula to text over one or more lines.
+func Display(src string) string {
+	p := parser{src: []rune(src), display: true}
+	return p.formula().String()
+}
+
+type parser struct {
+	src     []rune
+	pos     int
+	display bool
+	// script is the depth of sub- and superscripts, they are set on a single
+	// line and without spaces around operators.
+	script int
+}
+
+type atom struct {
+	box
+	kind kind
+	// limits are set above and below the atom in display math.
+	limits bool
+}
+
+// flat returns whether the formula is set on a single line at this point.
+func (p *parser) flat() bool {
+	return !p.display || p.script > 0
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// peekCommand returns the name of the command at the current position and
+// its length including the backslash. Names are either letters or a single
+// other character, such as \, or \\.
+func (p *parser) peekCommand() (string, int) {
+	if p.eof() || p.src[p.pos] != '\\' {
+		return "", 0
+	}
+	end := p.pos + 1
+	for end < len(p.src) && unicode.IsLetter(p.src[end]) {
+		end++
+	}
+	if end == p.pos+1 && end < len(p.src) {
+		end++
+	}
+	return string(p.src[p.pos+1 : end]), end - p.pos
+}
+
+func (p *parser) command() string {
+	name, n := p.peekCommand()
+	p.pos += n
+	return name
+}
+
+// peekStop returns the token at the current position which ends a list, if
+// any, and its length.
+func (p *parser) peekStop() (string, int) {
+	if p.eof() {
+		return "", 0
+	}
+	switch r := p.src[p.pos]; r {
+	case '}', '&':
+		return string(r), 1
+	case '\\':
+		name, n := p.peekCommand()
+		switch name {
+		case `\`, "right", "end":
+			return name, n
+		}
+	}
+	return "", 0
+}
+
+// formula parses the whole source. Lines separated by \\ are aligned at
+// their & like an aligned environment.
+func (p *parser) formula() box {
+	rows := p.rows("&", `\`)
+	if len(rows) == 1 && len(rows[0]) == 1 {
+		return rows[0][0]
+	}
+	return p.table(rows, "rl", 0)
+}
+
+// list parses atoms until one of the stop tokens and returns them laid out
+// next to each other, along with the stop token. Stop tokens which are not
+// expected are skipped.
+func (p *parser) list(stops ...string) (box, string) {
+	var atoms []atom
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return p.join(atoms), ""
+		}
+		if stop, n := p.peekStop(); stop != "" {
+			p.pos += n
+			if slices.Contains(stops, stop) {
+				return p.join(atoms), stop
+			}
+			switch stop {
+			case "right":
+				p.delimiter()
+			case "end":
+				p.raw()
+			}
+			continue
+		}
+		if a, ok := p.atom(); ok {
+			atoms = append(atoms, p.scripts(a))
+		}
+	}
+}
+
+// rows parses the cells of a matrix or the lines of aligned equations.
+func (p *parser) rows(stops ...string) [][]box {
+	rows := [][]box{nil}
+	for {
+		b, stop := p.list(stops...)
+		rows[len(rows)-1] = append(rows[len(rows)-1], b)
+		switch stop {
+		case "&":
+		case `\`:
+			rows = append(rows, nil)
+		default:
+			if stop == "end" {
+				p.raw()
+			}
+			// A trailing \\ does not start another row.
+			if last := rows[len(rows)-1]; len(rows) > 1 && len(last) == 1 && last[0].width() == 0 {
+				rows = rows[:len(rows)-1]
+			}
+			return rows
+		}
+	}
+}
+
+// join lays out atoms next to each other with spaces around operators and
+// relations, like TeX does.
+func (p *parser) join(atoms []atom) box {
+	parts := []box{text("")}
+	prev := space
+	for i, a := range atoms {
+		// An operator without a left operand is a sign.
+		if a.kind == bin && (i == 0 || slices.Contains([]kind{bin, rel, open, punct, op}, prev)) {
+			a.kind = ord
+		}
+		if p.script == 0 && (gap(prev, a.kind) || (i == 0 && a.kind == rel)) {
+			parts = append(parts, text(" "))
+		}
+		parts = append(parts, a.box)
+		if a.kind != space {
+			prev = a.kind
+		}
+	}
+	return hjoin(parts...)
+}
+
+// gap returns whether there is a space between atoms of the kinds l and r.
+func gap(l, r kind) bool {
+	switch {
+	case l == space || r == space:
+		return false
+	case l == bin || r == bin:
+		return true
+	case l == rel && r == rel:
+		return false
+	case l == rel || r == rel || l == punct:
+		return true
+	case l == op:
+		return r == ord || r == op
+	case r == op:
+		return l == ord || l == closing
+	}
+	return false
+}
+
+// atom parses the next atom, it returns false for commands which do not
+// produce anything.
+func (p *parser) atom() (atom, bool) {
+	r := p.src[p.pos]
+	p.pos++
+	switch r {
+	case '{':
+		b, _ := p.list("}")
+		return atom{box: b}, true
+	case '\\':
+		p.pos--
+		return p.commandAtom()
+	case '^', '_':
+		// Scripts without anything to attach to.
+		p.pos--
+		return atom{box: text("")}, true
+	case '~':
+		return atom{box: text(" "), kind: space}, true
+	case '\'':
+		return atom{box: text("′")}, true
+	case '+':
+		return atom{box: text("+"), kind: bin}, true
+	case '-':
+		return atom{box: text("−"), kind: bin}, true
+	case '*':
+		return atom{box: text("∗"), kind: bin}, true
+	case '=', '<', '>', ':':
+		return atom{box: text(string(r)), kind: rel}, true
+	case ',', ';':
+		return atom{box: text(string(r)), kind: punct}, true
+	case '(', '[':
+		return atom{box: text(string(r)), kind: open}, true
+	case ')', ']', '!':
+		return atom{box: text(string(r)), kind: closing}, true
+	}
+	return atom{box: text(string(r))}, true
+}
+
+func (p *parser) commandAtom() (atom, bool) {
+	name := p.command()
+	switch name {
+	case "":
+		return atom{box: text(`\`)}, true
+	case "frac", "dfrac", "tfrac", "cfrac":
+		num := p.arg()
+		den := p.arg()
+		return atom{box: p.fraction(num, den)}, true
+	case "binom", "dbinom", "tbinom":
+		n := p.arg()
+		k := p.arg()
+		return atom{box: p.binomial(n, k)}, true
+	case "sqrt":
+		index := p.optional()
+		return atom{box: p.root(index, p.arg())}, true
+	case "text", "textrm", "textit", "textbf", "textsf", "texttt", "mbox":
+		return atom{box: text(p.raw())}, true
+	case "operatorname":
+		return atom{box: text(p.raw()), kind: op}, true
+	case "mathrm", "mathit", "mathbf", "mathsf", "mathtt", "mathnormal", "boldsymbol", "bm":
+		return atom{box: p.arg()}, true
+	case "mathbb":
+		return atom{box: text(alphabet(p.raw(), doubleStruck))}, true
+	case "mathcal", "mathscr":
+		return atom{box: text(alphabet(p.raw(), calligraphic))}, true
+	case "left":
+		return atom{box: p.leftRight()}, true
+	case "begin":
+		return atom{box: p.environment()}, true
+	case "not":
+		p.skipSpace()
+		if p.eof() {
+			return atom{}, false
+		}
+		a, ok := p.atom()
+		if ok && a.height() == 1 {
+			a.box = text(a.flat() + "\u0338")
+		}
+		return a, ok
+	}
+
+	if mark, ok := accents[name]; ok {
+		return atom{box: accent(p.arg(), mark)}, true
+	}
+	if o, ok := largeOperators[name]; ok {
+		return atom{box: text(o.text), kind: op, limits: o.limits}, true
+	}
+	if functions[name] {
+		return atom{box: text(name), kind: op}, true
+	}
+	if s, ok := symbols[name]; ok {
+		return atom{box: text(s.text), kind: s.kind}, true
+	}
+	if ignored[name] {
+		return atom{}, false
+	}
+	// Unknown commands are shown as they are, so that they stand out.
+	return atom{box: text(`\` + name)}, true
+}
+
+// arg parses the argument of a command, a group in braces or a single atom.
+func (p *parser) arg() box {
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return text("")
+		}
+		if p.src[p.pos] == '{' {
+			p.pos++
+			b, _ := p.list("}")
+			return b
+		}
+		if stop, _ := p.peekStop(); stop != "" {
+			return text("")
+		}
+		if a, ok := p.atom(); ok {
+			return a.box
+		}
+	}
+}
+
+// raw returns the text of the next group in braces, or the next character,
+// as it is.
+func (p *parser) raw() string {
+	p.skipSpace()
+	if p.eof() {
+		return ""
+	}
+	if p.src[p.pos] != '{' {
+		p.pos++
+		return string(p.src[p.pos-1])
+	}
+	depth := 0
+	start := p.pos + 1
+	for ; !p.eof(); p.pos++ {
+		switch p.src[p.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return string(p.src[start : p.pos-1])
+			}
+		}
+	}
+	return string(p.src[start:])
+}
+
+// optional returns the optional argument in brackets, if there is one.
+func (p *parser) optional() string {
+	p.skipSpace()
+	if p.eof() || p.src[p.pos] != '[' {
+		return ""
+	}
+	end := slices.Index(p.src[p.pos:], ']')
+	if end < 0 {
+		return ""
+	}
+	sub := parser{src: p.src[p.pos+1 : p.pos+end], script: p.script + 1}
+	p.pos += end + 1
+	return sub.formula().flat()
+}
+
+// scripts attaches the sub- and superscripts and primes which follow the
+// atom to it.
+func (p *parser) scripts(a atom) atom {
+	var sup, sub *box
+	for {
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		r := p.src[p.pos]
+		if r != '^' && r != '_' && r != '\'' {
+			break
+		}
+		p.pos++
+		if r == '\'' {
+			a.box = appendText(a.box, "′")
+			continue
+		}
+		p.script++
+		b := p.arg()
+		p.script--
+		if r == '^' {
+			sup = &b
+		} else {
+			sub = &b
+		}
+	}
+	if sup == nil && sub == nil {
+		return a
+	}
+
+	if a.limits && !p.flat() {
+		parts := []box{}
+		baseline := a.baseline
+		if sup != nil {
+			parts = append(parts, *sup)
+			baseline += sup.height()
+		}
+		parts = append(parts, a.box)
+		if sub != nil {
+			parts = append(parts, *sub)
+		}
+		a.box = vjoin(baseline, parts...)
+		return a
+	}
+
+	// Use superscript and subscript characters where there are some.
+	type part struct {
+		b      *box
+		table  map[rune]rune
+		prefix string
+	}
+	parts := []part{{sub, subscripts, "_"}, {sup, superscripts, "^"}}
+	converted, ok := make([]string, len(parts)), true
+	for i, part := range parts {
+		if part.b == nil {
+			continue
+		}
+		t, found := script(part.b.flat(), part.table)
+		if found && part.b.height() == 1 {
+			converted[i] = t
+		} else {
+			ok = false
+		}
+	}
+
+	if !ok && !p.flat() {
+		// Set the scripts on the lines above and below the atom.
+		var right box
+		if sup != nil {
+			right.lines = append(right.lines, sup.lines...)
+		}
+		right.baseline = len(right.lines) + a.baseline
+		right.lines = append(right.lines, make([]string, a.height())...)
+		if sub != nil {
+			right.lines = append(right.lines, sub.lines...)
+		}
+		a.box = hjoin(a.box, right)
+		return a
+	}
+
+	s := ""
+	for i, part := range parts {
+		switch {
+		case part.b == nil:
+		case converted[i] != "":
+			s += converted[i]
+		default:
+			s += part.prefix + group(part.b.flat())
+		}
+	}
+	a.box = appendText(a.box, s)
+	return a
+}
+
+// group puts parentheses around scripts of more than one character.
+func group(s string) string {
+	if len([]rune(s)) > 1 {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// operand puts parentheses around s unless it is a single number, name or
+// group, so that it can be the operand of a fraction or root on one line.
+func operand(s string) string {
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		return s
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '.' {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
+
+func (p *parser) fraction(num, den box) box {
+	if p.flat() {
+		n, d := num.flat(), den.flat()
+		if f, ok := fractions[n+"/"+d]; ok {
+			return text(f)
+		}
+		return text(operand(n) + "/" + operand(d))
+	}
+	bar := strings.Repeat("─", max(num.width(), den.width())+2)
+	return vjoin(num.height(), num, text(bar), den)
+}
+
+func (p *parser) binomial(n, k box) box {
+	if p.flat() {
+		return text("C(" + n.flat() + ", " + k.flat() + ")")
+	}
+	b := vjoin(n.height(), n, text(""), k)
+	return hjoin(delimiter("(", b), b, delimiter(")", b))
+}
+
+func (p *parser) root(index string, arg box) box {
+	sign := "√"
+	switch index {
+	case "":
+	case "3":
+		sign = "∛"
+	case "4":
+		sign = "∜"
+	default:
+		if s, ok := script(index, superscripts); ok {
+			sign = s + "√"
+		} else {
+			sign = "(" + index + ")√"
+		}
+	}
+	if arg.height() == 1 {
+		return text(sign + operand(arg.flat()))
+	}
+
+	// A tall radicand is covered by a bar with the sign at its bottom.
+	indent := strings.Repeat(" ", len([]rune(sign)))
+	lines := []string{indent + "┌" + strings.Repeat("─", arg.width())}
+	for i, line := range arg.lines {
+		prefix := indent
+		if i == arg.height()-1 {
+			prefix = sign
+		}
+		lines = append(lines, prefix+"│"+line)
+	}
+	return box{lines: lines, baseline: arg.baseline + 1}
+}
+
+// accent places the combining mark over every character of the box.
+func accent(b box, mark rune) box {
+	if b.height() > 1 {
+		return b
+	}
+	var s strings.Builder
+	for _, r := range b.flat() {
+		s.WriteRune(r)
+		if !unicode.IsSpace(r) {
+			s.WriteRune(mark)
+		}
+	}
+	return text(s.String())
+}
+
+// delimiter parses the delimiter after \left or \right.
+func (p *parser) delimiter() string {
+	p.skipSpace()
+	if p.eof() {
+		return ""
+	}
+	if p.src[p.pos] == '\\' {
+		switch name := p.command(); name {
+		case "vert", "lvert", "rvert":
+			return "|"
+		case "Vert", "lVert", "rVert", "|":
+			return "‖"
+		default:
+			return symbols[name].text
+		}
+	}
+	r := p.src[p.pos]
+	p.pos++
+	switch r {
+	case '.':
+		return ""
+	case '<':
+		return "⟨"
+	case '>':
+		return "⟩"
+	}
+	return string(r)
+}
+
+// leftRight parses the group between \left and \right, the delimiters are
+// as tall as the group.
+func (p *parser) leftRight() box {
+	left := p.delimiter()
+	inner, stop := p.list("right")
+	right := ""
+	if stop == "right" {
+		right = p.delimiter()
+	}
+	return hjoin(delimiter(left, inner), inner, delimiter(right, inner))
+}
+
+// environment parses \begin{name} ... \end{name}.
+func (p *parser) environment() box {
+	name := p.raw()
+	if name == "array" {
+		// The column specification.
+		p.raw()
+	}
+	rows := p.rows("&", `\`, "end")
+
+	left, right, aligns, gap := "", "", "c", 2
+	switch strings.TrimSuffix(name, "*") {
+	case "pmatrix":
+		left, right = "(", ")"
+	case "bmatrix":
+		left, right = "[", "]"
+	case "Bmatrix":
+		left, right = "{", "}"
+	case "vmatrix":
+		left, right = "|", "|"
+	case "Vmatrix":
+		left, right = "‖", "‖"
+	case "cases":
+		left, aligns = "{", "l"
+	case "aligned", "align", "alignat", "split", "eqnarray", "flalign":
+		aligns, gap = "rl", 0
+	case "gathered", "gather", "equation", "multline":
+		gap = 0
+	}
+	t := p.table(rows, aligns, gap)
+	if p.flat() && left != "" {
+		return text(left + t.flat() + right)
+	}
+	return hjoin(delimiter(left, t), t, delimiter(right, t))
+}
+
+// table lays out rows of cells in columns. The columns are aligned by the
+// letters of aligns, which repeat, and separated by gap spaces.
+func (p *parser) table(rows [][]box, aligns string, gap int) box {
+	if p.flat() {
+		sep := ""
+		if gap > 0 {
+			sep = " "
+		}
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = cell.flat()
+			}
+			lines[i] = strings.TrimSpace(strings.Join(cells, sep))
+		}
+		return text(strings.Join(lines, "; "))
+	}
+
+	var widths []int
+	for _, row := range rows {
+		for j, cell := range row {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[j] = max(widths[j], cell.width())
+		}
+	}
+
+	var t box
+	for _, row := range rows {
+		var cells []box
+		for j, w := range widths {
+			cell := text("")
+			if j < len(row) {
+				cell = row[j]
+			}
+			if j > 0 && gap > 0 {
+				cells = append(cells, text(strings.Repeat(" ", gap)))
+			}
+			cells = append(cells, align(cell, w, aligns[j%len(aligns)]))
+		}
+		line := hjoin(cells...)
+		if len(rows) == 1 {
+			return line
+		}
+		t.lines = append(t.lines, line.lines...)
+	}
+	t.baseline = len(t.lines) / 2
+	return t
+}
@@ -0,0 +1,99 @@
+package tex_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/tex"
+)
+
+func TestInline(t *testing.T) {
+	tt := []struct {
+		src      string
+		expected string
+	}{
+		{`\alpha + \beta = \gamma`, "α + β = γ"},
+		{`x^2 + y_1`, "x² + y₁"},
+		{`e^{i\pi} = -1`, "e^(iπ) = −1"},
+		{`\frac{1}{2} + \frac{a+b}{c}`, "½ + (a + b)/c"},
+		{`\sum_{i=1}^n x_i`, "∑ᵢ₌₁ⁿ xᵢ"},
+		{`\int_0^\infty f(x)\,dx`, "∫₀^∞ f(x) dx"},
+		{`\sqrt{2} \ne \sqrt{x+1}`, "√2 ≠ √(x + 1)"},
+		{`\sqrt[3]{8} = 2`, "∛8 = 2"},
+		{`\mathbb{R}^n \to \mathbb{R}`, "ℝⁿ → ℝ"},
+		{`\sin x \leq 1`, "sin x ≤ 1"},
+		{`\hat{y} = \text{mean of } y`, "y\u0302 = mean of y"},
+		{`\begin{pmatrix} a & b \\ c & d \end{pmatrix}`, "(a b; c d)"},
+		{`\unknown{x}`, `\unknownx`},
+	}
+
+	for _, tc := range tt {
+		if got := tex.Inline(tc.src); got != tc.expected {
+			t.Errorf("Inline(%q) = %q, want %q", tc.src, got, tc.expected)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tt := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "fraction",
+			src:  `\frac{-b \pm \sqrt{b^2 - 4ac}}{2a}`,
+			expected: `
+ −b ± √(b² − 4ac)
+──────────────────
+        2a`,
+		},
+		{
+			name: "limits",
+			src:  `\sum_{i=1}^{n} i = \frac{n(n+1)}{2}`,
+			expected: `
+ n       n(n + 1)
+ ∑  i = ──────────
+i=1         2`,
+		},
+		{
+			name: "scripts",
+			src:  `\int_0^\infty e^{-x^2} dx`,
+			expected: `
+ ∞  −x²
+∫  e   dx
+ 0`,
+		},
+		{
+			name: "delimiters",
+			src:  `\left( \frac{a}{b} \right)^2`,
+			expected: `
+⎛ a ⎞
+⎜───⎟²
+⎝ b ⎠`,
+		},
+		{
+			name: "matrix",
+			src:  `A = \begin{bmatrix} 1 & 0 \\ 0 & 1 \end{bmatrix}`,
+			expected: `
+    ⎡1  0⎤
+A = ⎣0  1⎦`,
+		},
+		{
+			name: "aligned",
+			src:  `f(x) &= (x+1)^2 \\ &= x^2 + 2x + 1`,
+			expected: `
+f(x) = (x + 1)²
+     = x² + 2x + 1`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := strings.TrimPrefix(tc.expected, "\n")
+			if got := tex.Display(tc.src); got != expected {
+				t.Errorf("got\n%s\nwant\n%s", got, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package transition
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+)
+
+// defaultForeground is the color text without a color is assumed to have
+// when it fades.
+var defaultForeground = colorful.Color{R: 0xd0 / 255.0, G: 0xd0 / 255.0, B: 0xd0 / 255.0}
+
+// style is the SGR state of a cell.
+type style struct {
+	// attrs has bit n set for attribute n, from bold (1) to strikethrough
+	// (9).
+	attrs  uint16
+	fg, bg color
+}
+
+type colorKind uint8
+
+const (
+	noColor colorKind = iota
+	ansiColor
+	rgbColor
+)
+
+// color is an ANSI color, n is the number of 256 color palette, or a
+// 24-bit color.
+type color struct {
+	kind    colorKind
+	n       uint8
+	r, g, b uint8
+}
+
+// apply returns the style after the SGR sequence with the given parameters.
+func (s style) apply(params string) style {
+	ps := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(ps) == 0 {
+		return style{}
+	}
+	for i := 0; i < len(ps); i++ {
+		p, err := strconv.Atoi(ps[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case p == 0:
+			s = style{}
+		case p >= 1 && p <= 9:
+			s.attrs |= 1 << p
+		case p == 22:
+			s.attrs &^= 1<<1 | 1<<2
+		case p == 23 || p == 24 || p == 27 || p == 28 || p == 29:
+			s.attrs &^= 1 << (p - 20)
+		case p == 25:
+			s.attrs &^= 1<<5 | 1<<6
+		case p >= 30 && p <= 37:
+			s.fg = color{kind: ansiColor, n: uint8(p - 30)}
+		case p >= 90 && p <= 97:
+			s.fg = color{kind: ansiColor, n: uint8(p - 90 + 8)}
+		case p == 39:
+			s.fg = color{}
+		case p >= 40 && p <= 47:
+			s.bg = color{kind: ansiColor, n: uint8(p - 40)}
+		case p >= 100 && p <= 107:
+			s.bg = color{kind: ansiColor, n: uint8(p - 100 + 8)}
+		case p == 49:
+			s.bg = color{}
+		case p == 38 || p == 48:
+			c, n := extendedColor(ps[i+1:])
+			i += n
+			if p == 38 {
+				s.fg = c
+			} else {
+				s.bg = c
+			}
+		}
+	}
+	return s
+}
+
+// extendedColor parses the parameters after 38 or 48, which are 5;n or
+// 2;r;g;b, and returns the color and the number of parameters it took up.
+func extendedColor(ps []string) (color, int) {
+	nums := make([]uint8, 0, 4)
+	for _, p := range ps[:min(len(ps), 4)] {
+		n, _ := strconv.Atoi(p)
+		nums = append(nums, uint8(n))
+	}
+	switch {
+	case len(nums) >= 2 && nums[0] == 5:
+		return color{kind: ansiColor, n: nums[1]}, 2
+	case len(nums) >= 4 && nums[0] == 2:
+		return color{kind: rgbColor, r: nums[1], g: nums[2], b: nums[3]}, 4
+	}
+	return color{}, len(nums)
+}
+
+// sequence returns the SGR sequence which sets the style after a reset.
+func (s style) sequence() string {
+	var ps []string
+	for a := 1; a <= 9; a++ {
+		if s.attrs&(1<<a) != 0 {
+			ps = append(ps, strconv.Itoa(a))
+		}
+	}
+	if p := s.fg.params(); p != "" {
+		ps = append(ps, "38;"+p)
+	}
+	if p := s.bg.params(); p != "" {
+		ps = append(ps, "48;"+p)
+	}
+	if len(ps) == 0 {
+		return ""
+	}
+	return "\x1b[" + strings.Join(ps, ";") + "m"
+}
+
+func (c color) params() string {
+	switch c.kind {
+	case ansiColor:
+		return "5;" + strconv.Itoa(int(c.n))
+	case rgbColor:
+		return fmt.Sprintf("2;%d;%d;%d", c.r, c.g, c.b)
+	}
+	return ""
+}
+
+func (c color) rgb() colorful.Color {
+	switch c.kind {
+	case ansiColor:
+		return termenv.ConvertToRGB(termenv.ANSI256Color(c.n))
+	case rgbColor:
+		return colorful.Color{R: float64(c.r) / 255, G: float64(c.g) / 255, B: float64(c.b) / 255}
+	}
+	return defaultForeground
+}
+
+// fade returns the cell with its colors at the given opacity over a black
+// background.
+func (c cell) fade(opacity float64) cell {
+	black := colorful.Color{}
+	blend := func(col color) color {
+		r, g, b := black.BlendRgb(col.rgb(), opacity).Clamped().RGB255()
+		return color{kind: rgbColor, r: r, g: g, b: b}
+	}
+	c.style.fg = blend(c.style.fg)
+	if c.style.bg.kind != noColor {
+		c.style.bg = blend(c.style.bg)
+	}
+	return c
+}
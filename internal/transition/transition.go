@@ -0,0 +1,220 @@
+// Package transition draws the frames of transitions between two slides,
+// which are rendered with ANSI escape sequences.
+package transition
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Kinds of transitions.
+const (
+	None       = "none"
+	SlideLeft  = "slide-left"
+	SlideRight = "slide-right"
+	Fade       = "fade"
+	Wipe       = "wipe"
+	Dissolve   = "dissolve"
+)
+
+// Kinds are the kinds of transitions, other than None.
+var Kinds = []string{SlideLeft, SlideRight, Fade, Wipe, Dissolve}
+
+// Valid returns whether kind is a kind of transition.
+func Valid(kind string) bool {
+	if kind == None {
+		return true
+	}
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Frame returns the frame of a transition from one screen to another, of
+// the given size, at progress between 0 and 1.
+func Frame(kind, from, to string, width, height int, progress float64) string {
+	progress = min(max(progress, 0), 1)
+	fromLines, toLines := screen(from, width, height), screen(to, width, height)
+	shift := int(progress * float64(width))
+
+	lines := make([]string, height)
+	for y := range lines {
+		a, b := fromLines[y], toLines[y]
+		row := make([]cell, width)
+		for x := range row {
+			switch kind {
+			case SlideLeft:
+				// The new slide comes in from the right.
+				if x < width-shift {
+					row[x] = a[x+shift]
+				} else {
+					row[x] = b[x-(width-shift)]
+				}
+			case SlideRight:
+				if x < shift {
+					row[x] = b[width-shift+x]
+				} else {
+					row[x] = a[x-shift]
+				}
+			case Wipe:
+				if x < shift {
+					row[x] = b[x]
+				} else {
+					row[x] = a[x]
+				}
+			case Dissolve:
+				if noise(x, y) < progress {
+					row[x] = b[x]
+				} else {
+					row[x] = a[x]
+				}
+			case Fade:
+				// Fade out to the background, then the new slide in.
+				if progress < 0.5 {
+					row[x] = a[x].fade(1 - 2*progress)
+				} else {
+					row[x] = b[x].fade(2*progress - 1)
+				}
+			default:
+				row[x] = b[x]
+			}
+		}
+		lines[y] = render(row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// noise returns a number between 0 and 1 which is the same for a cell in
+// every frame, so that cells which dissolved stay dissolved.
+func noise(x, y int) float64 {
+	h := uint32(x)*374761393 + uint32(y)*668265263
+	h = (h ^ (h >> 13)) * 1274126177
+	h ^= h >> 16
+	return float64(h&0xffff) / 0x10000
+}
+
+// cell is a column of the screen. Wide characters take up two cells, the
+// second of which has no text.
+type cell struct {
+	text  string
+	style style
+	wide  bool
+}
+
+// screen splits a screen into lines of cells of the given size.
+func screen(s string, width, height int) [][]cell {
+	lines := strings.Split(s, "\n")
+	cells := make([][]cell, height)
+	for y := range cells {
+		var line string
+		if y < len(lines) {
+			line = lines[y]
+		}
+		cells[y] = parse(line, width)
+	}
+	return cells
+}
+
+// parse splits a line into cells, keeping the colors and attributes of
+// every cell. Escape sequences other than SGR, such as hyperlinks and
+// images, are dropped.
+func parse(line string, width int) []cell {
+	row := make([]cell, 0, width)
+	var st style
+	state := -1
+	for len(line) > 0 && len(row) < width {
+		if line[0] == '\x1b' {
+			seq, rest := SplitEscape(line)
+			if params, ok := strings.CutPrefix(seq, "\x1b["); ok && strings.HasSuffix(params, "m") {
+				st = st.apply(strings.TrimSuffix(params, "m"))
+			}
+			// The state of the grapheme clusters looked ahead at the
+			// escape sequence.
+			line, state = rest, -1
+			continue
+		}
+
+		var cluster string
+		var w int
+		cluster, line, w, state = uniseg.FirstGraphemeClusterInString(line, state)
+		switch {
+		case cluster == "\t":
+			row = append(row, cell{text: " ", style: st})
+		case w == 0:
+			// Control characters are dropped.
+		case w == 2 && len(row)+1 < width:
+			row = append(row, cell{text: cluster, style: st, wide: true}, cell{style: st})
+		case w == 2:
+			row = append(row, cell{text: " ", style: st})
+		default:
+			row = append(row, cell{text: cluster, style: st})
+		}
+	}
+	for len(row) < width {
+		row = append(row, cell{text: " "})
+	}
+	return row
+}
+
+// SplitEscape splits the escape sequence at the start of s from the rest.
+func SplitEscape(s string) (string, string) {
+	if len(s) < 2 {
+		return s, ""
+	}
+	switch s[1] {
+	case '[':
+		// CSI sequences end with a byte in @ to ~.
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return s[:i+1], s[i+1:]
+			}
+		}
+	case ']', '_', 'P', '^':
+		// OSC, APC, DCS and PM sequences end with BEL or ST.
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return s[:i+1], s[i+1:]
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return s[:i+2], s[i+2:]
+			}
+		}
+	default:
+		return s[:2], s[2:]
+	}
+	return s, ""
+}
+
+// render joins cells into a line, with the escape sequences of their
+// styles.
+func render(row []cell) string {
+	var b strings.Builder
+	var prev style
+	for i, c := range row {
+		if c.text == "" {
+			if i > 0 && row[i-1].wide {
+				continue
+			}
+			// The second half of a wide character which was cut off.
+			c.text = " "
+		}
+		if c.wide && (i+1 == len(row) || row[i+1].text != "") {
+			// A wide character whose second half was cut off.
+			c.text = " "
+		}
+		if c.style != prev {
+			b.WriteString("\x1b[0m")
+			b.WriteString(c.style.sequence())
+			prev = c.style
+		}
+		b.WriteString(c.text)
+	}
+	if prev != (style{}) {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
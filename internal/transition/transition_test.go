@@ -0,0 +1,90 @@
+package transition_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/maaslalani/slides/internal/transition"
+)
+
+func TestSlide(t *testing.T) {
+	from, to := "aaaa\nbbbb", "1234\n5678"
+	tests := []struct {
+		kind     string
+		progress float64
+		want     string
+	}{
+		{transition.SlideLeft, 0, "aaaa\nbbbb"},
+		{transition.SlideLeft, 0.5, "aa12\nbb56"},
+		{transition.SlideLeft, 1, "1234\n5678"},
+		{transition.SlideRight, 0.5, "34aa\n78bb"},
+		{transition.Wipe, 0.25, "1aaa\n5bbb"},
+		{transition.None, 0.5, "1234\n5678"},
+	}
+	for _, tt := range tests {
+		got := ansi.Strip(transition.Frame(tt.kind, from, to, 4, 2, tt.progress))
+		if got != tt.want {
+			t.Errorf("%s at %v: expected %q, got %q", tt.kind, tt.progress, tt.want, got)
+		}
+	}
+}
+
+func TestStyles(t *testing.T) {
+	from := "\x1b[1;38;5;39mab\x1b[0mc\x1b]8;;https://example.com\x07d\x1b]8;;\x07"
+	got := transition.Frame(transition.Wipe, from, "", 4, 1, 0)
+	if want := "\x1b[0m\x1b[1;38;5;39mab\x1b[0mcd"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFade(t *testing.T) {
+	got := transition.Frame(transition.Fade, "\x1b[38;2;200;100;0mx", "y", 1, 1, 0.25)
+	if want := "\x1b[0m\x1b[38;2;100;50;0mx\x1b[0m"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := ansi.Strip(transition.Frame(transition.Fade, "x", "y", 1, 1, 0.75)); got != "y" {
+		t.Errorf("expected the new slide in the second half, got %q", got)
+	}
+}
+
+func TestDissolve(t *testing.T) {
+	from, to := strings.Repeat("a", 100), strings.Repeat("b", 100)
+	half := ansi.Strip(transition.Frame(transition.Dissolve, from, to, 100, 1, 0.5))
+	if n := strings.Count(half, "b"); n < 30 || n > 70 {
+		t.Errorf("expected about half of the cells to have dissolved, got %d", n)
+	}
+	more := ansi.Strip(transition.Frame(transition.Dissolve, from, to, 100, 1, 0.75))
+	for i := range half {
+		if half[i] == 'b' && more[i] != 'b' {
+			t.Fatalf("expected cell %d to stay dissolved", i)
+		}
+	}
+}
+
+func TestWide(t *testing.T) {
+	got := ansi.Strip(transition.Frame(transition.SlideLeft, "日本", "ab", 4, 1, 0.25))
+	if got != " 本a" {
+		t.Errorf("expected the cut wide character to be a space, got %q", got)
+	}
+}
+
+func TestSplitEscape(t *testing.T) {
+	tests := []struct {
+		s, seq, rest string
+	}{
+		{"\x1b[38;5;252mtext", "\x1b[38;5;252m", "text"},
+		{"\x1b]8;;https://example.com\atext", "\x1b]8;;https://example.com\a", "text"},
+		{"\x1b]8;;https://example.com\x1b\\text", "\x1b]8;;https://example.com\x1b\\", "text"},
+		{"\x1b_Gi=1;\x1b\\", "\x1b_Gi=1;\x1b\\", ""},
+		{"\x1b7text", "\x1b7", "text"},
+		{"\x1b[38;5", "\x1b[38;5", ""},
+		{"\x1b", "\x1b", ""},
+	}
+	for _, tt := range tests {
+		seq, rest := transition.SplitEscape(tt.s)
+		if seq != tt.seq || rest != tt.rest {
+			t.Errorf("SplitEscape(%q) = %q, %q, want %q, %q", tt.s, seq, rest, tt.seq, tt.rest)
+		}
+	}
+}
@@ -0,0 +1,609 @@
+// Package vt implements a small virtual terminal which interprets the output
+// of programs (text, control characters and the common escape sequences) and
+// keeps the resulting screen so that it can be displayed inside a slide.
+package vt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Style holds the graphic rendition of a cell as SGR parameters.
+type Style struct {
+	Fg        string
+	Bg        string
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Reverse   bool
+}
+
+// sgr returns the escape sequence which selects the style.
+func (s Style) sgr() string {
+	params := []string{"0"}
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Faint {
+		params = append(params, "2")
+	}
+	if s.Italic {
+		params = append(params, "3")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if s.Reverse {
+		params = append(params, "7")
+	}
+	if s.Fg != "" {
+		params = append(params, s.Fg)
+	}
+	if s.Bg != "" {
+		params = append(params, s.Bg)
+	}
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// Cell is a single character on the screen.
+type Cell struct {
+	Rune  rune
+	Style Style
+}
+
+var blank = Cell{Rune: ' '}
+
+// maxParam is the largest parameter of an escape sequence, larger ones are
+// cut to it so that counts and positions cannot overflow.
+const maxParam = 1<<16 - 1
+
+type state int
+
+const (
+	stateGround state = iota
+	stateEscape
+	stateCharset
+	stateCSI
+	stateOSC
+	stateOSCEscape
+)
+
+// Terminal is a virtual terminal screen. It implements io.Writer, everything
+// written to it is interpreted like a terminal emulator would.
+type Terminal struct {
+	mu sync.Mutex
+
+	cols, rows int
+	screen     [][]Cell
+	// main holds the main screen while the alternate screen is in use.
+	main [][]Cell
+
+	x, y           int
+	savedX, savedY int
+	// wrap is set when a character was written in the last column, the next
+	// character then goes to the start of the next line.
+	wrap   bool
+	style  Style
+	top    int
+	bottom int
+	hidden bool
+
+	state   state
+	params  []byte
+	partial []byte
+
+	// Reply receives the answers to requests from the program, such as
+	// cursor position reports. It is usually the input of the program.
+	Reply io.Writer
+}
+
+// New creates a terminal of the given size.
+func New(cols, rows int) *Terminal {
+	t := &Terminal{}
+	t.resize(max(cols, 1), max(rows, 1))
+	return t
+}
+
+func newScreen(cols, rows int) [][]Cell {
+	screen := make([][]Cell, rows)
+	for y := range screen {
+		screen[y] = newLine(cols)
+	}
+	return screen
+}
+
+func newLine(cols int) []Cell {
+	line := make([]Cell, cols)
+	for x := range line {
+		line[x] = blank
+	}
+	return line
+}
+
+// Size returns the number of columns and rows of the terminal.
+func (t *Terminal) Size() (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cols, t.rows
+}
+
+// Resize changes the size of the terminal, keeping as much of the screen as
+// fits.
+func (t *Terminal) Resize(cols, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resize(max(cols, 1), max(rows, 1))
+}
+
+func (t *Terminal) resize(cols, rows int) {
+	screen := newScreen(cols, rows)
+	// Keep the bottom of the screen, where the cursor usually is.
+	offset := max(len(t.screen)-rows, 0)
+	for y := 0; y < rows && y+offset < len(t.screen); y++ {
+		copy(screen[y], t.screen[y+offset])
+	}
+	t.screen = screen
+	if t.main != nil {
+		t.main = newScreen(cols, rows)
+	}
+	t.cols, t.rows = cols, rows
+	t.moveTo(t.x, t.y-offset)
+	t.savedX = min(max(t.savedX, 0), cols-1)
+	t.savedY = min(max(t.savedY-offset, 0), rows-1)
+	t.top, t.bottom = 0, rows-1
+	t.wrap = false
+}
+
+// Write interprets p as terminal output.
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(p)
+	if len(t.partial) > 0 {
+		p = append(t.partial, p...)
+		t.partial = nil
+	}
+
+	for len(p) > 0 {
+		if t.state == stateGround && p[0] >= 0x80 {
+			if !utf8.FullRune(p) {
+				t.partial = append([]byte(nil), p...)
+				break
+			}
+			r, size := utf8.DecodeRune(p)
+			t.print(r)
+			p = p[size:]
+			continue
+		}
+		t.feed(p[0])
+		p = p[1:]
+	}
+
+	return n, nil
+}
+
+func (t *Terminal) feed(b byte) {
+	switch t.state {
+	case stateGround:
+		t.ground(b)
+	case stateEscape:
+		t.escape(b)
+	case stateCharset:
+		t.state = stateGround
+	case stateCSI:
+		if b >= 0x40 && b <= 0x7e {
+			t.csi(b)
+			t.state = stateGround
+			return
+		}
+		t.params = append(t.params, b)
+	case stateOSC:
+		// Operating system commands, such as window titles, are ignored.
+		switch b {
+		case '\a':
+			t.state = stateGround
+		case '\x1b':
+			t.state = stateOSCEscape
+		}
+	case stateOSCEscape:
+		t.state = stateGround
+	}
+}
+
+func (t *Terminal) ground(b byte) {
+	switch b {
+	case '\x1b':
+		t.state = stateEscape
+	case '\r':
+		t.x = 0
+		t.wrap = false
+	case '\n', '\v', '\f':
+		t.lineFeed()
+	case '\b':
+		if t.x > 0 {
+			t.x--
+		}
+		t.wrap = false
+	case '\t':
+		t.x = min((t.x/8+1)*8, t.cols-1)
+	case '\a':
+	default:
+		if b >= 0x20 {
+			t.print(rune(b))
+		}
+	}
+}
+
+func (t *Terminal) escape(b byte) {
+	t.state = stateGround
+	switch b {
+	case '[':
+		t.state = stateCSI
+		t.params = t.params[:0]
+	case ']':
+		t.state = stateOSC
+	case '(', ')', '*', '+':
+		t.state = stateCharset
+	case '7':
+		t.savedX, t.savedY = t.x, t.y
+	case '8':
+		t.moveTo(t.savedX, t.savedY)
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.x = 0
+		t.lineFeed()
+	case 'M':
+		if t.y == t.top {
+			t.scrollDown(1)
+		} else if t.y > 0 {
+			t.y--
+		}
+	case 'c':
+		t.reset()
+	}
+}
+
+func (t *Terminal) reset() {
+	t.screen = newScreen(t.cols, t.rows)
+	t.main = nil
+	t.x, t.y = 0, 0
+	t.style = Style{}
+	t.top, t.bottom = 0, t.rows-1
+	t.wrap = false
+	t.hidden = false
+}
+
+// moveTo moves the cursor to column x of row y, or the closest position on
+// the screen.
+func (t *Terminal) moveTo(x, y int) {
+	t.x = min(max(x, 0), t.cols-1)
+	t.y = min(max(y, 0), t.rows-1)
+}
+
+func (t *Terminal) print(r rune) {
+	if t.wrap {
+		t.x = 0
+		t.lineFeed()
+		t.wrap = false
+	}
+	t.screen[t.y][t.x] = Cell{Rune: r, Style: t.style}
+	if t.x == t.cols-1 {
+		t.wrap = true
+	} else {
+		t.x++
+	}
+}
+
+func (t *Terminal) lineFeed() {
+	t.wrap = false
+	if t.y == t.bottom {
+		t.scrollUp(1)
+		return
+	}
+	if t.y < t.rows-1 {
+		t.y++
+	}
+}
+
+// scrollUp scrolls the lines of the scrolling region up by n lines.
+func (t *Terminal) scrollUp(n int) {
+	for n = min(n, t.bottom-t.top+1); n > 0; n-- {
+		copy(t.screen[t.top:t.bottom+1], t.screen[t.top+1:t.bottom+1])
+		t.screen[t.bottom] = newLine(t.cols)
+	}
+}
+
+// scrollDown scrolls the lines of the scrolling region down by n lines.
+func (t *Terminal) scrollDown(n int) {
+	for n = min(n, t.bottom-t.top+1); n > 0; n-- {
+		copy(t.screen[t.top+1:t.bottom+1], t.screen[t.top:t.bottom])
+		t.screen[t.top] = newLine(t.cols)
+	}
+}
+
+func (t *Terminal) csi(final byte) {
+	raw := string(t.params)
+	private := strings.HasPrefix(raw, "?")
+	raw = strings.TrimLeft(raw, "?>=")
+
+	var params []int
+	if raw != "" {
+		for _, p := range strings.Split(raw, ";") {
+			// Parameters are digits, anything else counts as missing.
+			n, err := strconv.ParseUint(p, 10, 64)
+			if err != nil && n == 0 {
+				params = append(params, 0)
+				continue
+			}
+			params = append(params, int(min(n, maxParam)))
+		}
+	}
+	// param returns the i-th parameter, or def if it is missing or zero.
+	param := func(i, def int) int {
+		if i < len(params) && params[i] != 0 {
+			return params[i]
+		}
+		return def
+	}
+
+	t.wrap = false
+	switch final {
+	case 'A':
+		t.y = max(t.y-param(0, 1), 0)
+	case 'B', 'e':
+		t.y = min(t.y+param(0, 1), t.rows-1)
+	case 'C', 'a':
+		t.x = min(t.x+param(0, 1), t.cols-1)
+	case 'D':
+		t.x = max(t.x-param(0, 1), 0)
+	case 'E':
+		t.y = min(t.y+param(0, 1), t.rows-1)
+		t.x = 0
+	case 'F':
+		t.y = max(t.y-param(0, 1), 0)
+		t.x = 0
+	case 'G', '`':
+		t.moveTo(param(0, 1)-1, t.y)
+	case 'd':
+		t.moveTo(t.x, param(0, 1)-1)
+	case 'H', 'f':
+		t.moveTo(param(1, 1)-1, param(0, 1)-1)
+	case 'J':
+		t.eraseDisplay(param(0, 0))
+	case 'K':
+		t.eraseLine(param(0, 0))
+	case 'L':
+		if t.y >= t.top && t.y <= t.bottom {
+			top := t.top
+			t.top = t.y
+			t.scrollDown(param(0, 1))
+			t.top = top
+		}
+	case 'M':
+		if t.y >= t.top && t.y <= t.bottom {
+			top := t.top
+			t.top = t.y
+			t.scrollUp(param(0, 1))
+			t.top = top
+		}
+	case 'P':
+		line := t.screen[t.y]
+		n := min(param(0, 1), t.cols-t.x)
+		copy(line[t.x:], line[t.x+n:])
+		for x := t.cols - n; x < t.cols; x++ {
+			line[x] = blank
+		}
+	case '@':
+		line := t.screen[t.y]
+		n := min(param(0, 1), t.cols-t.x)
+		copy(line[t.x+n:], line[t.x:])
+		for x := t.x; x < t.x+n; x++ {
+			line[x] = blank
+		}
+	case 'X':
+		for x := t.x; x < min(t.x+param(0, 1), t.cols); x++ {
+			t.screen[t.y][x] = blank
+		}
+	case 'S':
+		t.scrollUp(param(0, 1))
+	case 'T':
+		t.scrollDown(param(0, 1))
+	case 'r':
+		top, bottom := param(0, 1)-1, param(1, t.rows)-1
+		if top < bottom && bottom < t.rows {
+			t.top, t.bottom = top, bottom
+			t.x, t.y = 0, 0
+		}
+	case 's':
+		t.savedX, t.savedY = t.x, t.y
+	case 'u':
+		t.moveTo(t.savedX, t.savedY)
+	case 'm':
+		t.sgr(params)
+	case 'h', 'l':
+		if private {
+			t.mode(params, final == 'h')
+		}
+	case 'n':
+		if param(0, 0) == 6 {
+			t.reply(fmt.Sprintf("\x1b[%d;%dR", t.y+1, t.x+1))
+		}
+	case 'c':
+		if !private {
+			t.reply("\x1b[?1;2c")
+		}
+	}
+}
+
+func (t *Terminal) reply(s string) {
+	if t.Reply != nil {
+		_, _ = io.WriteString(t.Reply, s)
+	}
+}
+
+func (t *Terminal) mode(params []int, set bool) {
+	for _, p := range params {
+		switch p {
+		case 25:
+			t.hidden = !set
+		case 47, 1047, 1049:
+			if set && t.main == nil {
+				t.main = t.screen
+				t.screen = newScreen(t.cols, t.rows)
+				if p == 1049 {
+					t.savedX, t.savedY = t.x, t.y
+				}
+			} else if !set && t.main != nil {
+				t.screen = t.main
+				t.main = nil
+				if p == 1049 {
+					t.moveTo(t.savedX, t.savedY)
+				}
+			}
+		}
+	}
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for y := t.y + 1; y < t.rows; y++ {
+			t.screen[y] = newLine(t.cols)
+		}
+	case 1:
+		t.eraseLine(1)
+		for y := 0; y < t.y; y++ {
+			t.screen[y] = newLine(t.cols)
+		}
+	case 2, 3:
+		t.screen = newScreen(t.cols, t.rows)
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	from, to := t.x, t.cols
+	switch mode {
+	case 1:
+		from, to = 0, t.x+1
+	case 2:
+		from, to = 0, t.cols
+	}
+	for x := from; x < min(to, t.cols); x++ {
+		t.screen[t.y][x] = blank
+	}
+}
+
+func (t *Terminal) sgr(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		switch {
+		case p == 0:
+			t.style = Style{}
+		case p == 1:
+			t.style.Bold = true
+		case p == 2:
+			t.style.Faint = true
+		case p == 3:
+			t.style.Italic = true
+		case p == 4:
+			t.style.Underline = true
+		case p == 7:
+			t.style.Reverse = true
+		case p == 22:
+			t.style.Bold, t.style.Faint = false, false
+		case p == 23:
+			t.style.Italic = false
+		case p == 24:
+			t.style.Underline = false
+		case p == 27:
+			t.style.Reverse = false
+		case p >= 30 && p <= 37, p >= 90 && p <= 97:
+			t.style.Fg = strconv.Itoa(p)
+		case p == 39:
+			t.style.Fg = ""
+		case p >= 40 && p <= 47, p >= 100 && p <= 107:
+			t.style.Bg = strconv.Itoa(p)
+		case p == 49:
+			t.style.Bg = ""
+		case p == 38, p == 48:
+			color, n := extendedColor(params[i:])
+			if p == 38 {
+				t.style.Fg = color
+			} else {
+				t.style.Bg = color
+			}
+			i += n
+		}
+	}
+}
+
+// extendedColor parses a 256 color (38;5;n) or true color (38;2;r;g;b)
+// parameter and returns it along with the number of parameters used after
+// the first.
+func extendedColor(params []int) (string, int) {
+	if len(params) >= 3 && params[1] == 5 {
+		return fmt.Sprintf("%d;5;%d", params[0], params[2]), 2
+	}
+	if len(params) >= 5 && params[1] == 2 {
+		return fmt.Sprintf("%d;2;%d;%d;%d", params[0], params[2], params[3], params[4]), 4
+	}
+	return "", len(params) - 1
+}
+
+// String returns the text on the screen without any styling, trailing spaces
+// are removed from every line.
+func (t *Terminal) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, t.rows)
+	for y, line := range t.screen {
+		var b strings.Builder
+		for _, c := range line {
+			b.WriteRune(c.Rune)
+		}
+		lines[y] = strings.TrimRight(b.String(), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Render returns the screen with styling as lines of text, each exactly as
+// wide as the terminal. The cursor is drawn in reverse video when showCursor
+// is set and the program has not hidden it.
+func (t *Terminal) Render(showCursor bool) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := make([]string, t.rows)
+	for y, line := range t.screen {
+		var (
+			b    strings.Builder
+			last *Style
+		)
+		for x, c := range line {
+			style := c.Style
+			if showCursor && !t.hidden && x == t.x && y == t.y {
+				style.Reverse = !style.Reverse
+			}
+			if last == nil || *last != style {
+				b.WriteString(style.sgr())
+				last = &style
+			}
+			b.WriteRune(c.Rune)
+		}
+		b.WriteString("\x1b[0m")
+		lines[y] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
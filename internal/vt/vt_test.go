@@ -0,0 +1,154 @@
+package vt_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/maaslalani/slides/internal/vt"
+)
+
+func TestWrite(t *testing.T) {
+	tt := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "text and newlines",
+			input:    "hello\r\nworld",
+			expected: "hello\nworld\n",
+		},
+		{
+			desc:     "wraps long lines",
+			input:    "abcdefghij",
+			expected: "abcdefgh\nij\n",
+		},
+		{
+			desc:     "scrolls",
+			input:    "1\r\n2\r\n3\r\n4",
+			expected: "2\n3\n4",
+		},
+		{
+			desc:     "carriage return overwrites",
+			input:    "hello\rJ",
+			expected: "Jello\n\n",
+		},
+		{
+			desc:     "cursor movement",
+			input:    "\x1b[2;3Hx\x1b[1;1Hy",
+			expected: "y\n  x\n",
+		},
+		{
+			desc:     "erase line",
+			input:    "hello\x1b[3G\x1b[K",
+			expected: "he\n\n",
+		},
+		{
+			desc:     "erase display",
+			input:    "a\r\nb\x1b[2J",
+			expected: "\n\n",
+		},
+		{
+			desc:     "styles are not printed",
+			input:    "\x1b[1;31mred\x1b[0m \x1b[38;5;200mpink\x1b[m",
+			expected: "red pink\n\n",
+		},
+		{
+			desc:     "titles are not printed",
+			input:    "\x1b]0;title\aok",
+			expected: "ok\n\n",
+		},
+		{
+			desc:     "alternate screen",
+			input:    "main\x1b[?1049h\x1b[Halt\x1b[?1049l",
+			expected: "main\n\n",
+		},
+		{
+			desc:     "utf-8",
+			input:    "héllo ✓",
+			expected: "héllo ✓\n\n",
+		},
+		{
+			desc:     "negative column",
+			input:    "ab\x1b[-5Gx",
+			expected: "xb\n\n",
+		},
+		{
+			desc:     "huge forward count",
+			input:    "\x1b[9223372036854775807Cx",
+			expected: "       x\n\n",
+		},
+		{
+			desc:     "overflowing position",
+			input:    "\x1b[99999999999999999999;99999999999999999999Hx",
+			expected: "\n\n       x",
+		},
+		{
+			desc:     "huge scroll counts",
+			input:    "a\r\nb\x1b[9223372036854775807L\x1b[99999999999S\x1b[99999999999Tc",
+			expected: "\n c\n",
+		},
+		{
+			desc:     "huge insert and delete counts",
+			input:    "abc\x1b[1G\x1b[99999999999P\x1b[99999999999@\x1b[99999999999Md",
+			expected: "d\n\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			term := vt.New(8, 3)
+			_, _ = term.Write([]byte(tc.input))
+			if got := term.String(); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteSplitSequences(t *testing.T) {
+	term := vt.New(8, 1)
+	input := "\x1b[31m✓\x1b[0m"
+	for i := 0; i < len(input); i++ {
+		_, _ = term.Write([]byte{input[i]})
+	}
+	if got := term.String(); got != "✓" {
+		t.Errorf("expected %q, got %q", "✓", got)
+	}
+}
+
+func TestReply(t *testing.T) {
+	var reply bytes.Buffer
+	term := vt.New(8, 3)
+	term.Reply = &reply
+	_, _ = term.Write([]byte("ab\x1b[6n"))
+	if got := reply.String(); got != "\x1b[1;3R" {
+		t.Errorf("expected cursor position report, got %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	term := vt.New(4, 1)
+	_, _ = term.Write([]byte("\x1b[31mab"))
+	got := term.Render(false)
+	if !strings.Contains(got, "\x1b[0;31mab") {
+		t.Errorf("expected styled output, got %q", got)
+	}
+}
+
+func TestRestoreAfterResize(t *testing.T) {
+	for _, seq := range []struct{ save, restore string }{
+		{"\x1b7", "\x1b8"},
+		{"\x1b[s", "\x1b[u"},
+		{"\x1b[?1049h", "\x1b[?1049l"},
+	} {
+		term := vt.New(20, 20)
+		_, _ = term.Write([]byte("\x1b[18;15H" + seq.save))
+		term.Resize(10, 5)
+		_, _ = term.Write([]byte(seq.restore + "x"))
+		if got := term.String(); !strings.Contains(got, "x") {
+			t.Errorf("%q: expected x on the screen, got %q", seq.restore, got)
+		}
+	}
+}
@@ -7,12 +7,18 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/maaslalani/slides/internal/cmd"
+	"github.com/maaslalani/slides/internal/keymap"
 	"github.com/maaslalani/slides/internal/model"
 	"github.com/maaslalani/slides/internal/navigation"
 	"github.com/maaslalani/slides/internal/term"
 	"github.com/muesli/coral"
 )
 
+var (
+	noTransitions bool
+	mouse         bool
+)
+
 var rootCmd = &coral.Command{
 	Use:   "slides <file.md>",
 	Short: "Terminal based presentation tool",
@@ -32,25 +38,43 @@ var rootCmd = &coral.Command{
 			protocol = term.Iterm
 		}
 
+		keys, err := keymap.Load(keymap.Path())
+		if err != nil {
+			return err
+		}
+
+		out := term.Synchronized(os.Stdout)
 		presentation := model.Model{
 			Page:             0,
 			Date:             time.Now().Format("2006-01-02"),
 			FileName:         fileName,
 			Search:           navigation.NewSearch(),
+			Picker:           navigation.NewPicker(),
+			Finder:           navigation.NewFinder(),
+			KeyMap:           &keys,
 			TerminalProtocol: protocol,
+			NoTransitions:    noTransitions,
+			Mouse:            mouse,
+			Output:           out,
 		}
 		err = presentation.Load()
 		if err != nil {
 			return err
 		}
 
-		p := tea.NewProgram(presentation, tea.WithAltScreen())
+		opts := []tea.ProgramOption{tea.WithOutput(out), tea.WithAltScreen()}
+		if mouse {
+			opts = append(opts, tea.WithMouseCellMotion())
+		}
+		p := tea.NewProgram(presentation, opts...)
 		_, err = p.Run()
 		return err
 	},
 }
 
 func init() {
+	rootCmd.Flags().BoolVar(&noTransitions, "no-transitions", false, "Change slides without transitions")
+	rootCmd.Flags().BoolVar(&mouse, "mouse", false, "Click to change slides, scroll long slides and open links")
 	rootCmd.AddCommand(
 		cmd.ServeCmd,
 	)
@@ -3,12 +3,14 @@ package styles
 
 import (
 	_ "embed"
+	"encoding/json"
 	"io"
 	"net/http"
 	"os"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/glamour/styles"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
@@ -36,6 +38,65 @@ var (
 	// Search is the style for the search input at the bottom-left corner of
 	// the screen when searching is active.
 	Search = lipgloss.NewStyle().Faint(true).Align(lipgloss.Left).MarginLeft(2)
+	// Message is the style for transient messages, such as clipboard
+	// confirmations, in the bottom-left corner of the presentation.
+	Message = lipgloss.NewStyle().Foreground(salmon).Align(lipgloss.Left).MarginLeft(2)
+	// Dim is the style for the lines of a code block which are not
+	// highlighted.
+	Dim = lipgloss.NewStyle().Faint(true)
+	// DiffInsert is the style for the gutter of lines inserted in a diff.
+	DiffInsert = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	// DiffDelete is the style for the gutter of lines deleted in a diff.
+	DiffDelete = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+	// Pane is the style for the border around interactive terminal panes.
+	Pane = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	// PaneFocused is the style for the border around the terminal pane which
+	// receives the key presses.
+	PaneFocused = Pane.BorderForeground(salmon)
+	// Focus is the style for the gutter drawn next to the focused code
+	// block.
+	Focus = lipgloss.NewStyle().Foreground(salmon)
+	// OverviewCard is the style for the cards of the slides in the overview.
+	OverviewCard = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+	// OverviewSelected is the style for the card of the selected slide in
+	// the overview.
+	OverviewSelected = OverviewCard.BorderForeground(salmon)
+	// OverviewTitle is the style for the titles of the slides in the
+	// overview.
+	OverviewTitle = lipgloss.NewStyle().Bold(true)
+	// OverviewSelectedTitle is the style for the title of the selected slide
+	// in the overview.
+	OverviewSelectedTitle = OverviewTitle.Foreground(salmon)
+	// PickerItem is the style for the titles of the slides in the picker.
+	PickerItem = lipgloss.NewStyle()
+	// PickerSelected is the style for the title of the selected slide in the
+	// picker.
+	PickerSelected = lipgloss.NewStyle().Foreground(salmon)
+	// PickerMatch is the style for the characters of titles which match the
+	// query of the picker.
+	PickerMatch = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(salmon)
+	// HelpTitle is the style for the title of the help overlay.
+	HelpTitle = lipgloss.NewStyle().Bold(true).Foreground(salmon)
+	// HelpKey is the style for the keys in the help overlay.
+	HelpKey = lipgloss.NewStyle().Foreground(salmon)
+	// HelpDesc is the style for the descriptions of the keys in the help
+	// overlay.
+	HelpDesc = lipgloss.NewStyle().Faint(true)
+	// TableBorder is the style for the borders of tables.
+	TableBorder = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	// TableHeader is the style for the header cells of tables.
+	TableHeader = lipgloss.NewStyle().Bold(true).Foreground(salmon).Padding(0, 1)
+	// TableCell is the style for the cells of tables.
+	TableCell = lipgloss.NewStyle().Padding(0, 1)
+	// Series are the styles of the data series in charts, in order.
+	Series = []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(salmon),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#4169E1")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAA00")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87")),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#B294BB")),
+	}
 )
 
 // DefaultTheme is the default theme for the presentation.
@@ -43,6 +104,16 @@ var (
 //go:embed theme.json
 var DefaultTheme []byte
 
+// slideMargin is the number of columns around the text of a slide, the
+// padding of the slide and the margin of the document on both sides.
+const slideMargin = 8
+
+// SlideWidth returns the width of the text of a slide in a window of the
+// given width.
+func SlideWidth(width int) int {
+	return width - slideMargin
+}
+
 // JoinHorizontal joins two strings horizontally and fills the space in-between.
 func JoinHorizontal(left, right string, width int) string {
 	w := width - lipgloss.Width(right)
@@ -58,15 +129,21 @@ func JoinVertical(top, bottom string, height int) string {
 // SelectTheme picks a glamour style config based
 // on the theme provided in the markdown header
 func SelectTheme(theme string) glamour.TermRendererOption {
+	return glamour.WithStyles(ThemeConfig(theme))
+}
+
+// ThemeConfig returns the glamour style config of a theme: one of glamour's
+// themes, a JSON file or URL, or else the default theme.
+func ThemeConfig(theme string) ansi.StyleConfig {
 	switch theme {
 	case "ascii":
-		return glamour.WithStyles(styles.ASCIIStyleConfig)
+		return styles.ASCIIStyleConfig
 	case "light":
-		return glamour.WithStyles(styles.LightStyleConfig)
+		return styles.LightStyleConfig
 	case "dark":
-		return glamour.WithStyles(styles.DarkStyleConfig)
+		return styles.DarkStyleConfig
 	case "notty":
-		return glamour.WithStyles(styles.NoTTYStyleConfig)
+		return styles.NoTTYStyleConfig
 	default:
 		var themeReader io.Reader
 		var err error
@@ -88,21 +165,58 @@ func SelectTheme(theme string) glamour.TermRendererOption {
 		}
 		bytes, err := io.ReadAll(themeReader)
 		if err == nil {
-			return glamour.WithStylesFromJSONBytes(bytes)
+			var config ansi.StyleConfig
+			if err = json.Unmarshal(bytes, &config); err == nil {
+				return config
+			}
 		}
 		// Should log a warning so the user knows we failed to read their theme file
 		return getDefaultTheme()
 	}
 }
 
-func getDefaultTheme() glamour.TermRendererOption {
+func getDefaultTheme() ansi.StyleConfig {
 	if termenv.EnvNoColor() {
-		return glamour.WithStyles(styles.NoTTYStyleConfig)
+		return styles.NoTTYStyleConfig
 	}
 
 	if !termenv.HasDarkBackground() {
-		return glamour.WithStyles(styles.LightStyleConfig)
+		return styles.LightStyleConfig
 	}
 
-	return glamour.WithStylesFromJSONBytes(DefaultTheme)
+	var config ansi.StyleConfig
+	_ = json.Unmarshal(DefaultTheme, &config)
+	return config
+}
+
+// ThemeColors returns the colors of a theme which stand out from its text,
+// those of its headings, links and code, for drawing elements of slides in
+// the colors of the theme.
+func ThemeColors(config ansi.StyleConfig) []string {
+	primitives := []ansi.StylePrimitive{
+		config.H1.StylePrimitive,
+		config.H2.StylePrimitive,
+		config.H3.StylePrimitive,
+		config.H4.StylePrimitive,
+		config.H5.StylePrimitive,
+		config.H6.StylePrimitive,
+		config.Heading.StylePrimitive,
+		config.LinkText,
+		config.Link,
+		config.Code.StylePrimitive,
+	}
+	if chroma := config.CodeBlock.Chroma; chroma != nil {
+		primitives = append(primitives, chroma.Keyword, chroma.NameFunction, chroma.LiteralString, chroma.LiteralNumber)
+	}
+
+	var colors []string
+	seen := map[string]bool{}
+	for _, p := range primitives {
+		if p.Color == nil || *p.Color == "" || seen[*p.Color] {
+			continue
+		}
+		seen[*p.Color] = true
+		colors = append(colors, *p.Color)
+	}
+	return colors
 }
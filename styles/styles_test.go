@@ -73,3 +73,33 @@ func TestSelectTheme_file(t *testing.T) {
 		})
 	}
 }
+
+func TestThemeColors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ansi.StyleConfig
+		colors int
+	}{
+		{name: "Dark theme", config: glamStyles.DarkStyleConfig, colors: 2},
+		{name: "Light theme", config: glamStyles.LightStyleConfig, colors: 2},
+		{name: "Default theme", config: styles.ThemeConfig("./theme.json"), colors: 2},
+		{name: "Notty theme", config: glamStyles.NoTTYStyleConfig, colors: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colors := styles.ThemeColors(tt.config)
+			if tt.colors == 0 {
+				assert.Empty(t, colors)
+				return
+			}
+			assert.GreaterOrEqual(t, len(colors), tt.colors)
+
+			// Colors are not repeated
+			seen := map[string]bool{}
+			for _, c := range colors {
+				assert.False(t, seen[c], c)
+				seen[c] = true
+			}
+		})
+	}
+}